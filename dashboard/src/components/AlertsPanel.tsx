@@ -1,5 +1,5 @@
 import { useEffect, useState } from 'react'
-import { TrendingDown, TrendingUp, Zap, DollarSign, CheckCircle, Bell, AlertCircle } from 'lucide-react'
+import { TrendingDown, TrendingUp, Zap, DollarSign, CheckCircle, Bell, AlertCircle, Clock } from 'lucide-react'
 import { apiFetch } from '../config'
 import './AlertsPanel.css'
 
@@ -72,6 +72,7 @@ function AlertsPanel({ selectedMarket, onSelectMarket }: AlertsPanelProps) {
       case 'imbalance_pressure': return <Zap {...iconProps} />
       case 'no_arb_violation': return <DollarSign {...iconProps} />
       case 'execution_ready': return <CheckCircle {...iconProps} />
+      case 'expiring_soon': return <Clock {...iconProps} />
       default: return <Bell {...iconProps} />
     }
   }
@@ -0,0 +1,98 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// cooldownSaveInterval is how often cooldowns set since the last save are
+// written out while running
+const cooldownSaveInterval = 10 * time.Second
+
+// loadCooldowns restores cooldowns saved by a previous run, dropping any that
+// have already expired. A missing file is not an error.
+func (m *Manager) loadCooldowns() error {
+	if m.config.CooldownStatePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.config.CooldownStatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cooldown state: %w", err)
+	}
+
+	var saved map[string]time.Time
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse cooldown state: %w", err)
+	}
+
+	cooldownDuration := time.Duration(m.config.AlertCooldownSecs) * time.Second
+
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, lastAlert := range saved {
+		if now.Sub(lastAlert) < cooldownDuration {
+			m.cooldown[key] = lastAlert
+		}
+	}
+	return nil
+}
+
+// saveChangedCooldowns saves cooldowns if any were set since the last save
+func (m *Manager) saveChangedCooldowns() error {
+	m.mu.RLock()
+	changed := m.cooldownsChanged
+	m.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	return m.saveCooldowns()
+}
+
+// saveCooldowns writes still-active cooldowns to disk
+func (m *Manager) saveCooldowns() error {
+	if m.config.CooldownStatePath == "" {
+		return nil
+	}
+
+	cooldownDuration := time.Duration(m.config.AlertCooldownSecs) * time.Second
+
+	now := m.now()
+	m.mu.Lock()
+	active := make(map[string]time.Time, len(m.cooldown))
+	for key, lastAlert := range m.cooldown {
+		if now.Sub(lastAlert) < cooldownDuration {
+			active[key] = lastAlert
+		}
+	}
+	m.cooldownsChanged = false
+	m.mu.Unlock()
+
+	err := writeCooldowns(m.config.CooldownStatePath, active)
+	if err != nil {
+		// Try again at the next save
+		m.mu.Lock()
+		m.cooldownsChanged = true
+		m.mu.Unlock()
+	}
+	return err
+}
+
+func writeCooldowns(path string, cooldowns map[string]time.Time) error {
+	data, err := json.Marshal(cooldowns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cooldown state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cooldown state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,105 @@
+package alerting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// recordingSender collects sent messages
+type recordingSender struct {
+	messages []string
+}
+
+func (r *recordingSender) Send(message string) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+// newTestManager returns a manager with one sink that accepts everything and
+// room to queue deliveries without dispatch workers
+func newTestManager(cfg config.AlertingConfig) *Manager {
+	cfg.SendQueueSize = 10
+	m := NewManager(cfg, nil)
+	m.sinks = []sink{{name: "test", client: &recordingSender{}, minSeverity: signals.SeverityInfo}}
+	return m
+}
+
+func TestCooldownSurvivesRestart(t *testing.T) {
+	cfg := config.AlertingConfig{
+		AlertCooldownSecs: 300,
+		CooldownStatePath: filepath.Join(t.TempDir(), "cooldowns.json"),
+	}
+	signal := signals.Signal{MarketTicker: "MKT", Type: signals.SignalTypeVolumeSurge}
+
+	first := newTestManager(cfg)
+	first.handleSignal(signal)
+	if len(first.deliveries) != 1 {
+		t.Fatalf("first alert queued %d deliveries, want 1", len(first.deliveries))
+	}
+
+	// Cooldowns are saved as they change, not only at shutdown
+	if err := first.saveChangedCooldowns(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cfg.CooldownStatePath); err != nil {
+		t.Fatalf("cooldowns not saved: %v", err)
+	}
+
+	restarted := newTestManager(cfg)
+	if err := restarted.loadCooldowns(); err != nil {
+		t.Fatal(err)
+	}
+	restarted.handleSignal(signal)
+	if len(restarted.deliveries) != 0 {
+		t.Fatal("alert fired again after restart inside its cooldown")
+	}
+
+	// Other markets aren't affected
+	restarted.handleSignal(signals.Signal{MarketTicker: "OTHER", Type: signals.SignalTypeVolumeSurge})
+	if len(restarted.deliveries) != 1 {
+		t.Fatalf("other market queued %d deliveries, want 1", len(restarted.deliveries))
+	}
+}
+
+func TestSaveChangedCooldownsSkipsWhenUnchanged(t *testing.T) {
+	cfg := config.AlertingConfig{
+		AlertCooldownSecs: 300,
+		CooldownStatePath: filepath.Join(t.TempDir(), "cooldowns.json"),
+	}
+	m := newTestManager(cfg)
+	if err := m.saveChangedCooldowns(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cfg.CooldownStatePath); !os.IsNotExist(err) {
+		t.Fatal("saved with no cooldown changes")
+	}
+}
+
+func TestCooldownFollowsManagerClock(t *testing.T) {
+	m := newTestManager(config.AlertingConfig{AlertCooldownSecs: 300})
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return at }
+	signal := signals.Signal{MarketTicker: "MKT", Type: signals.SignalTypeVolumeSurge}
+
+	m.handleSignal(signal)
+	if recorded := m.cooldown["MKT"+string(signals.SignalTypeVolumeSurge)]; !recorded.Equal(at) {
+		t.Fatalf("cooldown recorded at %s, want the manager clock's %s", recorded, at)
+	}
+
+	// Inside the cooldown by the manager's clock, then just past it
+	at = at.Add(299 * time.Second)
+	m.handleSignal(signal)
+	if len(m.deliveries) != 1 {
+		t.Fatalf("%d deliveries inside the cooldown, want 1", len(m.deliveries))
+	}
+	at = at.Add(2 * time.Second)
+	m.handleSignal(signal)
+	if len(m.deliveries) != 2 {
+		t.Errorf("%d deliveries after the cooldown, want 2", len(m.deliveries))
+	}
+}
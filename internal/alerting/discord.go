@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type DiscordClient struct {
@@ -12,10 +13,10 @@ type DiscordClient struct {
 	client     *http.Client
 }
 
-func NewDiscordClient(webhookURL string) *DiscordClient {
+func NewDiscordClient(webhookURL string, timeout time.Duration) *DiscordClient {
 	return &DiscordClient{
 		webhookURL: webhookURL,
-		client:     &http.Client{},
+		client:     newHTTPClient(timeout),
 	}
 }
 
@@ -40,7 +41,7 @@ func (c *DiscordClient) Send(message string) error {
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
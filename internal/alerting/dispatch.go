@@ -0,0 +1,47 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+)
+
+// delivery is one message bound for one sink
+type delivery struct {
+	target  sink
+	message string
+}
+
+// startDispatchers runs SendConcurrency workers that drain the delivery queue
+// until ctx is cancelled, so a signal burst can't open unbounded connections
+func (m *Manager) startDispatchers(ctx context.Context) {
+	workers := m.config.SendConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go m.dispatch(ctx)
+	}
+}
+
+func (m *Manager) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-m.deliveries:
+			if err := d.target.client.Send(d.message); err != nil {
+				fmt.Printf("Failed to send alert to %s: %v\n", d.target.name, err)
+			}
+		}
+	}
+}
+
+// enqueue hands a delivery to the workers without blocking the signal loop.
+// When every worker is busy and the queue is full the delivery is dropped.
+func (m *Manager) enqueue(d delivery) {
+	select {
+	case m.deliveries <- d:
+	default:
+		fmt.Printf("Alert queue full; dropped alert for %s\n", d.target.name)
+	}
+}
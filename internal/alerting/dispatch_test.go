@@ -0,0 +1,102 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// blockingSender holds every send until release is closed, tracking how many
+// are in flight at once
+type blockingSender struct {
+	release chan struct{}
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	sent        int
+}
+
+func (b *blockingSender) Send(message string) error {
+	b.mu.Lock()
+	b.inFlight++
+	b.maxInFlight = max(b.maxInFlight, b.inFlight)
+	b.mu.Unlock()
+
+	<-b.release
+
+	b.mu.Lock()
+	b.inFlight--
+	b.sent++
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *blockingSender) counts() (inFlight, maxInFlight, sent int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight, b.maxInFlight, b.sent
+}
+
+// waitFor polls cond for up to two seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSignalBurstKeepsSendsBounded(t *testing.T) {
+	const workers, queue = 3, 5
+
+	m := NewManager(config.AlertingConfig{SendConcurrency: workers, SendQueueSize: queue}, nil)
+	sender := &blockingSender{release: make(chan struct{})}
+	m.sinks = []sink{{name: "test", client: sender, minSeverity: signals.SeverityInfo}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startDispatchers(ctx)
+
+	burst := func(from, to int) {
+		for i := from; i < to; i++ {
+			m.handleSignal(signals.Signal{MarketTicker: fmt.Sprintf("MKT-%d", i), Type: signals.SignalTypeVolumeSurge})
+		}
+	}
+
+	// Occupy every worker, then burst far past what the queue holds
+	burst(0, workers)
+	waitFor(t, "workers to pick up sends", func() bool {
+		inFlight, _, _ := sender.counts()
+		return inFlight == workers
+	})
+	goroutines := runtime.NumGoroutine()
+	burst(workers, 200)
+
+	if grown := runtime.NumGoroutine() - goroutines; grown > 0 {
+		t.Errorf("burst started %d goroutines, want none beyond the workers", grown)
+	}
+	if len(m.deliveries) != queue {
+		t.Errorf("queued %d deliveries, want the queue size %d", len(m.deliveries), queue)
+	}
+
+	// The in-flight and queued sends complete; the overflow was dropped
+	close(sender.release)
+	waitFor(t, "queued sends to finish", func() bool {
+		_, _, sent := sender.counts()
+		return sent == workers+queue
+	})
+	time.Sleep(20 * time.Millisecond)
+	if _, maxInFlight, sent := sender.counts(); maxInFlight > workers || sent != workers+queue {
+		t.Errorf("max in flight %d, sent %d; want at most %d and exactly %d", maxInFlight, sent, workers, workers+queue)
+	}
+}
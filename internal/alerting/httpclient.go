@@ -0,0 +1,25 @@
+package alerting
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxResponseBytes bounds how much of a webhook response is read. Only the
+// status (and for Telegram a short JSON error) matters, so a misbehaving
+// endpoint streaming a huge body can't tie up a send worker.
+const maxResponseBytes = 64 << 10
+
+// newHTTPClient returns the client webhook senders use. The timeout covers the
+// whole exchange, including reading the response; 0 disables it.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// closeBody drains up to maxResponseBytes of a response so the connection can
+// be reused, then closes it
+func closeBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	resp.Body.Close()
+}
@@ -0,0 +1,72 @@
+package alerting
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendTimesOutOnHungEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // never answers
+	}))
+	defer server.Close()
+	defer close(release)
+
+	const timeout = 100 * time.Millisecond
+	telegram := NewTelegramClient("token", "chat", timeout)
+	telegram.apiURL = server.URL
+
+	senders := map[string]interface{ Send(string) error }{
+		"slack":    NewSlackClient(server.URL, timeout),
+		"discord":  NewDiscordClient(server.URL, timeout),
+		"webhook":  NewWebhookClient(server.URL, timeout),
+		"telegram": telegram,
+	}
+	for name, sender := range senders {
+		done := make(chan error, 1)
+		go func() { done <- sender.Send("hello") }()
+
+		select {
+		case err := <-done:
+			var netErr net.Error
+			if err == nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+				t.Errorf("%s: Send returned %v, want a timeout error", name, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: Send still blocked on an endpoint that never responds", name)
+		}
+	}
+}
+
+func TestSendReadsBoundedResponseBody(t *testing.T) {
+	// 1 MiB of response, then the endpoint stalls without finishing the body
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := strings.Repeat("x", 64<<10)
+		for i := 0; i < 16; i++ {
+			if _, err := w.Write([]byte(chunk)); err != nil {
+				return
+			}
+		}
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewWebhookClient(server.URL, 5*time.Second)
+	start := time.Now()
+	if err := client.Send("hello"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	// Reading stops at maxResponseBytes instead of waiting out the timeout
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Send took %v reading an endless body", elapsed)
+	}
+}
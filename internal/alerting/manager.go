@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/kalshi-signal-feed/internal/config"
@@ -13,82 +14,130 @@ import (
 type Manager struct {
 	config      config.AlertingConfig
 	signalChan  <-chan signals.Signal
-	slackClient *SlackClient
-	discordClient *DiscordClient
+	sinks       []sink
+	templates   map[signals.SignalType]*template.Template
 	cooldown    map[string]time.Time
+	cooldownsChanged bool // cooldowns set since the last save, guarded by mu
 	mu          sync.RWMutex
-}
 
-func NewManager(cfg config.AlertingConfig, signalChan <-chan signals.Signal) *Manager {
-	var slackClient *SlackClient
-	var discordClient *DiscordClient
+	// Resolves a market ticker to its dashboard category, for category-filtered sinks
+	categoryOf func(ticker string) string
 
-	if cfg.SlackWebhookURL != "" {
-		slackClient = NewSlackClient(cfg.SlackWebhookURL)
-	}
+	// Sends waiting for one of the dispatch workers
+	deliveries chan delivery
 
-	if cfg.DiscordWebhookURL != "" {
-		discordClient = NewDiscordClient(cfg.DiscordWebhookURL)
-	}
+	quietHours *quietHours      // nil when not configured
+	now        func() time.Time // time.Now, replaceable for a fixed clock
+}
 
+func NewManager(cfg config.AlertingConfig, signalChan <-chan signals.Signal) *Manager {
 	return &Manager{
 		config:       cfg,
 		signalChan:   signalChan,
-		slackClient:  slackClient,
-		discordClient: discordClient,
+		sinks:        buildSinks(cfg),
+		templates:    parseTemplates(cfg.Templates),
 		cooldown:     make(map[string]time.Time),
+		deliveries:   make(chan delivery, max(cfg.SendQueueSize, 0)),
+		quietHours:   parseQuietHours(cfg),
+		now:          time.Now,
 	}
 }
 
+// SetCategoryFunc supplies the market categorisation used by sinks that filter
+// on category. Without it, category-filtered sinks receive nothing.
+func (m *Manager) SetCategoryFunc(categoryOf func(ticker string) string) {
+	m.categoryOf = categoryOf
+}
+
 func (m *Manager) Run(ctx context.Context) error {
 	if !m.config.Enabled {
 		return nil
 	}
 
+	if err := m.loadCooldowns(); err != nil {
+		fmt.Printf("Failed to restore alert cooldowns: %v\n", err)
+	}
+
+	m.startDispatchers(ctx)
+
+	// Save cooldowns as they change, so a crash doesn't lose them
+	saveTicker := time.NewTicker(cooldownSaveInterval)
+	defer saveTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			if err := m.saveCooldowns(); err != nil {
+				fmt.Printf("Failed to save alert cooldowns: %v\n", err)
+			}
 			return ctx.Err()
+		case <-saveTicker.C:
+			if err := m.saveChangedCooldowns(); err != nil {
+				fmt.Printf("Failed to save alert cooldowns: %v\n", err)
+			}
 		case signal := <-m.signalChan:
-			if signal.Metadata.ThresholdCrossed {
+			if m.shouldAlert(signal) {
 				m.handleSignal(signal)
 			}
 		}
 	}
 }
 
+// shouldAlert gates which signals reach the webhooks
+func (m *Manager) shouldAlert(signal signals.Signal) bool {
+	if !signal.Metadata.ThresholdCrossed {
+		return false
+	}
+	if m.quietHours.suppresses(signal.Metadata.Severity, m.now()) {
+		return false
+	}
+	return signal.Metadata.Confidence >= m.config.MinConfidence
+}
+
 func (m *Manager) handleSignal(signal signals.Signal) {
 	// Check cooldown
 	key := signal.MarketTicker + string(signal.Type)
+	now := m.now()
 	m.mu.RLock()
 	lastAlert, inCooldown := m.cooldown[key]
 	m.mu.RUnlock()
 
 	if inCooldown {
 		cooldownDuration := time.Duration(m.config.AlertCooldownSecs) * time.Second
-		if time.Since(lastAlert) < cooldownDuration {
+		if now.Sub(lastAlert) < cooldownDuration {
 			return
 		}
 	}
 
 	// Update cooldown
 	m.mu.Lock()
-	m.cooldown[key] = time.Now()
+	m.cooldown[key] = now
+	m.cooldownsChanged = true
 	m.mu.Unlock()
 
 	// Send alerts
 	message := m.formatSignalMessage(signal)
 
-	if m.slackClient != nil {
-		go m.slackClient.Send(message)
+	severity := signal.Metadata.Severity
+
+	var category string
+	if m.categoryOf != nil {
+		category = m.categoryOf(signal.MarketTicker)
 	}
 
-	if m.discordClient != nil {
-		go m.discordClient.Send(message)
+	for _, target := range m.sinks {
+		if !target.accepts(severity, category) {
+			continue
+		}
+		m.enqueue(delivery{target: target, message: message})
 	}
 }
 
 func (m *Manager) formatSignalMessage(signal signals.Signal) string {
+	if msg, ok := m.renderTemplate(signal); ok {
+		return msg
+	}
+
 	var msg string
 
 	switch signal.Type {
@@ -125,18 +174,50 @@ func (m *Manager) formatSignalMessage(signal signals.Signal) string {
 			msg = fmt.Sprintf("📈 **Volume Surge**\n"+
 				"Market: %s\n"+
 				"Multiplier: %.2fx\n"+
+				"Flow: %s (%d bought / %d sold)\n"+
 				"Confidence: %.0f%%",
 				signal.MarketTicker,
 				signal.VolumeSurge.VolumeMultiplier,
+				signal.VolumeSurge.Direction,
+				signal.VolumeSurge.BuyVolume,
+				signal.VolumeSurge.SellVolume,
 				signal.Metadata.Confidence*100,
 			)
 		}
+
+	case signals.SignalTypeLiquidityWithdrawal:
+		if signal.LiquidityWithdrawal != nil {
+			msg = fmt.Sprintf("🕳️ **Liquidity Withdrawal**\n"+
+				"Market: %s\n"+
+				"Book: %s → %s",
+				signal.MarketTicker,
+				signal.LiquidityWithdrawal.PreviousState,
+				signal.LiquidityWithdrawal.CurrentState,
+			)
+		}
+
+	case signals.SignalTypeQuoteFlicker:
+		if signal.QuoteFlicker != nil {
+			msg = fmt.Sprintf("⚠️ **Quote Flicker**\n"+
+				"Market: %s\n"+
+				"Top of book changed %d times in %ds (%.1f/s)\n"+
+				"Quoting is unstable; treat displayed prices with caution",
+				signal.MarketTicker,
+				signal.QuoteFlicker.Changes,
+				signal.QuoteFlicker.WindowSecs,
+				signal.Value,
+			)
+		}
 	}
 
 	if msg == "" {
 		msg = fmt.Sprintf("Signal: %s on %s (Value: %.2f)", signal.Type, signal.MarketTicker, signal.Value)
 	}
 
+	if link := config.MarketURL(m.config.DashboardBaseURL, signal.MarketTicker); link != "" {
+		msg += "\n" + link
+	}
+
 	return msg
 }
 
@@ -0,0 +1,113 @@
+package alerting
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+func TestShouldAlertMinConfidence(t *testing.T) {
+	m := NewManager(config.AlertingConfig{MinConfidence: 0.6}, nil)
+
+	tests := []struct {
+		name       string
+		confidence float64
+		crossed    bool
+		want       bool
+	}{
+		{"below minimum", 0.5, true, false},
+		{"at minimum", 0.6, true, true},
+		{"above minimum", 0.9, true, true},
+		{"threshold not crossed", 0.9, false, false},
+	}
+	for _, tt := range tests {
+		signal := signals.Signal{
+			MarketTicker: "MKT",
+			Type:         signals.SignalTypeOrderbookImbalance,
+			Metadata: signals.SignalMetadata{
+				Confidence:       tt.confidence,
+				ThresholdCrossed: tt.crossed,
+				Severity:         signals.SeverityHigh,
+			},
+		}
+		if got := m.shouldAlert(signal); got != tt.want {
+			t.Errorf("%s: shouldAlert = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityRoutingPerSink(t *testing.T) {
+	cfg := config.AlertingConfig{
+		SlackWebhookURL:  "http://slack.invalid/hook",
+		SlackMinSeverity: "info",
+		Sinks: []config.SinkConfig{
+			{Name: "pager", Type: "webhook", URL: "http://pager.invalid/hook", MinSeverity: "high"},
+		},
+		SendQueueSize: 10,
+	}
+
+	tests := []struct {
+		severity signals.Severity
+		want     []string
+	}{
+		{signals.SeverityInfo, []string{"slack"}},
+		{signals.SeverityMedium, []string{"slack"}},
+		{signals.SeverityHigh, []string{"slack", "pager"}},
+	}
+	for _, tt := range tests {
+		m := NewManager(cfg, nil)
+		m.handleSignal(signals.Signal{
+			MarketTicker: "MKT",
+			Type:         signals.SignalTypeVolumeSurge,
+			Metadata:     signals.SignalMetadata{Severity: tt.severity},
+		})
+
+		var got []string
+		for len(m.deliveries) > 0 {
+			got = append(got, (<-m.deliveries).target.name)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s alert went to %v, want %v", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryRoutingAcrossSlackSinks(t *testing.T) {
+	cfg := config.AlertingConfig{
+		Sinks: []config.SinkConfig{
+			{Name: "politics", Type: "slack", URL: "http://slack.invalid/politics", Categories: []string{"Politics"}},
+			{Name: "sports", Type: "slack", URL: "http://slack.invalid/sports", Categories: []string{"sports"}},
+		},
+		SendQueueSize: 10,
+	}
+	categories := map[string]string{"POL": "Politics", "NBA": "Sports", "FED": "Economics"}
+
+	tests := []struct {
+		ticker string
+		want   []string
+	}{
+		{"POL", []string{"politics"}},
+		{"NBA", []string{"sports"}},
+		{"FED", nil},
+		{"UNKNOWN", nil},
+	}
+	for _, tt := range tests {
+		m := NewManager(cfg, nil)
+		m.SetCategoryFunc(func(ticker string) string { return categories[ticker] })
+		m.handleSignal(signals.Signal{
+			MarketTicker: tt.ticker,
+			Type:         signals.SignalTypeVolumeSurge,
+			Metadata:     signals.SignalMetadata{Severity: signals.SeverityHigh},
+		})
+
+		var got []string
+		for len(m.deliveries) > 0 {
+			got = append(got, (<-m.deliveries).target.name)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s alert went to %v, want %v", tt.ticker, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// quietHours is a daily window during which only signals at or above
+// minSeverity are sent. The window may wrap past midnight.
+type quietHours struct {
+	start, end  time.Duration // offsets from local midnight
+	location    *time.Location
+	minSeverity signals.Severity
+}
+
+// parseQuietHours builds the schedule from config, returning nil when quiet
+// hours are disabled or misconfigured (which is logged)
+func parseQuietHours(cfg config.AlertingConfig) *quietHours {
+	if cfg.QuietHoursStart == "" && cfg.QuietHoursEnd == "" {
+		return nil
+	}
+
+	start, err := parseClock(cfg.QuietHoursStart)
+	if err != nil {
+		fmt.Printf("Ignoring quiet hours: start: %v\n", err)
+		return nil
+	}
+	end, err := parseClock(cfg.QuietHoursEnd)
+	if err != nil {
+		fmt.Printf("Ignoring quiet hours: end: %v\n", err)
+		return nil
+	}
+
+	location := time.UTC
+	if cfg.QuietHoursTimezone != "" {
+		location, err = time.LoadLocation(cfg.QuietHoursTimezone)
+		if err != nil {
+			fmt.Printf("Ignoring quiet hours: %v\n", err)
+			return nil
+		}
+	}
+
+	// High is the top severity signals are given from confidence, so it's the
+	// default bar for getting through quiet hours
+	minSeverity := signals.Severity(cfg.QuietHoursMinSeverity)
+	if minSeverity == "" {
+		minSeverity = signals.SeverityHigh
+	}
+
+	return &quietHours{start: start, end: end, location: location, minSeverity: minSeverity}
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// active reports whether t falls inside the quiet window
+func (q *quietHours) active(t time.Time) bool {
+	local := t.In(q.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+
+	if q.start <= q.end {
+		return offset >= q.start && offset < q.end
+	}
+	return offset >= q.start || offset < q.end
+}
+
+// suppresses reports whether a signal of this severity is held back at t
+func (q *quietHours) suppresses(severity signals.Severity, t time.Time) bool {
+	return q != nil && q.active(t) && !severity.AtLeast(q.minSeverity)
+}
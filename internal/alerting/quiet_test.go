@@ -0,0 +1,64 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+func TestQuietHoursSuppressWithFixedClock(t *testing.T) {
+	// 22:00-07:00 in New York, crossing midnight, with the default threshold
+	m := NewManager(config.AlertingConfig{
+		QuietHoursStart:    "22:00",
+		QuietHoursEnd:      "07:00",
+		QuietHoursTimezone: "America/New_York",
+	}, nil)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		severity signals.Severity
+		want     bool
+	}{
+		{"daytime medium", time.Date(2026, 3, 2, 12, 0, 0, 0, newYork), signals.SeverityMedium, true},
+		{"late evening medium", time.Date(2026, 3, 2, 23, 30, 0, 0, newYork), signals.SeverityMedium, false},
+		{"after midnight low", time.Date(2026, 3, 3, 3, 0, 0, 0, newYork), signals.SeverityLow, false},
+		{"after midnight high", time.Date(2026, 3, 3, 3, 0, 0, 0, newYork), signals.SeverityHigh, true},
+		{"window end is exclusive", time.Date(2026, 3, 3, 7, 0, 0, 0, newYork), signals.SeverityMedium, true},
+		{"window start is inclusive", time.Date(2026, 3, 2, 22, 0, 0, 0, newYork), signals.SeverityMedium, false},
+		// 04:00 UTC is 23:00 the evening before in New York
+		{"clock in UTC", time.Date(2026, 3, 3, 4, 0, 0, 0, time.UTC), signals.SeverityMedium, false},
+	}
+	for _, tt := range tests {
+		at := tt.at
+		m.now = func() time.Time { return at }
+		signal := signals.Signal{
+			MarketTicker: "MKT",
+			Type:         signals.SignalTypeVolumeSurge,
+			Metadata:     signals.SignalMetadata{Confidence: 0.9, ThresholdCrossed: true, Severity: tt.severity},
+		}
+		if got := m.shouldAlert(signal); got != tt.want {
+			t.Errorf("%s: shouldAlert = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuietHoursDefaultLetsTheHighestEmittedSeverityThrough(t *testing.T) {
+	q := parseQuietHours(config.AlertingConfig{QuietHoursStart: "00:00", QuietHoursEnd: "23:59"})
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	// The top severity confidence maps to must still get through
+	if top := signals.SeverityFromConfidence(1); q.suppresses(top, at) {
+		t.Errorf("default quiet hours suppress %q, the highest severity signals are given", top)
+	}
+	if !q.suppresses(signals.SeverityMedium, at) {
+		t.Error("default quiet hours let medium through")
+	}
+}
@@ -0,0 +1,111 @@
+package alerting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// sender is implemented by every alert destination client
+type sender interface {
+	Send(message string) error
+}
+
+// sink is a configured destination plus the filters deciding what it receives
+type sink struct {
+	name        string
+	client      sender
+	categories  map[string]bool // lowercased; nil accepts every category
+	minSeverity signals.Severity
+}
+
+// buildSinks turns the configured sinks, plus the legacy single-URL fields,
+// into dispatch targets. Sinks missing required settings are logged and skipped.
+func buildSinks(cfg config.AlertingConfig) []sink {
+	defs := make([]config.SinkConfig, 0, len(cfg.Sinks)+3)
+	if cfg.SlackWebhookURL != "" {
+		defs = append(defs, config.SinkConfig{Name: "slack", Type: "slack", URL: cfg.SlackWebhookURL, MinSeverity: cfg.SlackMinSeverity})
+	}
+	if cfg.DiscordWebhookURL != "" {
+		defs = append(defs, config.SinkConfig{Name: "discord", Type: "discord", URL: cfg.DiscordWebhookURL, MinSeverity: cfg.DiscordMinSeverity})
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		defs = append(defs, config.SinkConfig{Name: "telegram", Type: "telegram", BotToken: cfg.TelegramBotToken, ChatID: cfg.TelegramChatID, MinSeverity: cfg.TelegramMinSeverity})
+	}
+	defs = append(defs, cfg.Sinks...)
+
+	timeout := time.Duration(cfg.SendTimeoutSecs) * time.Second
+	sinks := make([]sink, 0, len(defs))
+	for i, def := range defs {
+		name := def.Name
+		if name == "" {
+			name = fmt.Sprintf("%s#%d", def.Type, i)
+		}
+
+		client, err := newSender(def, timeout)
+		if err != nil {
+			fmt.Printf("Skipping alert sink %s: %v\n", name, err)
+			continue
+		}
+
+		var categories map[string]bool
+		for _, category := range def.Categories {
+			if categories == nil {
+				categories = make(map[string]bool)
+			}
+			categories[strings.ToLower(category)] = true
+		}
+
+		minSeverity := signals.Severity(def.MinSeverity)
+		if minSeverity == "" {
+			minSeverity = signals.SeverityInfo
+		}
+
+		sinks = append(sinks, sink{
+			name:        name,
+			client:      client,
+			categories:  categories,
+			minSeverity: minSeverity,
+		})
+	}
+	return sinks
+}
+
+// newSender builds the client for a sink; timeout bounds each request
+func newSender(def config.SinkConfig, timeout time.Duration) (sender, error) {
+	switch def.Type {
+	case "slack", "discord", "webhook":
+		if def.URL == "" {
+			return nil, fmt.Errorf("%s sink needs a url", def.Type)
+		}
+		switch def.Type {
+		case "slack":
+			return NewSlackClient(def.URL, timeout), nil
+		case "discord":
+			return NewDiscordClient(def.URL, timeout), nil
+		}
+		return NewWebhookClient(def.URL, timeout), nil
+	case "telegram":
+		if def.BotToken == "" || def.ChatID == "" {
+			return nil, fmt.Errorf("telegram sink needs bot_token and chat_id")
+		}
+		return NewTelegramClient(def.BotToken, def.ChatID, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", def.Type)
+	}
+}
+
+// accepts reports whether the sink wants a signal of this severity and category.
+// A category-filtered sink rejects signals whose category can't be determined.
+func (s sink) accepts(severity signals.Severity, category string) bool {
+	if !severity.AtLeast(s.minSeverity) {
+		return false
+	}
+	if s.categories == nil {
+		return true
+	}
+	return category != "" && s.categories[strings.ToLower(category)]
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type SlackClient struct {
@@ -12,10 +13,10 @@ type SlackClient struct {
 	client     *http.Client
 }
 
-func NewSlackClient(webhookURL string) *SlackClient {
+func NewSlackClient(webhookURL string, timeout time.Duration) *SlackClient {
 	return &SlackClient{
 		webhookURL: webhookURL,
-		client:     &http.Client{},
+		client:     newHTTPClient(timeout),
 	}
 }
 
@@ -40,7 +41,7 @@ func (c *SlackClient) Send(message string) error {
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
@@ -0,0 +1,104 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const telegramAPIURL = "https://api.telegram.org"
+
+// maxTelegramRetryWait bounds how long a send waits out a rate limit, since it
+// holds one of the dispatch workers while it sleeps
+const maxTelegramRetryWait = 10 * time.Second
+
+type TelegramClient struct {
+	apiURL       string
+	botToken     string
+	chatID       string
+	client       *http.Client
+	maxRetryWait time.Duration
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func NewTelegramClient(botToken, chatID string, timeout time.Duration) *TelegramClient {
+	return &TelegramClient{
+		apiURL:       telegramAPIURL,
+		botToken:     botToken,
+		chatID:       chatID,
+		client:       newHTTPClient(timeout),
+		maxRetryWait: maxTelegramRetryWait,
+	}
+}
+
+// Send posts a message via the Bot API sendMessage method. If Telegram rate
+// limits the bot (429), it waits for retry_after and tries once more, unless
+// the wait is longer than maxRetryWait, in which case the message is dropped.
+func (c *TelegramClient) Send(message string) error {
+	// Our messages use **bold** (Slack/Discord); Telegram Markdown uses *bold*
+	text := strings.ReplaceAll(message, "**", "*")
+
+	payload := map[string]interface{}{
+		"chat_id":    c.chatID,
+		"text":       text,
+		"parse_mode": "Markdown",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	retryAfter, err := c.post(jsonData)
+	if err != nil && retryAfter > 0 {
+		wait := time.Duration(retryAfter) * time.Second
+		if wait > c.maxRetryWait {
+			return fmt.Errorf("%w; not retrying, the wait exceeds %s", err, c.maxRetryWait)
+		}
+		time.Sleep(wait)
+		_, err = c.post(jsonData)
+	}
+	return err
+}
+
+// post sends one request, returning the retry_after hint when rate limited
+func (c *TelegramClient) post(jsonData []byte) (int, error) {
+	url := fmt.Sprintf("%s/bot%s/sendMessage", c.apiURL, c.botToken)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode == http.StatusOK {
+		return 0, nil
+	}
+
+	var tgResp telegramResponse
+	json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&tgResp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return tgResp.Parameters.RetryAfter, fmt.Errorf("rate limited, retry after %ds", tgResp.Parameters.RetryAfter)
+	}
+
+	return 0, fmt.Errorf("unexpected status code: %d (%s)", resp.StatusCode, tgResp.Description)
+}
@@ -0,0 +1,98 @@
+package alerting
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTelegramSendRequest(t *testing.T) {
+	var gotPath, gotContentType string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &gotBody); err != nil {
+			t.Errorf("body %q is not JSON: %v", data, err)
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	client := NewTelegramClient("123:ABC", "-1001", 5*time.Second)
+	client.apiURL = server.URL
+
+	if err := client.Send("**Volume Surge**\nMarket: MKT"); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/bot123:ABC/sendMessage" {
+		t.Errorf("path = %s, want /bot123:ABC/sendMessage", gotPath)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("content type = %s", gotContentType)
+	}
+	want := map[string]interface{}{
+		"chat_id":    "-1001",
+		"text":       "*Volume Surge*\nMarket: MKT",
+		"parse_mode": "Markdown",
+	}
+	for key, value := range want {
+		if gotBody[key] != value {
+			t.Errorf("body[%s] = %v, want %v", key, gotBody[key], value)
+		}
+	}
+}
+
+func TestTelegramRetriesAfterRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok": false, "error_code": 429, "parameters": {"retry_after": 1}}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	client := NewTelegramClient("token", "chat", 5*time.Second)
+	client.apiURL = server.URL
+
+	if err := client.Send("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Fatalf("got %d requests, want 2", requests)
+	}
+}
+
+func TestTelegramDoesNotWaitOutLongRateLimits(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok": false, "error_code": 429, "parameters": {"retry_after": 3600}}`))
+	}))
+	defer server.Close()
+
+	client := NewTelegramClient("token", "chat", 5*time.Second)
+	client.apiURL = server.URL
+
+	start := time.Now()
+	err := client.Send("hello")
+	if err == nil {
+		t.Fatal("send succeeded against a persistent rate limit")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("send blocked for %s on an hour-long retry_after", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 with no retry", requests)
+	}
+}
@@ -0,0 +1,65 @@
+package alerting
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// templateData is what a message template renders against: every signal field
+// plus the dashboard base URL and the market's link on it
+type templateData struct {
+	signals.Signal
+	DashboardBaseURL string
+	URL              string
+}
+
+var templateFuncs = template.FuncMap{
+	"pct": func(x float64) string { return fmt.Sprintf("%.0f", x*100) },
+}
+
+// parseTemplates compiles the configured per-type templates. Templates that fail
+// to parse are logged and skipped so that type keeps the built-in format.
+func parseTemplates(raw map[string]string) map[signals.SignalType]*template.Template {
+	templates := make(map[signals.SignalType]*template.Template)
+	for signalType, text := range raw {
+		if text == "" {
+			continue
+		}
+		tmpl, err := template.New(signalType).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			fmt.Printf("Invalid alert template for %s: %v\n", signalType, err)
+			continue
+		}
+		templates[signals.SignalType(signalType)] = tmpl
+	}
+	return templates
+}
+
+// renderTemplate renders the custom template for the signal's type. It reports
+// false when there is none or rendering fails, e.g. a template referencing a
+// nil data section such as {{.VolumeSurge.VolumeMultiplier}} on another type.
+func (m *Manager) renderTemplate(signal signals.Signal) (string, bool) {
+	tmpl, ok := m.templates[signal.Type]
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	data := templateData{
+		Signal:           signal,
+		DashboardBaseURL: m.config.DashboardBaseURL,
+		URL:              config.MarketURL(m.config.DashboardBaseURL, signal.MarketTicker),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		fmt.Printf("Failed to render alert template for %s: %v\n", signal.Type, err)
+		return "", false
+	}
+	if buf.Len() == 0 {
+		return "", false
+	}
+	return buf.String(), true
+}
@@ -0,0 +1,43 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+func TestRenderCustomTemplate(t *testing.T) {
+	m := NewManager(config.AlertingConfig{
+		DashboardBaseURL: "https://dash.example.com/",
+		Templates: map[string]string{
+			"volume_surge":  `{{.MarketTicker}} {{printf "%.1f" .VolumeSurge.VolumeMultiplier}}x ({{pct .Metadata.Confidence}}%) {{.URL}} via {{.DashboardBaseURL}}`,
+			"quote_flicker": `{{.OrderbookImbalance.BidRatio}}`, // nil section for this type
+			"broken":        `{{.MarketTicker`,
+		},
+	}, nil)
+
+	surge := signals.Signal{
+		MarketTicker: "PRES-2028",
+		Type:         signals.SignalTypeVolumeSurge,
+		Metadata:     signals.SignalMetadata{Confidence: 0.42},
+		VolumeSurge:  &signals.VolumeSurgeData{VolumeMultiplier: 3.24},
+	}
+	want := "PRES-2028 3.2x (42%) https://dash.example.com/markets/PRES-2028 via https://dash.example.com/"
+	if got := m.formatSignalMessage(surge); got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+
+	// A template that fails to render falls back to the built-in format
+	flicker := signals.Signal{MarketTicker: "MKT", Type: signals.SignalTypeQuoteFlicker}
+	if _, ok := m.renderTemplate(flicker); ok {
+		t.Error("template over a nil section rendered")
+	}
+	if got := m.formatSignalMessage(flicker); got == "" {
+		t.Error("no built-in fallback message")
+	}
+
+	if _, ok := m.templates["broken"]; ok {
+		t.Error("unparseable template was kept")
+	}
+}
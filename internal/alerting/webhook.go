@@ -0,0 +1,52 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookClient posts alert messages as {"text": ...} to an arbitrary endpoint
+type WebhookClient struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookClient(url string, timeout time.Duration) *WebhookClient {
+	return &WebhookClient{
+		url:    url,
+		client: newHTTPClient(timeout),
+	}
+}
+
+func (c *WebhookClient) Send(message string) error {
+	payload := map[string]string{
+		"text": message,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
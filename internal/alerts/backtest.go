@@ -2,6 +2,7 @@ package alerts
 
 import (
 	"math"
+	"sync"
 	"time"
 
 	"github.com/kalshi-signal-feed/internal/state"
@@ -10,14 +11,18 @@ import (
 // BacktestHarness validates alerts against historical data
 type BacktestHarness struct {
 	state *state.Engine
+	mu    sync.RWMutex
 	stats map[string]AlertStats // alert_type_market -> stats
 }
 
 type AlertStats struct {
-	HitRate    float64
-	SampleSize int
-	AvgMove    float64 // average price move after alert
-	Confidence float64 // derived from hit rate and sample size
+	HitRate    float64 `json:"hit_rate"`
+	SampleSize int     `json:"sample_size"`
+	AvgMove    float64 `json:"avg_move"`   // average price move after alert, cents
+	// Average move in the alert's direction, cents: a sell alert followed by a
+	// fall counts positive. Undirected alerts count the move's size.
+	AvgFavorableMove float64 `json:"avg_favorable_move"`
+	Confidence float64 `json:"confidence"` // derived from hit rate and sample size
 }
 
 func NewBacktestHarness(stateEngine *state.Engine) *BacktestHarness {
@@ -31,7 +36,9 @@ func NewBacktestHarness(stateEngine *state.Engine) *BacktestHarness {
 func (b *BacktestHarness) GetAlertStats(marketTicker string, alertType AlertType) (confidence, hitRate float64, sampleSize int) {
 	key := string(alertType) + "_" + marketTicker
 	
+	b.mu.RLock()
 	stats, exists := b.stats[key]
+	b.mu.RUnlock()
 	if !exists {
 		// No historical data yet - return low confidence to indicate uncertainty
 		// Use 0.3 (30%) instead of 0.5 to show we don't have enough data
@@ -41,50 +48,106 @@ func (b *BacktestHarness) GetAlertStats(marketTicker string, alertType AlertType
 	return stats.Confidence, stats.HitRate, stats.SampleSize
 }
 
-// BacktestAlert validates an alert against historical data
+// GetExpectedValueScore weighs an alert type's confidence by the average
+// favorable move that followed it, so a reliable signal with tiny moves ranks
+// below a less reliable one with large moves, and one that keeps calling the
+// wrong direction scores negative. The score is in cents of expected move; it
+// is 0 until there is backtest history.
+func (b *BacktestHarness) GetExpectedValueScore(marketTicker string, alertType AlertType) float64 {
+	key := string(alertType) + "_" + marketTicker
+
+	b.mu.RLock()
+	stats, exists := b.stats[key]
+	b.mu.RUnlock()
+	if !exists {
+		return 0
+	}
+
+	return expectedValueScore(stats)
+}
+
+func expectedValueScore(stats AlertStats) float64 {
+	return stats.Confidence * stats.AvgFavorableMove
+}
+
+// favorableMove signs a price move by the alert's direction
+func favorableMove(alert Alert, priceMove float64) float64 {
+	switch alert.Action {
+	case "buy":
+		return priceMove
+	case "sell":
+		return -priceMove
+	default:
+		return math.Abs(priceMove)
+	}
+}
+
+// BacktestAlert validates an alert against historical data and folds the
+// outcome into the stats used to score live alerts
 func (b *BacktestHarness) BacktestAlert(alert Alert, lookbackWindow time.Duration) AlertStats {
+	priceMove, hit, ok := b.evaluate(alert, lookbackWindow)
+	if !ok {
+		return AlertStats{}
+	}
+
+	// Update stats
+	key := string(alert.Type) + "_" + alert.MarketTicker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats[key]
+	stats.add(priceMove, favorableMove(alert, priceMove), hit)
+	b.stats[key] = stats
+
+	return stats
+}
+
+// Evaluate backtests a set of alerts and returns their combined stats without
+// touching the stats used to score live alerts. Alerts whose lookback window
+// isn't covered by recorded snapshots are left out of the sample.
+func (b *BacktestHarness) Evaluate(alerts []Alert, lookbackWindow time.Duration) AlertStats {
+	var stats AlertStats
+	for _, alert := range alerts {
+		if priceMove, hit, ok := b.evaluate(alert, lookbackWindow); ok {
+			stats.add(priceMove, favorableMove(alert, priceMove), hit)
+		}
+	}
+	return stats
+}
+
+// evaluate measures the mid move (cents) from the last mid at or before the
+// alert to the first one a lookback window after it, and whether that move
+// bears the alert out. ok is false when either mid is missing, e.g. the window
+// hasn't elapsed yet or the market has no history that far back.
+func (b *BacktestHarness) evaluate(alert Alert, lookbackWindow time.Duration) (priceMove float64, hit bool, ok bool) {
 	ts := b.state.GetTimeSeries()
-	
-	// Get snapshots before and after alert time
+
 	beforeTime := alert.Timestamp.Add(-lookbackWindow)
 	afterTime := alert.Timestamp.Add(lookbackWindow)
-	
-	beforeSnapshots := ts.GetSnapshots(alert.MarketTicker, beforeTime)
-	afterSnapshots := ts.GetSnapshots(alert.MarketTicker, afterTime)
-	
-	if len(beforeSnapshots) == 0 || len(afterSnapshots) == 0 {
-		return AlertStats{
-			HitRate:    0.0,
-			SampleSize: 0,
-			Confidence: 0.0,
-		}
-	}
-	
-	// Find snapshot closest to alert time
-	var beforeSnapshot, afterSnapshot state.MarketSnapshot
-	beforeSnapshot = beforeSnapshots[len(beforeSnapshots)-1]
-	
-	// Find snapshot after alert
-	for _, snap := range afterSnapshots {
-		if snap.Timestamp.After(alert.Timestamp) {
-			afterSnapshot = snap
+
+	// Mid history falls back to minute and hour bar closes once snapshots
+	// have been compacted, so alerts older than the full-resolution window
+	// can still be evaluated (at bar resolution)
+	var before, after *state.MidPoint
+	history := ts.GetMidHistory(alert.MarketTicker, beforeTime)
+	for i := range history {
+		point := &history[i]
+		if !point.Timestamp.After(alert.Timestamp) {
+			before = point
+		} else if !point.Timestamp.Before(afterTime) {
+			after = point
 			break
 		}
 	}
-	
-	if afterSnapshot.MarketTicker == "" {
-		return AlertStats{
-			HitRate:    0.0,
-			SampleSize: 0,
-			Confidence: 0.0,
-		}
+
+	if before == nil || after == nil {
+		return 0, false, false
 	}
-	
-	// Calculate price move
-	priceMove := afterSnapshot.MidPrice - beforeSnapshot.MidPrice
-	
+
+	// Mids are probabilities; moves are compared in cents
+	priceMove = (after.Mid - before.Mid) * 100
+
 	// Determine if alert was "correct" based on type
-	hit := false
 	switch alert.Type {
 	case AlertTypeImbalancePressure:
 		// If imbalance suggests buy and price went up, it's a hit
@@ -99,45 +162,37 @@ func (b *BacktestHarness) BacktestAlert(alert Alert, lookbackWindow time.Duratio
 			hit = true
 		}
 	case AlertTypeNoArbViolation:
-		// No-arb should be profitable if executed
-		hit = alert.EstimatedEdge > alert.EstimatedSlippage
+		// No-arb should be profitable if executed (edge is already net of slippage)
+		hit = alert.EstimatedEdge > 0
 	default:
 		hit = math.Abs(priceMove) > 0.5
 	}
-	
-	// Update stats
-	key := string(alert.Type) + "_" + alert.MarketTicker
-	stats, exists := b.stats[key]
-	if !exists {
-		stats = AlertStats{
-			SampleSize: 0,
-			HitRate:    0.0,
-			AvgMove:    0.0,
-		}
-	}
-	
-	stats.SampleSize++
+
+	return priceMove, hit, true
+}
+
+// add folds one backtested alert into the running stats
+func (s *AlertStats) add(priceMove, favorable float64, hit bool) {
+	s.SampleSize++
+	n := float64(s.SampleSize)
+
+	hits := s.HitRate * (n - 1)
 	if hit {
-		stats.HitRate = (stats.HitRate*float64(stats.SampleSize-1) + 1.0) / float64(stats.SampleSize)
-	} else {
-		stats.HitRate = (stats.HitRate * float64(stats.SampleSize-1)) / float64(stats.SampleSize)
+		hits++
 	}
-	
-	stats.AvgMove = (stats.AvgMove*float64(stats.SampleSize-1) + priceMove) / float64(stats.SampleSize)
-	
+	s.HitRate = hits / n
+	s.AvgMove = (s.AvgMove*(n-1) + priceMove) / n
+	s.AvgFavorableMove = (s.AvgFavorableMove*(n-1) + favorable) / n
+
 	// Confidence = hit rate adjusted by sample size
 	// More samples = higher confidence in hit rate
-	if stats.SampleSize < 10 {
-		stats.Confidence = stats.HitRate * 0.5 // Low confidence with few samples
-	} else if stats.SampleSize < 50 {
-		stats.Confidence = stats.HitRate * 0.75
+	if s.SampleSize < 10 {
+		s.Confidence = s.HitRate * 0.5 // Low confidence with few samples
+	} else if s.SampleSize < 50 {
+		s.Confidence = s.HitRate * 0.75
 	} else {
-		stats.Confidence = stats.HitRate // High confidence with many samples
+		s.Confidence = s.HitRate // High confidence with many samples
 	}
-	
-	b.stats[key] = stats
-	
-	return stats
 }
 
 // RunBacktest runs backtest on all historical alerts
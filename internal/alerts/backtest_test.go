@@ -0,0 +1,141 @@
+package alerts
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// Run with -race: live scoring reads stats while background backtests write them
+func TestBacktestStatsConcurrentAccess(t *testing.T) {
+	stateEngine := state.NewEngine()
+	ts := stateEngine.GetTimeSeries()
+
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+	ts.RecordSnapshot("MKT", ob, nil)
+	alertTime := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	ob.Bids = []state.PriceLevel{{Price: 50, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 52, Quantity: 100}}
+	ts.RecordSnapshot("MKT", ob, nil)
+
+	// The alert sits between the two snapshots so every backtest has a sample
+	harness := NewBacktestHarness(stateEngine)
+	alert := Alert{MarketTicker: "MKT", Type: AlertTypeImbalancePressure, Action: "buy", Timestamp: alertTime}
+
+	const writes = 200
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				harness.BacktestAlert(alert, time.Millisecond)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				harness.GetAlertStats("MKT", AlertTypeImbalancePressure)
+				harness.GetExpectedValueScore("MKT", AlertTypeImbalancePressure)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, hitRate, sampleSize := harness.GetAlertStats("MKT", AlertTypeImbalancePressure)
+	if sampleSize != 4*writes {
+		t.Errorf("sample size = %d, want %d", sampleSize, 4*writes)
+	}
+	if hitRate != 1 {
+		t.Errorf("hit rate = %.2f, want 1 (buy alert before a 5¢ rise)", hitRate)
+	}
+}
+
+func TestExpectedValueScoreWeighsMoveSize(t *testing.T) {
+	profile := func(samples, hits int, move float64) AlertStats {
+		var stats AlertStats
+		for i := 0; i < samples; i++ {
+			stats.add(move, move, i < hits)
+		}
+		return stats
+	}
+
+	// 90% hit rate but 0.6¢ moves vs 60% hit rate with 8¢ moves
+	reliable := profile(20, 18, 0.6)
+	volatile := profile(20, 12, 8)
+	if reliable.Confidence <= volatile.Confidence {
+		t.Fatalf("confidence %.2f vs %.2f, want the reliable profile higher", reliable.Confidence, volatile.Confidence)
+	}
+	if r, v := expectedValueScore(reliable), expectedValueScore(volatile); r >= v {
+		t.Errorf("EV score reliable=%.2f volatile=%.2f, want the large-move profile higher", r, v)
+	}
+}
+
+func TestExpectedValueScoreSignsMoveByDirection(t *testing.T) {
+	// Buy alerts after rises and sell alerts after falls: the raw moves cancel
+	// but every alert called its direction
+	var right AlertStats
+	for i := 0; i < 10; i++ {
+		buy := Alert{Action: "buy"}
+		sell := Alert{Action: "sell"}
+		right.add(3, favorableMove(buy, 3), true)
+		right.add(-3, favorableMove(sell, -3), true)
+	}
+	if right.AvgMove != 0 {
+		t.Fatalf("avg move = %.2f, want the raw moves to cancel", right.AvgMove)
+	}
+	if score := expectedValueScore(right); score <= 0 {
+		t.Errorf("EV score = %.2f for alerts that called their direction, want positive", score)
+	}
+
+	// Sell alerts followed by rises: big moves, all the wrong way
+	var wrong AlertStats
+	for i := 0; i < 10; i++ {
+		wrong.add(5, favorableMove(Alert{Action: "sell"}, 5), i < 5)
+	}
+	if score := expectedValueScore(wrong); score >= 0 {
+		t.Errorf("EV score = %.2f for alerts that called the wrong direction, want negative", score)
+	}
+}
+
+func TestLiveAlertsFeedBacktestStats(t *testing.T) {
+	stateEngine := state.NewEngine()
+	ts := stateEngine.GetTimeSeries()
+	engine := NewEngine(stateEngine, config.ScannerConfig{})
+	engine.backtestLookback = time.Millisecond
+
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+	ts.RecordSnapshot("MKT", ob, nil)
+	alert := Alert{MarketTicker: "MKT", Type: AlertTypeImbalancePressure, Action: "buy", Timestamp: time.Now()}
+	engine.pendingBacktests = []Alert{alert}
+
+	// Not due yet: the alert stays queued
+	engine.backtestMatured(alert.Timestamp)
+	if len(engine.pendingBacktests) != 1 {
+		t.Fatalf("%d alerts pending before the lookback elapsed, want 1", len(engine.pendingBacktests))
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	ob.Bids = []state.PriceLevel{{Price: 50, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 52, Quantity: 100}}
+	ts.RecordSnapshot("MKT", ob, nil)
+
+	engine.backtestMatured(time.Now())
+	if _, _, n := engine.backtest.GetAlertStats("MKT", AlertTypeImbalancePressure); n != 1 {
+		t.Fatalf("sample size = %d after the lookback elapsed, want 1", n)
+	}
+	if score := engine.backtest.GetExpectedValueScore("MKT", AlertTypeImbalancePressure); score <= 0 {
+		t.Errorf("EV score = %.2f for a buy alert before a rise, want positive", score)
+	}
+	if len(engine.pendingBacktests) != 0 {
+		t.Errorf("%d alerts still pending after backtesting", len(engine.pendingBacktests))
+	}
+}
@@ -0,0 +1,36 @@
+package alerts
+
+import "time"
+
+// ArbCooldown is the exported form of a no-arb report, so the cooldown can be
+// saved and restored across restarts
+type ArbCooldown struct {
+	EventTicker string    `json:"event_ticker"`
+	At          time.Time `json:"at"`
+	NetArb      float64   `json:"net_arb"`
+}
+
+// ArbCooldowns returns the no-arb reports still inside their cooldown. Like
+// CheckAlerts it must not run concurrently with the alert loop.
+func (e *Engine) ArbCooldowns(now time.Time) []ArbCooldown {
+	cooldown := time.Duration(e.config.NoArbCooldownSecs) * time.Second
+
+	var active []ArbCooldown
+	for event, r := range e.reportedArbs {
+		if now.Sub(r.at) < cooldown {
+			active = append(active, ArbCooldown{EventTicker: event, At: r.at, NetArb: r.netArb})
+		}
+	}
+	return active
+}
+
+// RestoreArbCooldowns reinstates saved no-arb reports, dropping expired ones
+func (e *Engine) RestoreArbCooldowns(saved []ArbCooldown, now time.Time) {
+	cooldown := time.Duration(e.config.NoArbCooldownSecs) * time.Second
+
+	for _, c := range saved {
+		if now.Sub(c.At) < cooldown {
+			e.reportedArbs[c.EventTicker] = reportedArb{at: c.At, netArb: c.NetArb}
+		}
+	}
+}
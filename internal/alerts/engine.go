@@ -1,8 +1,11 @@
 package alerts
 
 import (
+	"sort"
 	"time"
 
+	"github.com/kalshi-signal-feed/internal/audit"
+	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/scanner"
 	"github.com/kalshi-signal-feed/internal/state"
 )
@@ -17,6 +20,7 @@ const (
 	AlertTypeNoArbViolation     AlertType = "no_arb_violation"
 	AlertTypeExecutionReady     AlertType = "execution_ready"
 	AlertTypePriceDrift         AlertType = "price_drift"
+	AlertTypeExpiringSoon       AlertType = "expiring_soon"
 )
 
 // Alert represents a mechanical trading alert
@@ -40,6 +44,7 @@ type Alert struct {
 	// Confidence (from backtesting)
 	Confidence    float64                `json:"confidence"`     // 0-1
 	HitRate       float64                `json:"hit_rate"`       // historical hit rate
+	ExpectedValueScore float64           `json:"expected_value_score"` // confidence x avg favorable move (cents)
 	SampleSize    int                    `json:"sample_size"`     // number of historical samples
 	
 	// Execution context
@@ -51,20 +56,54 @@ type Alert struct {
 	// Risk context
 	TimeToExpiry   float64 `json:"time_to_expiry"` // hours
 	CurrentExposure float64 `json:"current_exposure"` // if tracking positions
+
+	// Dashboard market view, when a dashboard base URL is configured (market
+	// alerts only; no-arb alerts cover a whole event)
+	URL string `json:"url,omitempty"`
 }
 
-// Engine generates mechanical alerts based on market conditions
+const (
+	// maxAlertHistoryPerMarket caps retained alerts per market
+	maxAlertHistoryPerMarket = 100
+	// alertHistoryRetention drops alerts older than this; it comfortably covers
+	// the backtest lookback so nothing a backtest needs is evicted
+	alertHistoryRetention = 24 * time.Hour
+	// liveBacktestLookback is the default move horizon live alerts are scored
+	// over once it has elapsed
+	liveBacktestLookback = 5 * time.Minute
+)
+
+// Engine generates mechanical alerts based on market conditions.
+// It is meant to be constructed once and reused across scan cycles.
 type Engine struct {
 	state        *state.Engine
 	scanner      *scanner.Scanner
 	noArbEngine  *scanner.NoArbEngine
 	backtest     *BacktestHarness
 	alertHistory map[string][]Alert // market_ticker -> alerts
+	pendingBacktests []Alert        // live alerts awaiting their lookback window
+	backtestLookback time.Duration  // move horizon live alerts are scored over
+	auditLog     *audit.Log
+	config       config.ScannerConfig
+	dashboardURL string // base URL for alert links ("" omits them)
+
+	// Last no-arb alert per event, to suppress repeats across scan cycles
+	reportedArbs map[string]reportedArb
+
+	// Time-to-expiration thresholds, largest first, and the smallest one
+	// already alerted per market
+	expiryThresholds []time.Duration
+	reportedExpiries map[string]time.Duration
+}
+
+type reportedArb struct {
+	at     time.Time
+	netArb float64
 }
 
-func NewEngine(stateEngine *state.Engine) *Engine {
-	scan := scanner.NewScanner(stateEngine)
-	noArbEngine := scanner.NewNoArbEngine(stateEngine)
+func NewEngine(stateEngine *state.Engine, scannerCfg config.ScannerConfig) *Engine {
+	scan := scanner.NewScanner(stateEngine, scannerCfg)
+	noArbEngine := scanner.NewNoArbEngine(stateEngine, scannerCfg)
 	backtest := NewBacktestHarness(stateEngine)
 	
 	return &Engine{
@@ -73,34 +112,88 @@ func NewEngine(stateEngine *state.Engine) *Engine {
 		noArbEngine:  noArbEngine,
 		backtest:     backtest,
 		alertHistory: make(map[string][]Alert),
+		backtestLookback: liveBacktestLookback,
+		config:       scannerCfg,
+		reportedArbs: make(map[string]reportedArb),
+		expiryThresholds: expiryThresholds(scannerCfg.ExpiryAlertMinutes),
+		reportedExpiries: make(map[string]time.Duration),
 	}
 }
 
+// SetAuditLog attaches an audit log that receives every generated alert
+func (e *Engine) SetAuditLog(auditLog *audit.Log) {
+	e.auditLog = auditLog
+}
+
+// SetDashboardBaseURL makes generated alerts link to the dashboard market view
+func (e *Engine) SetDashboardBaseURL(baseURL string) {
+	e.dashboardURL = baseURL
+}
+
+// Backtest evaluates previously generated alerts against the recorded book
+// history, measuring the move over lookback after each one. The live alert
+// confidence stats are left untouched.
+func (e *Engine) Backtest(history []Alert, lookback time.Duration) AlertStats {
+	return e.backtest.Evaluate(history, lookback)
+}
+
 // CheckAlerts scans markets and generates alerts
 func (e *Engine) CheckAlerts() []Alert {
 	var alerts []Alert
 	
 	// Check all opportunities
 	opportunities := e.scanner.ScanMarkets()
+	now := time.Now().UTC()
 	
 	for _, opp := range opportunities {
-		marketAlerts := e.checkMarketAlerts(opp)
-		alerts = append(alerts, marketAlerts...)
+		alerts = append(alerts, e.checkMarketAlerts(opp)...)
+	}
+
+	// Expiry is checked over every tradeable market, including the one-sided
+	// books the scanner leaves out
+	markets := e.state.GetAllMarkets()
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].Ticker < markets[j].Ticker
+	})
+	for _, market := range markets {
+		if !market.Tradeable {
+			continue
+		}
+		if alert, ok := e.checkExpiringSoon(market, now); ok {
+			alerts = append(alerts, alert)
+		}
 	}
 	
 	// Check no-arb violations
 	violations := e.noArbEngine.CheckNoArbViolations()
 	for _, violation := range violations {
-		if violation.Actionable {
+		if violation.Actionable && e.shouldReportArb(violation, now) {
 			alert := e.createNoArbAlert(violation)
 			alerts = append(alerts, alert)
 		}
 	}
 	
 	// Store in history
-	for _, alert := range alerts {
-		e.alertHistory[alert.MarketTicker] = append(e.alertHistory[alert.MarketTicker], alert)
+	for i := range alerts {
+		// No-arb alerts are keyed by event ticker, which has no dashboard view
+		if alerts[i].Type != AlertTypeNoArbViolation {
+			alerts[i].URL = config.MarketURL(e.dashboardURL, alerts[i].MarketTicker)
+		}
+		e.attachRecentSignals(&alerts[i])
+		if alerts[i].TimeToExpiry == 0 {
+			alerts[i].TimeToExpiry = e.hoursToExpiry(alerts[i].MarketTicker, now)
+		}
+		e.recordHistory(alerts[i])
+		e.auditLog.Write("alert", alerts[i])
+		// No-arb alerts are scored by their edge, not a recorded market move
+		if alerts[i].Type != AlertTypeNoArbViolation {
+			e.pendingBacktests = append(e.pendingBacktests, alerts[i])
+		}
 	}
+
+	e.backtestMatured(now)
+
+	e.pruneHistory(now)
 	
 	return alerts
 }
@@ -115,7 +208,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 			Type:         AlertTypeSpreadTightened,
 			MarketTicker: opp.MarketTicker,
 			Title:        opp.Title,
-			Timestamp:    time.Now(),
+			Timestamp:    time.Now().UTC(),
 			Reason:       "Spread tightened below 0.5%",
 			Inputs: map[string]interface{}{
 				"spread_percent": opp.SpreadPercent,
@@ -133,6 +226,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 		alert.Confidence = confidence
 		alert.HitRate = hitRate
 		alert.SampleSize = sampleSize
+		alert.ExpectedValueScore = e.backtest.GetExpectedValueScore(opp.MarketTicker, AlertTypeSpreadTightened)
 		
 		alerts = append(alerts, alert)
 	}
@@ -144,7 +238,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 			Type:         AlertTypeDepthIncreased,
 			MarketTicker: opp.MarketTicker,
 			Title:        opp.Title,
-			Timestamp:    time.Now(),
+			Timestamp:    time.Now().UTC(),
 			Reason:       "Depth at top-5 levels exceeds 500 contracts",
 			Inputs: map[string]interface{}{
 				"depth_at_top5": opp.DepthAtTop5,
@@ -153,7 +247,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 			CurrentValue:  float64(opp.DepthAtTop5),
 			Suggestion:    "High liquidity: can execute larger size",
 			Action:        "watch",
-			CanExecute:    true,
+			CanExecute:    !opp.BookStale,
 			RecommendedSize: int(opp.DepthAtTop5 / 2), // Conservative
 		}
 		
@@ -161,12 +255,19 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 		alert.Confidence = confidence
 		alert.HitRate = hitRate
 		alert.SampleSize = sampleSize
+		alert.ExpectedValueScore = e.backtest.GetExpectedValueScore(opp.MarketTicker, AlertTypeDepthIncreased)
 		
 		alerts = append(alerts, alert)
 	}
 	
+	// Execution-oriented alerts below recommend trading now, so a stale book
+	// can't produce them
+	if opp.BookStale {
+		return alerts
+	}
+
 	// 3. Imbalance pressure (imbalance high but price hasn't moved)
-	if absFloat(opp.Imbalance) > 0.6 && absFloat(opp.MicropriceDiff) > 1.0 {
+	if e.imbalancePressure(opp) {
 		direction := "buy"
 		if opp.Imbalance < 0 {
 			direction = "sell"
@@ -177,13 +278,14 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 			Type:         AlertTypeImbalancePressure,
 			MarketTicker: opp.MarketTicker,
 			Title:        opp.Title,
-			Timestamp:    time.Now(),
+			Timestamp:    time.Now().UTC(),
 			Reason:       "Strong orderbook imbalance detected with price lag",
 			Inputs: map[string]interface{}{
 				"imbalance":      opp.Imbalance,
 				"microprice_diff": opp.MicropriceDiff,
+				"price_lag_threshold": e.config.ImbalancePriceLagCents,
 			},
-			Threshold:    0.6,
+			Threshold:    e.config.ImbalancePressureThreshold,
 			CurrentValue: absFloat(opp.Imbalance),
 			Suggestion:   "Pressure detected: watch for price movement",
 			Action:       direction,
@@ -195,6 +297,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 		alert.Confidence = confidence
 		alert.HitRate = hitRate
 		alert.SampleSize = sampleSize
+		alert.ExpectedValueScore = e.backtest.GetExpectedValueScore(opp.MarketTicker, AlertTypeImbalancePressure)
 		
 		alerts = append(alerts, alert)
 	}
@@ -206,7 +309,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 			Type:         AlertTypeExecutionReady,
 			MarketTicker: opp.MarketTicker,
 			Title:        opp.Title,
-			Timestamp:    time.Now(),
+			Timestamp:    time.Now().UTC(),
 			Reason:       "Optimal execution conditions: tight spread + good depth",
 			Inputs: map[string]interface{}{
 				"liquidity_score": opp.LiquidityScore,
@@ -225,6 +328,7 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 		alert.Confidence = confidence
 		alert.HitRate = hitRate
 		alert.SampleSize = sampleSize
+		alert.ExpectedValueScore = e.backtest.GetExpectedValueScore(opp.MarketTicker, AlertTypeExecutionReady)
 		
 		alerts = append(alerts, alert)
 	}
@@ -232,39 +336,170 @@ func (e *Engine) checkMarketAlerts(opp scanner.MarketOpportunity) []Alert {
 	return alerts
 }
 
+// attachRecentSignals lists the signals the processor emitted for the alert's
+// market during the lookback window in the alert's inputs, most recent last,
+// along with their IDs so the alert can be joined to the signal feed
+func (e *Engine) attachRecentSignals(alert *Alert) {
+	if e.config.AlertSignalLookbackSecs <= 0 {
+		return
+	}
+
+	since := alert.Timestamp.Add(-time.Duration(e.config.AlertSignalLookbackSecs) * time.Second)
+	points := e.state.GetTimeSeries().GetSignals(alert.MarketTicker, since)
+	if len(points) == 0 {
+		return
+	}
+
+	recent := make([]map[string]interface{}, 0, len(points))
+	ids := make([]string, 0, len(points))
+	for _, point := range points {
+		id, _ := point.Metadata["id"].(string)
+		if id != "" {
+			ids = append(ids, id)
+		}
+		recent = append(recent, map[string]interface{}{
+			"id":         id,
+			"type":       point.Type,
+			"value":      point.Value,
+			"timestamp":  point.Timestamp,
+			"confidence": point.Metadata["confidence"],
+			"severity":   point.Metadata["severity"],
+		})
+	}
+
+	if alert.Inputs == nil {
+		alert.Inputs = make(map[string]interface{})
+	}
+	alert.Inputs["recent_signals"] = recent
+	alert.Inputs["signal_ids"] = ids
+}
+
+// imbalancePressure gates the imbalance-pressure alert on two independent
+// conditions: the book is lopsided, and the microprice hasn't converged to mid
+func (e *Engine) imbalancePressure(opp scanner.MarketOpportunity) bool {
+	imbalanced := absFloat(opp.Imbalance) > e.config.ImbalancePressureThreshold
+	lagging := absFloat(opp.MicropriceDiff) > e.config.ImbalancePriceLagCents
+	return imbalanced && lagging
+}
+
+// recordHistory appends an alert to its market's history, keeping at most
+// maxAlertHistoryPerMarket of the most recent
+func (e *Engine) recordHistory(alert Alert) {
+	history := append(e.alertHistory[alert.MarketTicker], alert)
+	if len(history) > maxAlertHistoryPerMarket {
+		history = history[len(history)-maxAlertHistoryPerMarket:]
+	}
+	e.alertHistory[alert.MarketTicker] = history
+}
+
+// backtestMatured folds alerts whose lookback window has elapsed into the
+// stats behind live confidence and EV scores. An alert whose window isn't
+// covered by recorded snapshots yet (the next poll hasn't landed) is retried
+// until it is a full window overdue, then dropped.
+func (e *Engine) backtestMatured(now time.Time) {
+	pending := e.pendingBacktests[:0]
+	for _, alert := range e.pendingBacktests {
+		due := alert.Timestamp.Add(e.backtestLookback)
+		if now.Before(due) {
+			pending = append(pending, alert)
+			continue
+		}
+		stats := e.backtest.BacktestAlert(alert, e.backtestLookback)
+		if stats.SampleSize == 0 && now.Before(due.Add(e.backtestLookback)) {
+			pending = append(pending, alert)
+		}
+	}
+	e.pendingBacktests = pending
+}
+
+// pruneHistory evicts alerts past the retention window and drops history for
+// markets that are no longer active. No-arb alerts are keyed by event ticker,
+// which isn't a market, so those only age out by time.
+func (e *Engine) pruneHistory(now time.Time) {
+	cutoff := now.Add(-alertHistoryRetention)
+
+	for ticker := range e.reportedExpiries {
+		if market, exists := e.state.GetMarket(ticker); !exists || market.Status != state.StatusActive {
+			delete(e.reportedExpiries, ticker)
+		}
+	}
+
+	for ticker, history := range e.alertHistory {
+		if market, exists := e.state.GetMarket(ticker); exists && market.Status != state.StatusActive {
+			delete(e.alertHistory, ticker)
+			continue
+		}
+
+		expired := 0
+		for expired < len(history) && history[expired].Timestamp.Before(cutoff) {
+			expired++
+		}
+		if expired == len(history) {
+			delete(e.alertHistory, ticker)
+		} else if expired > 0 {
+			e.alertHistory[ticker] = history[expired:]
+		}
+	}
+}
+
+// shouldReportArb reports a violation once per cooldown per event, unless the
+// net edge has moved materially since it was last reported
+func (e *Engine) shouldReportArb(violation scanner.NoArbViolation, now time.Time) bool {
+	last, reported := e.reportedArbs[violation.EventTicker]
+	if reported {
+		cooldown := time.Duration(e.config.NoArbCooldownSecs) * time.Second
+		edgeChange := absFloat(violation.NetArb-last.netArb) * 100 // cents
+		if now.Sub(last.at) < cooldown && edgeChange < e.config.NoArbEdgeChangeCents {
+			return false
+		}
+	}
+
+	e.reportedArbs[violation.EventTicker] = reportedArb{at: now, netArb: violation.NetArb}
+	return true
+}
+
 func (e *Engine) createNoArbAlert(violation scanner.NoArbViolation) Alert {
+	// Price the trade at the size we actually recommend so edge and CanExecute agree
+	recommendedSize := int(violation.Liquidity)
+	estimate := e.noArbEngine.EstimateExecution(violation, recommendedSize, e.noArbEngine.DefaultExecutionStyle())
+
 	alert := Alert{
 		ID:           generateAlertID(violation.EventTicker, AlertTypeNoArbViolation),
 		Type:         AlertTypeNoArbViolation,
 		MarketTicker: violation.EventTicker,
 		Title:        violation.FormatViolation(),
-		Timestamp:    time.Now(),
+		Timestamp:    time.Now().UTC(),
 		Reason:       "Arbitrage opportunity detected",
 		Inputs: map[string]interface{}{
 			"sum_buy_price":  violation.SumBuyPrice,
 			"sum_sell_price": violation.SumSellPrice,
 			"net_arb":        violation.NetArb,
+			"execution_style": string(estimate.Style),
+			"fee_rate":        estimate.FeeRate,
 		},
 		Threshold:    0.02,
 		CurrentValue: violation.NetArb,
 		Suggestion:   "Systematic arbitrage: execute if liquidity sufficient",
 		Action:       "buy", // or "sell" depending on arb type
-		CanExecute:   violation.Liquidity >= 10,
-		EstimatedEdge: violation.NetArb * 100, // cents
-		EstimatedSlippage: violation.EstimatedSlippage * 100,
-		RecommendedSize: int(violation.Liquidity),
+		CanExecute:   estimate.Fillable && estimate.EdgeCents > 0,
+		EstimatedEdge: estimate.EdgeCents, // cents per contract, net of fees and slippage
+		EstimatedSlippage: estimate.SlippageCents,
+		RecommendedSize: recommendedSize,
 	}
 	
 	confidence, hitRate, sampleSize := e.backtest.GetAlertStats(violation.EventTicker, AlertTypeNoArbViolation)
 	alert.Confidence = confidence
 	alert.HitRate = hitRate
 	alert.SampleSize = sampleSize
+	alert.ExpectedValueScore = e.backtest.GetExpectedValueScore(violation.EventTicker, AlertTypeNoArbViolation)
 	
 	return alert
 }
 
+// generateAlertID stamps IDs in UTC so they sort and compare the same across
+// deployments in different zones
 func generateAlertID(marketTicker string, alertType AlertType) string {
-	return marketTicker + "_" + string(alertType) + "_" + time.Now().Format("20060102150405")
+	return marketTicker + "_" + string(alertType) + "_" + time.Now().UTC().Format("20060102150405")
 }
 
 func absFloat(x float64) float64 {
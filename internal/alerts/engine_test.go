@@ -0,0 +1,326 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/scanner"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// addBook registers an active market with the given levels, fetched just now
+func addBook(engine *state.Engine, ticker, event string, bids, asks []state.PriceLevel) {
+	engine.RegisterMarket(&state.Market{Ticker: ticker, Title: ticker, Status: state.StatusActive, EventTicker: event})
+	ob := state.NewOrderbook(ticker)
+	ob.Bids = bids
+	ob.Asks = asks
+	ob.LastUpdate = time.Now()
+	engine.UpdateOrderbook(ticker, ob)
+}
+
+func TestNoArbAlertExecutableAtSmallerSize(t *testing.T) {
+	stateEngine := state.NewEngine()
+	// Asks sum to 85¢ at the top, but the second levels are 15¢ worse
+	addBook(stateEngine, "EV-A", "EV",
+		[]state.PriceLevel{{Price: 38, Quantity: 50}},
+		[]state.PriceLevel{{Price: 40, Quantity: 50}, {Price: 55, Quantity: 200}})
+	addBook(stateEngine, "EV-B", "EV",
+		[]state.PriceLevel{{Price: 43, Quantity: 50}},
+		[]state.PriceLevel{{Price: 45, Quantity: 50}, {Price: 60, Quantity: 200}})
+
+	e := NewEngine(stateEngine, config.ScannerConfig{FeeRate: 0.01})
+	violations := e.noArbEngine.CheckNoArbViolations()
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	violation := violations[0]
+
+	// 150 contracts walk both legs 10¢ deep on average, eating the edge
+	violation.Liquidity = 150
+	large := e.createNoArbAlert(violation)
+	if large.CanExecute || large.EstimatedEdge > 0 {
+		t.Fatalf("150 contracts: can_execute=%v edge=%.2f¢, want not executable", large.CanExecute, large.EstimatedEdge)
+	}
+
+	// 50 contracts fill at the top of both books
+	violation.Liquidity = 50
+	small := e.createNoArbAlert(violation)
+	if !small.CanExecute || small.EstimatedEdge <= 0 {
+		t.Fatalf("50 contracts: can_execute=%v edge=%.2f¢, want executable", small.CanExecute, small.EstimatedEdge)
+	}
+	if small.RecommendedSize != 50 {
+		t.Errorf("recommended size = %d, want 50", small.RecommendedSize)
+	}
+}
+
+func TestPersistentNoArbAlertsOnce(t *testing.T) {
+	stateEngine := state.NewEngine()
+	// Asks sum to 90¢: a 10¢ buy arb that persists unchanged
+	addBook(stateEngine, "EV-A", "EV",
+		[]state.PriceLevel{{Price: 38, Quantity: 100}},
+		[]state.PriceLevel{{Price: 40, Quantity: 100}})
+	addBook(stateEngine, "EV-B", "EV",
+		[]state.PriceLevel{{Price: 48, Quantity: 100}},
+		[]state.PriceLevel{{Price: 50, Quantity: 100}})
+
+	e := NewEngine(stateEngine, config.ScannerConfig{NoArbCooldownSecs: 300, NoArbEdgeChangeCents: 1})
+
+	noArbAlerts := 0
+	for cycle := 0; cycle < 5; cycle++ {
+		for _, alert := range e.CheckAlerts() {
+			if alert.Type == AlertTypeNoArbViolation {
+				noArbAlerts++
+			}
+		}
+	}
+	if noArbAlerts != 1 {
+		t.Fatalf("got %d no-arb alerts over 5 cycles, want 1", noArbAlerts)
+	}
+
+	// A material change in the edge re-alerts within the cooldown
+	addBook(stateEngine, "EV-A", "EV",
+		[]state.PriceLevel{{Price: 33, Quantity: 100}},
+		[]state.PriceLevel{{Price: 35, Quantity: 100}})
+	for _, alert := range e.CheckAlerts() {
+		if alert.Type == AlertTypeNoArbViolation {
+			noArbAlerts++
+		}
+	}
+	if noArbAlerts != 2 {
+		t.Fatalf("edge change: got %d no-arb alerts, want 2", noArbAlerts)
+	}
+}
+
+func TestAlertHistoryBounded(t *testing.T) {
+	stateEngine := state.NewEngine()
+	stateEngine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	stateEngine.RegisterMarket(&state.Market{Ticker: "DONE", Status: state.StatusActive})
+	e := NewEngine(stateEngine, config.ScannerConfig{})
+
+	now := time.Now()
+	for i := 0; i < 10*maxAlertHistoryPerMarket; i++ {
+		e.recordHistory(Alert{MarketTicker: "MKT", Timestamp: now, Reason: fmt.Sprint(i)})
+	}
+	e.recordHistory(Alert{MarketTicker: "DONE", Timestamp: now})
+	e.recordHistory(Alert{MarketTicker: "OLD", Timestamp: now.Add(-2 * alertHistoryRetention)})
+	e.pruneHistory(now)
+
+	history := e.alertHistory["MKT"]
+	if len(history) != maxAlertHistoryPerMarket {
+		t.Fatalf("history holds %d alerts, want %d", len(history), maxAlertHistoryPerMarket)
+	}
+	if last := history[len(history)-1].Reason; last != fmt.Sprint(10*maxAlertHistoryPerMarket-1) {
+		t.Errorf("newest retained alert = %s, want the last one recorded", last)
+	}
+
+	// Closed markets and alerts past retention are dropped entirely
+	stateEngine.RegisterMarket(&state.Market{Ticker: "DONE", Status: state.StatusClosed})
+	e.pruneHistory(now)
+	if _, ok := e.alertHistory["DONE"]; ok {
+		t.Error("history kept for a closed market")
+	}
+	if _, ok := e.alertHistory["OLD"]; ok {
+		t.Error("history kept past the retention window")
+	}
+}
+
+func TestAlertsLinkToDashboard(t *testing.T) {
+	stateEngine := state.NewEngine()
+	// Deep books raise per-market alerts; asks summing to 90¢ raise a no-arb alert
+	addBook(stateEngine, "EV-A", "EV",
+		[]state.PriceLevel{{Price: 38, Quantity: 600}},
+		[]state.PriceLevel{{Price: 40, Quantity: 600}})
+	addBook(stateEngine, "EV-B", "EV",
+		[]state.PriceLevel{{Price: 48, Quantity: 600}},
+		[]state.PriceLevel{{Price: 50, Quantity: 600}})
+
+	for _, base := range []string{"https://dash.example.com", ""} {
+		e := NewEngine(stateEngine, config.ScannerConfig{})
+		e.SetDashboardBaseURL(base)
+
+		var marketAlerts, noArbAlerts int
+		for _, alert := range e.CheckAlerts() {
+			if alert.Type == AlertTypeNoArbViolation {
+				noArbAlerts++
+				if alert.URL != "" {
+					t.Errorf("no-arb alert for event %s links to %s", alert.MarketTicker, alert.URL)
+				}
+				continue
+			}
+			marketAlerts++
+			want := ""
+			if base != "" {
+				want = base + "/markets/" + alert.MarketTicker
+			}
+			if alert.URL != want {
+				t.Errorf("base %q: %s alert url = %q, want %q", base, alert.Type, alert.URL, want)
+			}
+		}
+		if marketAlerts == 0 || noArbAlerts == 0 {
+			t.Fatalf("base %q: got %d market and %d no-arb alerts, want both", base, marketAlerts, noArbAlerts)
+		}
+	}
+}
+
+func TestImbalancePressureNeedsBothConditions(t *testing.T) {
+	cfg := config.ScannerConfig{ImbalancePressureThreshold: 0.5, ImbalancePriceLagCents: 2}
+
+	tests := []struct {
+		name           string
+		imbalance      float64
+		micropriceDiff float64
+		want           bool
+	}{
+		{"lopsided and lagging", 0.7, 3, true},
+		{"lopsided sell side and lagging", -0.7, -3, true},
+		{"lopsided, price caught up", 0.7, 1, false},
+		{"balanced, price lagging", 0.3, 3, false},
+		{"neither", 0.3, 1, false},
+	}
+	for _, tt := range tests {
+		e := NewEngine(state.NewEngine(), cfg)
+		opp := scanner.MarketOpportunity{MarketTicker: "MKT", Imbalance: tt.imbalance, MicropriceDiff: tt.micropriceDiff}
+
+		fired := false
+		for _, alert := range e.checkMarketAlerts(opp) {
+			if alert.Type == AlertTypeImbalancePressure {
+				fired = true
+			}
+		}
+		if fired != tt.want {
+			t.Errorf("%s: imbalance alert fired = %v, want %v", tt.name, fired, tt.want)
+		}
+	}
+
+	// Each threshold moves independently of the other
+	opp := scanner.MarketOpportunity{Imbalance: 0.7, MicropriceDiff: 3}
+	if NewEngine(state.NewEngine(), config.ScannerConfig{ImbalancePressureThreshold: 0.8, ImbalancePriceLagCents: 2}).imbalancePressure(opp) {
+		t.Error("fired with imbalance below a raised imbalance threshold")
+	}
+	if NewEngine(state.NewEngine(), config.ScannerConfig{ImbalancePressureThreshold: 0.5, ImbalancePriceLagCents: 4}).imbalancePressure(opp) {
+		t.Error("fired with microprice lag below a raised lag threshold")
+	}
+}
+
+func TestAlertReferencesRecentSignals(t *testing.T) {
+	stateEngine := state.NewEngine()
+	// A deep book raises a depth alert for MKT
+	addBook(stateEngine, "MKT", "EV",
+		[]state.PriceLevel{{Price: 45, Quantity: 600}},
+		[]state.PriceLevel{{Price: 47, Quantity: 600}})
+	// Recorded the way the signal processor records triggered signals
+	ts := stateEngine.GetTimeSeries()
+	ts.RecordSignal("MKT", "volume_surge", 4.2, map[string]interface{}{"id": "MKT_volume_surge_1", "confidence": 0.8, "severity": "high"})
+	ts.RecordSignal("OTHER", "volume_surge", 3.0, map[string]interface{}{"id": "OTHER_volume_surge_1"})
+
+	e := NewEngine(stateEngine, config.ScannerConfig{AlertSignalLookbackSecs: 60})
+	var depth *Alert
+	for _, alert := range e.CheckAlerts() {
+		if alert.Type == AlertTypeDepthIncreased {
+			depth = &alert
+			break
+		}
+	}
+	if depth == nil {
+		t.Fatal("no depth alert for MKT")
+	}
+
+	ids, _ := depth.Inputs["signal_ids"].([]string)
+	if fmt.Sprint(ids) != "[MKT_volume_surge_1]" {
+		t.Errorf("signal_ids = %v, want [MKT_volume_surge_1]", depth.Inputs["signal_ids"])
+	}
+	recent, _ := depth.Inputs["recent_signals"].([]map[string]interface{})
+	if len(recent) != 1 || recent[0]["id"] != "MKT_volume_surge_1" || recent[0]["type"] != "volume_surge" {
+		t.Errorf("recent_signals = %v, want the MKT volume surge", depth.Inputs["recent_signals"])
+	}
+
+	// With the lookback disabled nothing is attached
+	e = NewEngine(stateEngine, config.ScannerConfig{})
+	for _, alert := range e.CheckAlerts() {
+		if _, ok := alert.Inputs["signal_ids"]; ok {
+			t.Errorf("%s alert has signal_ids with the lookback disabled", alert.Type)
+		}
+	}
+}
+
+func TestStaleBookProducesNoExecutionReadyAlert(t *testing.T) {
+	// 60s polls with quiet markets on every second cycle: books up to 150s
+	// old are just waiting for their next poll
+	cfg := config.ScannerConfig{MaxBookAgeSecs: 150, BookPollIntervalSecs: 60}
+
+	tests := []struct {
+		name  string
+		age   time.Duration
+		stale bool
+	}{
+		{"just polled", time.Second, false},
+		{"between quiet polls", 100 * time.Second, false},
+		{"missed polls", 10 * time.Minute, true},
+	}
+	for _, tt := range tests {
+		stateEngine := state.NewEngine()
+		stateEngine.RegisterMarket(&state.Market{Ticker: "MKT", Title: "MKT", Status: state.StatusActive})
+		ob := state.NewOrderbook("MKT")
+		ob.Bids = []state.PriceLevel{{Price: 49, Quantity: 500}, {Price: 48, Quantity: 500}}
+		ob.Asks = []state.PriceLevel{{Price: 50, Quantity: 500}, {Price: 51, Quantity: 500}}
+		ob.LastUpdate = time.Now().Add(-tt.age)
+		stateEngine.UpdateOrderbook("MKT", ob)
+
+		e := NewEngine(stateEngine, cfg)
+		opps := e.scanner.ScanMarkets()
+		if len(opps) != 1 {
+			t.Fatalf("%s: %d opportunities, want 1", tt.name, len(opps))
+		}
+		if opps[0].BookStale != tt.stale {
+			t.Errorf("%s: book_stale = %v, want %v", tt.name, opps[0].BookStale, tt.stale)
+		}
+
+		executionReady := false
+		for _, alert := range e.checkMarketAlerts(opps[0]) {
+			if alert.Type == AlertTypeExecutionReady {
+				executionReady = true
+			}
+			if tt.stale && alert.CanExecute {
+				t.Errorf("%s: %s alert on a stale book is executable", tt.name, alert.Type)
+			}
+		}
+		if executionReady == tt.stale {
+			t.Errorf("%s: execution-ready alert fired = %v, want %v", tt.name, executionReady, !tt.stale)
+		}
+	}
+}
+
+func TestAlertIDsAndTimestampsUTCUnderNonUTCZone(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("JST", 9*60*60)
+	defer func() { time.Local = saved }()
+
+	before := time.Now().UTC().Truncate(time.Second)
+	id := generateAlertID("MKT", AlertTypeExecutionReady)
+	stamp := id[strings.LastIndex(id, "_")+1:]
+	parsed, err := time.Parse("20060102150405", stamp)
+	if err != nil {
+		t.Fatalf("alert ID %q has no timestamp: %v", id, err)
+	}
+	if parsed.Before(before) || parsed.Sub(before) > time.Minute {
+		t.Errorf("alert ID stamp %s is not UTC now (%s)", parsed, before)
+	}
+
+	stateEngine := state.NewEngine()
+	addBook(stateEngine, "EV-A", "EV", []state.PriceLevel{{Price: 38, Quantity: 50}}, []state.PriceLevel{{Price: 40, Quantity: 50}})
+	addBook(stateEngine, "EV-B", "EV", []state.PriceLevel{{Price: 43, Quantity: 50}}, []state.PriceLevel{{Price: 45, Quantity: 50}})
+	e := NewEngine(stateEngine, config.ScannerConfig{FeeRate: 0.01})
+	violations := e.noArbEngine.CheckNoArbViolations()
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	if zone := violations[0].Timestamp.Location(); zone != time.UTC {
+		t.Errorf("violation timestamp zone = %s, want UTC", zone)
+	}
+	if zone := e.createNoArbAlert(violations[0]).Timestamp.Location(); zone != time.UTC {
+		t.Errorf("alert timestamp zone = %s, want UTC", zone)
+	}
+}
@@ -0,0 +1,127 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// expiryThresholds converts the configured minutes into durations, largest
+// first, ignoring non-positive entries
+func expiryThresholds(minutes []int) []time.Duration {
+	thresholds := make([]time.Duration, 0, len(minutes))
+	for _, m := range minutes {
+		if m > 0 {
+			thresholds = append(thresholds, time.Duration(m)*time.Minute)
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool {
+		return thresholds[i] > thresholds[j]
+	})
+	return thresholds
+}
+
+// expiryTradeLookback is how far back the last trade reported with an expiry
+// alert may be; markets near settlement often trade rarely
+const expiryTradeLookback = time.Hour
+
+// checkExpiringSoon alerts when a market's time to expiration drops below one
+// of the configured thresholds, once per threshold per market. A market first
+// seen already inside several thresholds alerts once, for the smallest. If its
+// expiration is pushed back out past a reported threshold, the smaller ones are
+// re-armed. It takes the market rather than a scanner opportunity: markets
+// pinned near 0 or 100 at expiry often quote one side only, and the scanner
+// skips those.
+func (e *Engine) checkExpiringSoon(market *state.Market, now time.Time) (Alert, bool) {
+	if market.ExpirationTime == nil || len(e.expiryThresholds) == 0 {
+		return Alert{}, false
+	}
+	remaining := market.ExpirationTime.Sub(now)
+	if remaining <= 0 {
+		return Alert{}, false
+	}
+
+	// Thresholds run largest first, so the last one crossed is the smallest
+	var crossed time.Duration
+	for _, threshold := range e.expiryThresholds {
+		if remaining < threshold {
+			crossed = threshold
+		}
+	}
+
+	reported, wasReported := e.reportedExpiries[market.Ticker]
+	if crossed == 0 {
+		delete(e.reportedExpiries, market.Ticker)
+		return Alert{}, false
+	}
+	if wasReported && crossed >= reported {
+		e.reportedExpiries[market.Ticker] = crossed
+		return Alert{}, false
+	}
+	e.reportedExpiries[market.Ticker] = crossed
+
+	inputs := map[string]interface{}{
+		"expiration_time":   market.ExpirationTime.UTC(),
+		"minutes_remaining": remaining.Minutes(),
+		"threshold_minutes": crossed.Minutes(),
+	}
+
+	// Report whatever prices exist: the mid needs both sides, so a one-sided
+	// book reports its best bid or ask instead
+	price := ""
+	if ob, exists := e.state.GetOrderbook(market.Ticker); exists {
+		if len(ob.Bids) > 0 {
+			inputs["best_bid"] = ob.Bids[0].Price
+			price = fmt.Sprintf("%d¢ bid", ob.Bids[0].Price)
+		}
+		if len(ob.Asks) > 0 {
+			inputs["best_ask"] = ob.Asks[0].Price
+			price = fmt.Sprintf("%d¢ ask", ob.Asks[0].Price)
+		}
+		if len(ob.Bids) > 0 && len(ob.Asks) > 0 {
+			midCents := float64(ob.Bids[0].Price+ob.Asks[0].Price) / 2.0
+			inputs["mid_price"] = midCents
+			price = fmt.Sprintf("%.0f¢ mid", midCents)
+		}
+	}
+	if trades := e.state.GetTradesSince(market.Ticker, now.Add(-expiryTradeLookback)); len(trades) > 0 {
+		lastPrice := trades[len(trades)-1].Price
+		inputs["last_trade_price"] = lastPrice
+		if price == "" {
+			price = fmt.Sprintf("%d¢ last trade", lastPrice)
+		}
+	}
+
+	reason := fmt.Sprintf("Expires in %s (under %s)", remaining.Round(time.Minute), crossed)
+	if price != "" {
+		reason += " at " + price
+	}
+
+	alert := Alert{
+		ID:           generateAlertID(market.Ticker, AlertTypeExpiringSoon),
+		Type:         AlertTypeExpiringSoon,
+		MarketTicker: market.Ticker,
+		Title:        market.Title,
+		Timestamp:    now,
+		Reason:       reason,
+		Inputs:       inputs,
+		Threshold:    crossed.Minutes(),
+		CurrentValue: remaining.Minutes(),
+		Suggestion:   "Settlement is approaching: review open positions",
+		Action:       "watch",
+		TimeToExpiry: remaining.Hours(),
+	}
+	return alert, true
+}
+
+// hoursToExpiry is the market's time to expiration in hours as of now, or 0
+// when unknown, past, or the ticker isn't a market (no-arb alerts use events)
+func (e *Engine) hoursToExpiry(ticker string, now time.Time) float64 {
+	market, exists := e.state.GetMarket(ticker)
+	if !exists || market.ExpirationTime == nil || !market.ExpirationTime.After(now) {
+		return 0
+	}
+	return market.ExpirationTime.Sub(now).Hours()
+}
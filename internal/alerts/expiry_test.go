@@ -0,0 +1,91 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// addExpiringMarket registers an active market expiring at expiry whose book
+// only has a bid, as is typical for a market pinned near 100 before settlement
+func addExpiringMarket(engine *state.Engine, ticker string, expiry time.Time) {
+	addBook(engine, ticker, "EV", []state.PriceLevel{{Price: 97, Quantity: 500}}, nil)
+	market, _ := engine.GetMarket(ticker)
+	market.ExpirationTime = &expiry
+	engine.RegisterMarket(market)
+}
+
+func TestExpiringSoonFiresOncePerThreshold(t *testing.T) {
+	stateEngine := state.NewEngine()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	addExpiringMarket(stateEngine, "MKT", base.Add(90*time.Minute))
+	e := NewEngine(stateEngine, config.ScannerConfig{ExpiryAlertMinutes: []int{60, 15}})
+	market, _ := stateEngine.GetMarket("MKT")
+
+	steps := []struct {
+		elapsed   time.Duration
+		threshold float64 // minutes of the expected alert, 0 for none
+	}{
+		{0, 0},
+		{31 * time.Minute, 60},
+		{40 * time.Minute, 0},
+		{74 * time.Minute, 0},
+		{76 * time.Minute, 15},
+		{85 * time.Minute, 0},
+		{91 * time.Minute, 0}, // expired
+	}
+	for _, step := range steps {
+		alert, ok := e.checkExpiringSoon(market, base.Add(step.elapsed))
+		got := 0.0
+		if ok {
+			got = alert.Threshold
+		}
+		if got != step.threshold {
+			t.Errorf("after %s: alerted for %v min, want %v", step.elapsed, got, step.threshold)
+			continue
+		}
+		if ok && (alert.Type != AlertTypeExpiringSoon || alert.Inputs["best_bid"] != 97) {
+			t.Errorf("after %s: got %+v, want expiring_soon with the 97¢ bid", step.elapsed, alert)
+		}
+	}
+}
+
+func TestExpiringSoonCoversOneSidedBooks(t *testing.T) {
+	// Alerts are stamped in UTC whatever the host's zone
+	saved := time.Local
+	time.Local = time.FixedZone("JST", 9*60*60)
+	defer func() { time.Local = saved }()
+
+	stateEngine := state.NewEngine()
+	addExpiringMarket(stateEngine, "MKT", time.Now().Add(30*time.Minute))
+	e := NewEngine(stateEngine, config.ScannerConfig{ExpiryAlertMinutes: []int{60, 15}})
+
+	// The scanner skips a book without an ask, but expiry still applies
+	if opportunities := e.scanner.ScanMarkets(); len(opportunities) != 0 {
+		t.Fatalf("scanner returned %d opportunities for a one-sided book, want 0", len(opportunities))
+	}
+
+	count := func(alerts []Alert) int {
+		n := 0
+		for _, alert := range alerts {
+			if alert.Type == AlertTypeExpiringSoon && alert.MarketTicker == "MKT" {
+				n++
+			}
+		}
+		return n
+	}
+	first := e.CheckAlerts()
+	if n := count(first); n != 1 {
+		t.Fatalf("first check: %d expiring_soon alerts, want 1", n)
+	}
+	for _, alert := range first {
+		if alert.Type == AlertTypeExpiringSoon && alert.Timestamp.Location() != time.UTC {
+			t.Errorf("expiry alert timestamp zone = %s, want UTC", alert.Timestamp.Location())
+		}
+	}
+	if n := count(e.CheckAlerts()); n != 0 {
+		t.Errorf("second check: %d expiring_soon alerts, want 0", n)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requireAPIKey rejects /api requests that don't carry the configured key,
+// either as "Authorization: Bearer <key>" or in X-API-Key. Browsers can't set
+// headers on EventSource or WebSocket connections, so the stream endpoints
+// (including watchlist streams) also accept ?api_key=. /health and the
+// dashboard's static files are open. With no key configured every request
+// passes.
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	if s.config.APIKey == "" {
+		return next
+	}
+	key := []byte(s.config.APIKey)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if !strings.HasPrefix(path, "/api/") || path == "/api/v1/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		provided := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			provided = bearer
+		}
+		if provided == "" && isStreamPath(path) {
+			provided = r.URL.Query().Get("api_key")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(provided), key) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeError(w, http.StatusUnauthorized, errCodeUnauthorized, "Missing or invalid API key")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isStreamPath reports whether path is a long-lived SSE or WebSocket endpoint
+func isStreamPath(path string) bool {
+	if strings.HasPrefix(path, "/api/v1/stream/") || strings.HasPrefix(path, "/api/v1/ws/") {
+		return true
+	}
+	return strings.HasPrefix(path, "/api/v1/watchlists/") && strings.HasSuffix(path, "/stream")
+}
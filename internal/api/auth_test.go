@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+func TestAPIKeyRequired(t *testing.T) {
+	_, ts := newTestServer(t, config.APIConfig{APIKey: "s3cret"})
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		want    int
+	}{
+		{"no key", "/api/v1/markets", nil, http.StatusUnauthorized},
+		{"wrong key", "/api/v1/markets", map[string]string{"X-API-Key": "nope"}, http.StatusUnauthorized},
+		{"X-API-Key", "/api/v1/markets", map[string]string{"X-API-Key": "s3cret"}, http.StatusOK},
+		{"bearer", "/api/v1/markets", map[string]string{"Authorization": "Bearer s3cret"}, http.StatusOK},
+		{"wrong bearer", "/api/v1/markets", map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"query key off a stream", "/api/v1/markets?api_key=s3cret", nil, http.StatusUnauthorized},
+		{"health is exempt", "/api/v1/health", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", ts.URL+tt.path, nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			var body errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error.Code != errCodeUnauthorized {
+				t.Errorf("%s: body = %+v (%v), want code %q", tt.name, body, err, errCodeUnauthorized)
+			}
+			if resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: 401 without WWW-Authenticate", tt.name)
+			}
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestAPIKeyAcceptedInStreamQuery(t *testing.T) {
+	_, ts := newTestServer(t, config.APIConfig{APIKey: "s3cret"})
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws/signals"
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("stream without a key: err %v, resp %v; want 401", err, resp)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?api_key=s3cret", nil)
+	if err != nil {
+		t.Fatalf("stream with ?api_key: %v", err)
+	}
+	conn.Close()
+}
+
+func TestNoAPIKeyLeavesAPIOpen(t *testing.T) {
+	_, ts := newTestServer(t, config.APIConfig{})
+	if status := getJSON(t, ts.URL+"/api/v1/markets", nil); status != http.StatusOK {
+		t.Errorf("status = %d, want 200 with no key configured", status)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// postBacktestRequest posts body to the backtest endpoint and decodes the reply
+func postBacktestRequest(t *testing.T, url, body string, out interface{}) int {
+	t.Helper()
+	resp, err := http.Post(url+"/api/v1/backtest", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestBacktestEndpointAgainstSeededHistory(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT") // mid 46¢
+
+	// A buy alert and a sell alert, then the mid rises 4¢ over the next second
+	alertTime := time.Now()
+	s.alerts = append(s.alerts,
+		alerts.Alert{MarketTicker: "MKT", Type: alerts.AlertTypeImbalancePressure, Action: "buy", Timestamp: alertTime},
+		alerts.Alert{MarketTicker: "MKT", Type: alerts.AlertTypeImbalancePressure, Action: "sell", Timestamp: alertTime},
+		alerts.Alert{MarketTicker: "MKT", Type: alerts.AlertTypeExecutionReady, Timestamp: alertTime})
+	time.Sleep(1100 * time.Millisecond)
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 49, Quantity: 200}}
+	ob.Asks = []state.PriceLevel{{Price: 51, Quantity: 150}}
+	s.state.UpdateOrderbook("MKT", ob)
+
+	var body struct {
+		MarketTicker string  `json:"market_ticker"`
+		AlertType    string  `json:"alert_type"`
+		LookbackSecs int     `json:"lookback_secs"`
+		Alerts       int     `json:"alerts"`
+		HitRate      float64 `json:"hit_rate"`
+		SampleSize   int     `json:"sample_size"`
+		AvgMove      float64 `json:"avg_move"`
+		Confidence   float64 `json:"confidence"`
+	}
+	status := postBacktestRequest(t, ts.URL, `{"market_ticker":"MKT","alert_type":"imbalance_pressure","lookback":1}`, &body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if body.Alerts != 2 || body.SampleSize != 2 || body.LookbackSecs != 1 {
+		t.Errorf("alerts/samples/lookback = %d/%d/%d, want 2/2/1", body.Alerts, body.SampleSize, body.LookbackSecs)
+	}
+	// Moves are in cents: the buy is borne out by the 4¢ rise, the sell isn't
+	if math.Abs(body.AvgMove-4) > 1e-9 || body.HitRate != 0.5 {
+		t.Errorf("avg move %.4f¢, hit rate %.2f; want 4¢ and 0.5", body.AvgMove, body.HitRate)
+	}
+	if body.Confidence != 0.25 {
+		t.Errorf("confidence = %.2f, want 0.25 (half the hit rate under 10 samples)", body.Confidence)
+	}
+}
+
+func TestBacktestEndpointInsufficientHistory(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+	// Its lookback window hasn't elapsed, so there's no mid after it yet
+	s.alerts = append(s.alerts, alerts.Alert{MarketTicker: "MKT", Type: alerts.AlertTypeImbalancePressure, Action: "buy", Timestamp: time.Now()})
+
+	tests := []struct {
+		name string
+		body string
+		want int
+		code string
+	}{
+		{"no alerts of the type", `{"market_ticker":"MKT","alert_type":"spread_tightened"}`, http.StatusUnprocessableEntity, errCodeInsufficientHistory},
+		{"window not covered", `{"market_ticker":"MKT","alert_type":"imbalance_pressure","lookback":60}`, http.StatusUnprocessableEntity, errCodeInsufficientHistory},
+		{"missing fields", `{"market_ticker":"MKT"}`, http.StatusBadRequest, errCodeBadRequest},
+		{"negative lookback", `{"market_ticker":"MKT","alert_type":"imbalance_pressure","lookback":-5}`, http.StatusBadRequest, errCodeBadRequest},
+	}
+	for _, tt := range tests {
+		var body errorResponse
+		if status := postBacktestRequest(t, ts.URL, tt.body, &body); status != tt.want || body.Error.Code != tt.code {
+			t.Errorf("%s: status %d code %q, want %d %q", tt.name, status, body.Error.Code, tt.want, tt.code)
+		}
+	}
+}
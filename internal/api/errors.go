@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error codes used in the JSON error envelope
+const (
+	errCodeBadRequest = "bad_request"
+	errCodeNotFound   = "not_found"
+	errCodeInternal   = "internal_error"
+
+	errCodeMethodNotAllowed = "method_not_allowed"
+	errCodeUnavailable      = "unavailable"
+	errCodeUnauthorized     = "unauthorized"
+
+	errCodeInsufficientHistory = "insufficient_history"
+)
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError responds with {"error": {"code": ..., "message": ...}} so clients
+// can parse every response as JSON
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error errorBody `json:"error"`
+	}{
+		Error: errorBody{Code: code, Message: message},
+	})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+func TestErrorResponsesUseJSONEnvelope(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/api/v1/markets/MISSING", http.StatusNotFound, errCodeNotFound},
+		{"/api/v1/markets/MISSING/orderbook", http.StatusNotFound, errCodeNotFound},
+		{"/api/v1/no-such-endpoint", http.StatusNotFound, errCodeNotFound},
+		{"/api/v1/markets?include_book=maybe", http.StatusBadRequest, errCodeBadRequest},
+		{"/api/v1/markets/MKT/orderbook?levels=-1", http.StatusBadRequest, errCodeBadRequest},
+		{"/api/v1/signals?sort=alphabetical", http.StatusBadRequest, errCodeBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body errorResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, resp.StatusCode, tt.status)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", tt.path, ct)
+		}
+		if decodeErr != nil {
+			t.Errorf("%s: body is not JSON: %v", tt.path, decodeErr)
+			continue
+		}
+		if body.Error.Code != tt.code || body.Error.Message == "" {
+			t.Errorf("%s: error = %+v, want code %q with a message", tt.path, body.Error, tt.code)
+		}
+	}
+}
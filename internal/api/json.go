@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+)
+
+// writeJSON encodes v as the response body. Encoding happens before anything
+// is written, so a failure produces an error envelope rather than a truncated
+// 200 response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := marshalJSON(v)
+	if err != nil {
+		fmt.Printf("[%s] Failed to encode response for %T: %v\n", requestIDFromWriter(w), v, err)
+		writeError(w, http.StatusInternalServerError, errCodeInternal, "Failed to encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// marshalJSON is json.Marshal, except that NaN and ±Inf floats (which
+// encoding/json rejects) are encoded as 0. The sanitizing copy is only made
+// when the plain encode fails, so the common path costs nothing extra.
+func marshalJSON(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	var unsupported *json.UnsupportedValueError
+	if !errors.As(err, &unsupported) {
+		return body, err
+	}
+	return json.Marshal(sanitizeFloats(reflect.ValueOf(v)).Interface())
+}
+
+// sanitizeFloats returns a deep copy of v with every non-finite float replaced
+// by 0. Shared state is never modified. Unexported fields are copied as-is
+// since they aren't encoded.
+func sanitizeFloats(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return reflect.Zero(v.Type())
+		}
+		return v
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(sanitizeFloats(v.Elem()))
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(sanitizeFloats(v.Elem()))
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(sanitizeFloats(v.Field(i)))
+			}
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(sanitizeFloats(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(sanitizeFloats(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), sanitizeFloats(iter.Value()))
+		}
+		return copied
+
+	default:
+		return v
+	}
+}
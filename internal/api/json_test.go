@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type nanPayload struct {
+	Score   float64            `json:"score"`
+	Ratio   *float64           `json:"ratio"`
+	Series  []float64          `json:"series"`
+	Metrics map[string]float64 `json:"metrics"`
+	Extra   interface{}        `json:"extra"`
+	Label   string             `json:"label"`
+}
+
+func TestMarshalJSONReplacesNonFiniteFloats(t *testing.T) {
+	ratio := math.Inf(1)
+	payload := nanPayload{
+		Score:   math.NaN(),
+		Ratio:   &ratio,
+		Series:  []float64{1.5, math.Inf(-1)},
+		Metrics: map[string]float64{"sharpe": math.NaN(), "mid": 0.42},
+		Extra:   map[string]interface{}{"z": math.NaN()},
+		Label:   "MKT",
+	}
+
+	if _, err := json.Marshal(payload); err == nil {
+		t.Fatal("plain json.Marshal accepted NaN; the test no longer exercises the fallback")
+	}
+
+	body, err := marshalJSON(payload)
+	if err != nil {
+		t.Fatalf("marshalJSON: %v", err)
+	}
+
+	var got nanPayload
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, body)
+	}
+	if got.Score != 0 || got.Ratio == nil || *got.Ratio != 0 {
+		t.Errorf("score/ratio = %v/%v, want 0/0", got.Score, got.Ratio)
+	}
+	if len(got.Series) != 2 || got.Series[0] != 1.5 || got.Series[1] != 0 {
+		t.Errorf("series = %v, want [1.5 0]", got.Series)
+	}
+	if got.Metrics["sharpe"] != 0 || got.Metrics["mid"] != 0.42 {
+		t.Errorf("metrics = %v, want sharpe 0 and mid 0.42", got.Metrics)
+	}
+	if extra, _ := got.Extra.(map[string]interface{}); extra["z"] != 0.0 {
+		t.Errorf("extra = %v, want z 0", got.Extra)
+	}
+	if got.Label != "MKT" {
+		t.Errorf("label = %q, want MKT", got.Label)
+	}
+
+	// The caller's value is left untouched
+	if !math.IsNaN(payload.Score) || !math.IsInf(ratio, 1) || !math.IsNaN(payload.Metrics["sharpe"]) {
+		t.Error("marshalJSON modified its input")
+	}
+}
+
+func TestWriteJSONWithNaNReturnsCompleteBody(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeJSON(rec, map[string]float64{"volatility": math.NaN()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got map[string]float64
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, rec.Body.String())
+	}
+	if v, ok := got["volatility"]; !ok || v != 0 {
+		t.Errorf("volatility = %v (present %v), want 0", v, ok)
+	}
+}
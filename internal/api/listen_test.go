@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestListenFailsOnPortInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	addr := taken.Addr().String()
+
+	s := NewServer(config.APIConfig{BindAddress: addr}, config.ScannerConfig{}, state.NewEngine(), make(chan signals.Signal))
+	err = s.Listen()
+	if err == nil {
+		s.listener.Close()
+		t.Fatalf("bound %s while another listener holds it", addr)
+	}
+	if !strings.Contains(err.Error(), addr) {
+		t.Errorf("error %q doesn't name the address", err)
+	}
+	if s.listener != nil {
+		t.Error("listener set after a failed bind")
+	}
+
+	// A free port binds synchronously, before Run
+	free := NewServer(config.APIConfig{BindAddress: "127.0.0.1:0"}, config.ScannerConfig{}, state.NewEngine(), make(chan signals.Signal))
+	if err := free.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer free.listener.Close()
+	if _, err := net.Dial("tcp", free.listener.Addr().String()); err != nil {
+		t.Errorf("nothing accepting on the bound address: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// bufferState is what the server saves on shutdown so the dashboard shows the
+// same recent signals and alerts after a restart
+type bufferState struct {
+	SavedAt      time.Time            `json:"saved_at"`
+	Signals      []signals.Signal     `json:"signals"`
+	Alerts       []alerts.Alert       `json:"alerts"`
+	ArbCooldowns []alerts.ArbCooldown `json:"arb_cooldowns"`
+}
+
+// loadBuffers restores buffers saved by a previous run. A missing file is not
+// an error. Call before the collectors start.
+func (s *Server) loadBuffers() error {
+	if s.config.BufferStatePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.config.BufferStatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read buffer state: %w", err)
+	}
+
+	var saved bufferState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse buffer state: %w", err)
+	}
+
+	// Files written before timestamps were kept in UTC carry the host's offset
+	for i := range saved.Signals {
+		saved.Signals[i].Timestamp = saved.Signals[i].Timestamp.UTC()
+	}
+	for i := range saved.Alerts {
+		saved.Alerts[i].Timestamp = saved.Alerts[i].Timestamp.UTC()
+	}
+
+	s.mu.Lock()
+	s.signals = append(s.signals, newestN(saved.Signals, s.config.PersistedBufferSize)...)
+	s.alerts = append(s.alerts, newestN(saved.Alerts, s.config.PersistedBufferSize)...)
+	s.mu.Unlock()
+
+	s.alertEngine.RestoreArbCooldowns(saved.ArbCooldowns, time.Now())
+	return nil
+}
+
+// saveBuffers writes the newest PersistedBufferSize signals and alerts and the
+// active no-arb cooldowns. Call after the collectors have stopped.
+func (s *Server) saveBuffers() error {
+	if s.config.BufferStatePath == "" {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	s.mu.RLock()
+	saved := bufferState{
+		SavedAt:      now,
+		Signals:      newestN(s.signals, s.config.PersistedBufferSize),
+		Alerts:       newestN(s.alerts, s.config.PersistedBufferSize),
+		ArbCooldowns: s.alertEngine.ArbCooldowns(now),
+	}
+	data, err := marshalJSON(saved)
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal buffer state: %w", err)
+	}
+
+	tmp := s.config.BufferStatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write buffer state: %w", err)
+	}
+	return os.Rename(tmp, s.config.BufferStatePath)
+}
+
+// newestN returns the last n items (all of them if n <= 0 or there are fewer)
+func newestN[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[len(items)-n:]
+	}
+	return items
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestBuffersSurviveRestart(t *testing.T) {
+	cfg := config.APIConfig{
+		BufferStatePath:     filepath.Join(t.TempDir(), "buffers.json"),
+		PersistedBufferSize: 3,
+	}
+	scannerCfg := config.ScannerConfig{NoArbCooldownSecs: 300}
+	newServer := func() *Server {
+		return NewServer(cfg, scannerCfg, state.NewEngine(), make(chan signals.Signal))
+	}
+
+	before := newServer()
+	base := time.Now().Add(-time.Minute).UTC()
+	for i := 0; i < 5; i++ {
+		before.signals = append(before.signals, signals.Signal{
+			ID:           fmt.Sprintf("sig-%d", i),
+			MarketTicker: "MKT",
+			Type:         signals.SignalTypeVolumeSurge,
+			Timestamp:    base.Add(time.Duration(i) * time.Second),
+		})
+		before.alerts = append(before.alerts, alerts.Alert{
+			ID:           fmt.Sprintf("alert-%d", i),
+			MarketTicker: "MKT",
+			Timestamp:    base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	before.alertEngine.RestoreArbCooldowns([]alerts.ArbCooldown{
+		{EventTicker: "EV-LIVE", At: time.Now().Add(-time.Minute), NetArb: 0.04},
+		{EventTicker: "EV-EXPIRED", At: time.Now().Add(-time.Hour), NetArb: 0.05},
+	}, time.Now())
+
+	if err := before.saveBuffers(); err != nil {
+		t.Fatal(err)
+	}
+
+	after := newServer()
+	if err := after.loadBuffers(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the newest PersistedBufferSize entries come back, in order
+	var signalIDs, alertIDs []string
+	for _, sig := range after.signals {
+		signalIDs = append(signalIDs, sig.ID)
+	}
+	for _, alert := range after.alerts {
+		alertIDs = append(alertIDs, alert.ID)
+	}
+	if fmt.Sprint(signalIDs) != "[sig-2 sig-3 sig-4]" {
+		t.Errorf("restored signals = %v, want the newest three", signalIDs)
+	}
+	if fmt.Sprint(alertIDs) != "[alert-2 alert-3 alert-4]" {
+		t.Errorf("restored alerts = %v, want the newest three", alertIDs)
+	}
+	if got := after.signals[2].Timestamp; !got.Equal(base.Add(4*time.Second)) || got.Location() != time.UTC {
+		t.Errorf("restored signal timestamp = %v, want %v in UTC", got, base.Add(4*time.Second))
+	}
+
+	cooldowns := after.alertEngine.ArbCooldowns(time.Now())
+	if len(cooldowns) != 1 || cooldowns[0].EventTicker != "EV-LIVE" || cooldowns[0].NetArb != 0.04 {
+		t.Errorf("restored cooldowns = %+v, want only EV-LIVE", cooldowns)
+	}
+
+	// A first run with no saved file starts empty
+	cfg.BufferStatePath = filepath.Join(t.TempDir(), "missing.json")
+	fresh := newServer()
+	if err := fresh.loadBuffers(); err != nil || len(fresh.signals) != 0 {
+		t.Errorf("missing file: err = %v, %d signals", err, len(fresh.signals))
+	}
+}
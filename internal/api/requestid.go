@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLen bounds inbound IDs so a client can't bloat every log line
+	maxRequestIDLen = 64
+	// slowRequestThreshold is how long a request may take before it's logged
+	slowRequestThreshold = time.Second
+)
+
+type requestIDKey struct{}
+
+// withRequestID tags every request with an ID, taken from an inbound
+// X-Request-ID when it's well formed and generated otherwise. The ID is echoed
+// in the response header, carried in the request context for handlers'
+// log lines, and logged with requests that fail (5xx) or are slow. Streams are
+// long-lived by design, so only their failures are logged.
+func (s *Server) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		rec := &requestRecorder{ResponseWriter: w, id: id, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		elapsed := time.Since(start)
+
+		if rec.status >= http.StatusInternalServerError || (elapsed >= slowRequestThreshold && !isStreamPath(r.URL.Path)) {
+			fmt.Printf("[%s] %s %s -> %d in %s\n", id, r.Method, r.URL.Path, rec.status, elapsed.Round(time.Millisecond))
+		}
+	})
+}
+
+// requestLogf logs a line prefixed with the request's ID
+func requestLogf(r *http.Request, format string, args ...interface{}) {
+	fmt.Printf("[%s] "+format, append([]interface{}{requestIDFromContext(r.Context())}, args...)...)
+}
+
+// requestIDFromContext returns the ID withRequestID stored, or "-" outside a request
+func requestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return "-"
+}
+
+// requestIDFromWriter recovers the ID for helpers that only see the
+// ResponseWriter, such as writeJSON
+func requestIDFromWriter(w http.ResponseWriter) string {
+	if rec, ok := w.(*requestRecorder); ok {
+		return rec.id
+	}
+	return "-"
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// requestRecorder captures the response status. It passes Flush and Hijack
+// through so SSE and WebSocket handlers work behind it.
+type requestRecorder struct {
+	http.ResponseWriter
+	id          string
+	status      int
+	wroteHeader bool
+}
+
+func (rec *requestRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *requestRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *requestRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rec *requestRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+func (rec *requestRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestRequestIDInLogsAndResponseHeader(t *testing.T) {
+	s, _ := newTestServer(t, config.APIConfig{})
+	handler := s.withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestLogf(r, "looking up %s\n", "MKT")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	tests := []struct {
+		name    string
+		inbound string
+		keep    bool
+	}{
+		{"inbound ID kept", "trace-abc_123.4", true},
+		{"malformed inbound ID replaced", "bad id\nwith newline", false},
+		{"overlong inbound ID replaced", strings.Repeat("a", maxRequestIDLen+1), false},
+		{"generated when absent", "", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/markets/MKT", nil)
+		if tt.inbound != "" {
+			req.Header.Set(requestIDHeader, tt.inbound)
+		}
+		rec := httptest.NewRecorder()
+		logs := captureStdout(t, func() { handler.ServeHTTP(rec, req) })
+
+		id := rec.Header().Get(requestIDHeader)
+		if tt.keep && id != tt.inbound {
+			t.Errorf("%s: response ID = %q, want %q", tt.name, id, tt.inbound)
+		}
+		if !tt.keep && (id == tt.inbound || !validRequestID(id)) {
+			t.Errorf("%s: response ID = %q, want a fresh valid one", tt.name, id)
+		}
+
+		// Both the handler's line and the failed-request line carry the ID
+		lines := strings.Split(strings.TrimSpace(logs), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s: logged %q, want two lines", tt.name, logs)
+		}
+		if lines[0] != "["+id+"] looking up MKT" {
+			t.Errorf("%s: handler log = %q", tt.name, lines[0])
+		}
+		if !strings.HasPrefix(lines[1], "["+id+"] GET /api/v1/markets/MKT -> 500") {
+			t.Errorf("%s: request log = %q", tt.name, lines[1])
+		}
+	}
+}
+
+func TestRoutesSetRequestIDHeader(t *testing.T) {
+	_, ts := newTestServer(t, config.APIConfig{})
+
+	req, _ := http.NewRequest("GET", ts.URL+"/api/v1/health", nil)
+	req.Header.Set(requestIDHeader, "from-client")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get(requestIDHeader); got != "from-client" {
+		t.Errorf("X-Request-ID = %q, want the client's", got)
+	}
+
+	// A fast, successful request isn't logged
+	logs := captureStdout(t, func() {
+		resp, err := http.Get(ts.URL + "/api/v1/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.Header.Get(requestIDHeader) == "" {
+			t.Error("no X-Request-ID on a request without one")
+		}
+	})
+	if logs != "" {
+		t.Errorf("fast 200 logged %q", logs)
+	}
+}
@@ -2,19 +2,25 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/audit"
+	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/scanner"
+	"github.com/kalshi-signal-feed/internal/ingestion"
 	"github.com/kalshi-signal-feed/internal/signals"
 	"github.com/kalshi-signal-feed/internal/state"
 	"github.com/rs/cors"
@@ -25,44 +31,146 @@ type Server struct {
 	state      *state.Engine
 	signalChan <-chan signals.Signal
 	server     *http.Server
+	listener   net.Listener // bound by Listen, served by Run
+	redirectListener net.Listener // plain HTTP redirected to HTTPS, when configured
+	tlsEnabled bool
 	signals    []signals.Signal
 	alerts     []alerts.Alert
 	mu         sync.RWMutex
+
+	// Long-lived analysis components, shared by handlers and the alert loop
+	scanner     *scanner.Scanner
+	noArbEngine *scanner.NoArbEngine
+	alertEngine *alerts.Engine
+
+	subscribers map[chan streamEvent]struct{}
+	subMu       sync.RWMutex
+	streams     int // open SSE and WebSocket streams, guarded by subMu
+
+	micropriceLevels    int // levels per side in quant microprices, as the scanner uses
+	largeTradeContracts int // trade size counted as large in debug trade-size stats
+	freshOnly           bool // default for /scanner/opportunities?fresh_only
+
+	breakerStatus func() ingestion.BreakerStatus // nil until SetBreakerStatusFunc
+
+	watchlists *watchlistStore
 }
 
-func NewServer(cfg config.APIConfig, stateEngine *state.Engine, signalChan <-chan signals.Signal) *Server {
+func NewServer(cfg config.APIConfig, scannerCfg config.ScannerConfig, stateEngine *state.Engine, signalChan <-chan signals.Signal) *Server {
 	return &Server{
 		config:     cfg,
+		scanner:     scanner.NewScanner(stateEngine, scannerCfg),
+		noArbEngine: scanner.NewNoArbEngine(stateEngine, scannerCfg),
+		alertEngine: alerts.NewEngine(stateEngine, scannerCfg),
 		state:      stateEngine,
 		signalChan: signalChan,
 		signals:    make([]signals.Signal, 0, 1000),
+		subscribers: make(map[chan streamEvent]struct{}),
+		watchlists:  newWatchlistStore(cfg.WatchlistsPath),
+
+		micropriceLevels:    scannerCfg.MicropriceLevels,
+		largeTradeContracts: scannerCfg.LargeTradeContracts,
+		freshOnly:           scannerCfg.FreshOnly,
 	}
 }
 
-func (s *Server) Run(ctx context.Context) error {
+// SetAuditLog attaches an audit log that the server's alert engine writes to
+func (s *Server) SetAuditLog(auditLog *audit.Log) {
+	s.alertEngine.SetAuditLog(auditLog)
+}
+
+// SetDashboardBaseURL makes alerts served by the API link to the dashboard
+func (s *Server) SetDashboardBaseURL(baseURL string) {
+	s.alertEngine.SetDashboardBaseURL(baseURL)
+}
+
+// SetBreakerStatusFunc lets /health report the ingestion circuit breaker
+func (s *Server) SetBreakerStatusFunc(fn func() ingestion.BreakerStatus) {
+	s.breakerStatus = fn
+}
+
+// Listen binds the configured address (and the HTTPS redirect address) and
+// loads any TLS certificate, so a port conflict or bad certificate can fail
+// startup before anything else runs. Run calls it if it hasn't been called.
+func (s *Server) Listen() error {
+	tlsConfig, err := s.loadTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.config.BindAddress)
+	if err != nil {
+		return fmt.Errorf("failed to bind %s: %w", s.config.BindAddress, err)
+	}
+
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+		s.tlsEnabled = true
+
+		if s.config.HTTPRedirectAddress != "" {
+			redirectListener, err := net.Listen("tcp", s.config.HTTPRedirectAddress)
+			if err != nil {
+				listener.Close()
+				return fmt.Errorf("failed to bind %s: %w", s.config.HTTPRedirectAddress, err)
+			}
+			s.redirectListener = redirectListener
+		}
+	}
+
+	s.listener = listener
+	return nil
+}
+
+// routes builds the API router wrapped in the CORS, API-key and request-ID
+// middleware
+func (s *Server) routes() http.Handler {
 	router := mux.NewRouter()
 
 	// Setup CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins:   s.config.CORSOrigins,
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{requestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           3600,
 	})
 
 	// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Unknown endpoint")
+	})
+	api.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Method not allowed")
+	})
 	api.HandleFunc("/markets", s.getMarkets).Methods("GET")
 	api.HandleFunc("/markets/{ticker}", s.getMarket).Methods("GET")
 	api.HandleFunc("/markets/{ticker}/orderbook", s.getOrderbook).Methods("GET")
 	api.HandleFunc("/markets/{ticker}/debug", s.getMarketDebug).Methods("GET")
+	api.HandleFunc("/markets/{ticker}/overview", s.getMarketOverview).Methods("GET")
+	api.HandleFunc("/markets/{ticker}/quant/history", s.getQuantHistory).Methods("GET")
+	api.HandleFunc("/markets/{ticker}/ohlc", s.getOHLC).Methods("GET")
+	api.HandleFunc("/markets/{ticker}/pin", s.pinMarket).Methods("POST")
+	api.HandleFunc("/markets/{ticker}/pin", s.unpinMarket).Methods("DELETE")
+	api.HandleFunc("/pinned", s.getPinned).Methods("GET")
+	api.HandleFunc("/watchlists", s.getWatchlists).Methods("GET")
+	api.HandleFunc("/watchlists/{name}", s.getWatchlist).Methods("GET")
+	api.HandleFunc("/watchlists/{name}", s.putWatchlist).Methods("PUT")
+	api.HandleFunc("/watchlists/{name}", s.deleteWatchlist).Methods("DELETE")
+	api.HandleFunc("/watchlists/{name}/stream", s.streamWatchlist).Methods("GET")
 	api.HandleFunc("/scanner/opportunities", s.getOpportunities).Methods("GET")
 	api.HandleFunc("/scanner/noarb", s.getNoArbViolations).Methods("GET")
 	api.HandleFunc("/alerts", s.getAlerts).Methods("GET")
+	api.HandleFunc("/backtest", s.postBacktest).Methods("POST")
 	api.HandleFunc("/signals", s.getSignals).Methods("GET")
 	api.HandleFunc("/stream/signals", s.streamSignals).Methods("GET")
+	api.HandleFunc("/ws/signals", s.streamSignalsWS).Methods("GET")
 	api.HandleFunc("/categories", s.getCategories).Methods("GET")
+	api.HandleFunc("/categories/list", s.getCategoryList).Methods("GET")
+	api.HandleFunc("/categories/activity", s.getCategoryActivity).Methods("GET")
+	api.HandleFunc("/calibration", s.getCalibration).Methods("GET")
+	api.HandleFunc("/diagnostics/missing-books", s.getMissingBooks).Methods("GET")
 	api.HandleFunc("/health", s.getHealth).Methods("GET")
 
 	// Serve static files from dashboard/dist
@@ -75,30 +183,88 @@ func (s *Server) Run(ctx context.Context) error {
 			if !strings.HasPrefix(r.URL.Path, "/api") {
 				http.ServeFile(w, r, filepath.Join(staticDir, "index.html"))
 			} else {
-				http.NotFound(w, r)
+				writeError(w, http.StatusNotFound, errCodeNotFound, "Unknown endpoint")
 			}
 		})
 	}
 
-	handler := c.Handler(router)
+	return s.withRequestID(c.Handler(s.requireAPIKey(router)))
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	if s.listener == nil {
+		if err := s.Listen(); err != nil {
+			return err
+		}
+	}
+
+	handler := s.routes()
 
 	s.server = &http.Server{
 		Addr:    s.config.BindAddress,
 		Handler: handler,
 	}
 
+	if err := s.loadBuffers(); err != nil {
+		fmt.Printf("Failed to restore signal and alert buffers: %v\n", err)
+	}
+	if err := s.watchlists.load(); err != nil {
+		fmt.Printf("Failed to load watchlists: %v\n", err)
+	}
+
+	var collectors sync.WaitGroup
+	collectors.Add(2)
+
 	// Start signal collector
-	go s.collectSignals(ctx)
-	
+	go func() {
+		defer collectors.Done()
+		s.collectSignals(ctx)
+	}()
+
 	// Start alert checker
-	go s.collectAlerts(ctx)
+	go func() {
+		defer collectors.Done()
+		s.collectAlerts(ctx)
+	}()
+
+	var redirectServer *http.Server
+	if s.redirectListener != nil {
+		redirectServer = &http.Server{Handler: s.redirectToHTTPS()}
+		go func() {
+			fmt.Printf("Redirecting HTTP on %s to HTTPS\n", s.config.HTTPRedirectAddress)
+			if err := redirectServer.Serve(s.redirectListener); err != nil && err != http.ErrServerClosed {
+				fmt.Printf("HTTPS redirect server error: %v\n", err)
+			}
+		}()
+	}
+
+	// Stop serving on shutdown
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if redirectServer != nil {
+			redirectServer.Shutdown(shutdownCtx)
+		}
+		s.server.Shutdown(shutdownCtx)
+	}()
 
-	fmt.Printf("API server starting on %s\n", s.config.BindAddress)
+	scheme := "http"
+	if s.tlsEnabled {
+		scheme = "https"
+	}
+	fmt.Printf("API server starting on %s (%s)\n", s.config.BindAddress, scheme)
 
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := s.server.Serve(s.listener); err != nil && err != http.ErrServerClosed {
 		return err
 	}
 
+	// Flush the buffers once the collectors have stopped writing to them
+	collectors.Wait()
+	if err := s.saveBuffers(); err != nil {
+		fmt.Printf("Failed to save signal and alert buffers: %v\n", err)
+	}
+
 	return nil
 }
 
@@ -115,23 +281,71 @@ func (s *Server) collectSignals(ctx context.Context) {
 				s.signals = s.signals[len(s.signals)-1000:]
 			}
 			s.mu.Unlock()
+
+			s.publishSignal(signal)
 		}
 	}
 }
 
+// getMarkets lists markets. By default only active markets are returned, matching
+// the categories endpoint; include_inactive=true returns all, and status=<status>
+// narrows to a single status regardless of include_inactive.
 func (s *Server) getMarkets(w http.ResponseWriter, r *http.Request) {
-	markets := s.state.GetAllMarkets()
+	status := r.URL.Query().Get("status")
+	includeInactive := false
+	if v := r.URL.Query().Get("include_inactive"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid include_inactive")
+			return
+		}
+		includeInactive = parsed
+	}
+
+	includeBook := false
+	if v := r.URL.Query().Get("include_book"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid include_book")
+			return
+		}
+		includeBook = parsed
+	}
+
+	// Book is only populated with include_book, so the default stays lightweight
+	type marketEntry struct {
+		*state.Market
+		Book *topOfBook `json:"book,omitempty"`
+	}
+
+	markets := make([]marketEntry, 0)
+	for _, market := range s.state.GetAllMarkets() {
+		if status != "" {
+			if string(market.Status) != status {
+				continue
+			}
+		} else if !includeInactive && market.Status != state.StatusActive {
+			continue
+		}
+		entry := marketEntry{Market: market}
+		if includeBook {
+			if orderbook, ok := s.state.GetOrderbook(market.Ticker); ok {
+				top := newTopOfBook(orderbook)
+				entry.Book = &top
+			}
+		}
+		markets = append(markets, entry)
+	}
 
 	response := struct {
-		Markets []*state.Market `json:"markets"`
-		Count   int             `json:"count"`
+		Markets []marketEntry `json:"markets"`
+		Count   int           `json:"count"`
 	}{
 		Markets: markets,
 		Count:   len(markets),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, response)
 }
 
 func (s *Server) getMarket(w http.ResponseWriter, r *http.Request) {
@@ -140,26 +354,98 @@ func (s *Server) getMarket(w http.ResponseWriter, r *http.Request) {
 
 	market, exists := s.state.GetMarket(ticker)
 	if !exists {
-		http.Error(w, "Market not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Market not found")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(market)
+	response := struct {
+		*state.Market
+		Metadata *state.MarketMetadata `json:"metadata,omitempty"`
+		Quote    *state.Quote          `json:"quote,omitempty"` // from the WebSocket ticker channel
+	}{
+		Market: market,
+	}
+	if metadata, ok := s.state.GetMarketMetadata(ticker); ok {
+		response.Metadata = metadata
+	}
+	if quote, ok := s.state.GetQuote(ticker); ok {
+		response.Quote = quote
+	}
+
+	writeJSON(w, response)
 }
 
 func (s *Server) getOrderbook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	ticker := vars["ticker"]
 
+	levels := s.config.OrderbookLevels
+	if v := r.URL.Query().Get("levels"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid levels")
+			return
+		}
+		levels = parsed
+	}
+
 	orderbook, exists := s.state.GetOrderbook(ticker)
 	if !exists {
-		http.Error(w, "Orderbook not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Orderbook not found")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orderbook)
+	// Truncate the returned copy only; the stored book stays complete
+	if levels > 0 {
+		if len(orderbook.Bids) > levels {
+			orderbook.Bids = orderbook.Bids[:levels]
+		}
+		if len(orderbook.Asks) > levels {
+			orderbook.Asks = orderbook.Asks[:levels]
+		}
+	}
+
+	writeJSON(w, orderbook)
+}
+
+func (s *Server) pinMarket(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	if err := s.state.PinMarket(ticker); err != nil {
+		writeError(w, http.StatusInternalServerError, errCodeInternal, fmt.Sprintf("Failed to pin market: %v", err))
+		return
+	}
+
+	s.writePinned(w)
+}
+
+func (s *Server) unpinMarket(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	if err := s.state.UnpinMarket(ticker); err != nil {
+		writeError(w, http.StatusInternalServerError, errCodeInternal, fmt.Sprintf("Failed to unpin market: %v", err))
+		return
+	}
+
+	s.writePinned(w)
+}
+
+func (s *Server) getPinned(w http.ResponseWriter, r *http.Request) {
+	s.writePinned(w)
+}
+
+func (s *Server) writePinned(w http.ResponseWriter) {
+	pinned := s.state.PinnedMarkets()
+
+	response := struct {
+		Pinned []string `json:"pinned"`
+		Count  int      `json:"count"`
+	}{
+		Pinned: pinned,
+		Count:  len(pinned),
+	}
+
+	writeJSON(w, response)
 }
 
 func (s *Server) getSignals(w http.ResponseWriter, r *http.Request) {
@@ -173,6 +459,16 @@ func (s *Server) getSignals(w http.ResponseWriter, r *http.Request) {
 	signalType := r.URL.Query().Get("type")
 	limitStr := r.URL.Query().Get("limit")
 
+	minConfidence := 0.0
+	if minStr := r.URL.Query().Get("min_confidence"); minStr != "" {
+		v, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid min_confidence")
+			return
+		}
+		minConfidence = v
+	}
+
 	// Filter signals
 	filtered := make([]signals.Signal, 0)
 	for _, sig := range signalsCopy {
@@ -182,6 +478,9 @@ func (s *Server) getSignals(w http.ResponseWriter, r *http.Request) {
 		if signalType != "" && string(sig.Type) != signalType {
 			continue
 		}
+		if sig.Metadata.Confidence < minConfidence {
+			continue
+		}
 		filtered = append(filtered, sig)
 	}
 
@@ -196,8 +495,21 @@ func (s *Server) getSignals(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if limit < len(filtered) {
-		filtered = filtered[len(filtered)-limit:]
+	// sort=score ranks across types, highest first, and the limit keeps the top;
+	// otherwise signals stay in arrival order and the limit keeps the newest
+	switch r.URL.Query().Get("sort") {
+	case "":
+		if limit < len(filtered) {
+			filtered = filtered[len(filtered)-limit:]
+		}
+	case "score":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Score > filtered[j].Score
+		})
+		filtered = filtered[:limit]
+	default:
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid sort")
+		return
 	}
 
 	response := struct {
@@ -208,23 +520,27 @@ func (s *Server) getSignals(w http.ResponseWriter, r *http.Request) {
 		Count:   len(filtered),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, response)
 }
 
 func (s *Server) streamSignals(w http.ResponseWriter, r *http.Request) {
-	// Upgrade to WebSocket would go here
-	// For now, return SSE (Server-Sent Events)
+	// Server-Sent Events; WebSocket clients use /ws/signals instead
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, errCodeInternal, "Streaming not supported")
 		return
 	}
 
+	if !s.acquireStream() {
+		writeError(w, http.StatusServiceUnavailable, errCodeUnavailable, "Too many stream clients")
+		return
+	}
+	defer s.releaseStream()
+
 	// Send initial connection message
 	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
 	flusher.Flush()
@@ -250,7 +566,11 @@ func (s *Server) streamSignals(w http.ResponseWriter, r *http.Request) {
 				s.mu.RUnlock()
 
 				for _, sig := range newSignals {
-					data, _ := json.Marshal(sig)
+					data, err := marshalJSON(sig)
+					if err != nil {
+						requestLogf(r, "Failed to encode signal event: %v\n", err)
+						continue
+					}
 					fmt.Fprintf(w, "data: %s\n\n", string(data))
 					flusher.Flush()
 				}
@@ -260,13 +580,16 @@ func (s *Server) streamSignals(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Distances from mid, in cents, of the debug endpoint's imbalance profile
+var imbalanceBands = []int{1, 2, 5, 10}
+
 func (s *Server) getMarketDebug(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	ticker := vars["ticker"]
 
 	market, exists := s.state.GetMarket(ticker)
 	if !exists {
-		http.Error(w, "Market not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Market not found")
 		return
 	}
 
@@ -277,25 +600,36 @@ func (s *Server) getMarketDebug(w http.ResponseWriter, r *http.Request) {
 		MarketTicker        string    `json:"market_ticker"`
 		MarketStatus       string    `json:"market_status"`
 		HasOrderbook       bool      `json:"has_orderbook"`
+		BookState          string    `json:"book_state"`
 		OrderbookTimestamp *time.Time `json:"orderbook_timestamp,omitempty"`
 		BidLevels           int       `json:"bid_levels"`
 		AskLevels           int       `json:"ask_levels"`
 		BestBid             *int      `json:"best_bid,omitempty"`
 		BestAsk             *int      `json:"best_ask,omitempty"`
 		Spread              *int      `json:"spread,omitempty"`
+		SpreadTicks         *int      `json:"spread_ticks,omitempty"`
+		TickSize            int       `json:"tick_size"`
 		Microprice          *float64  `json:"microprice,omitempty"`
+		FairValue           *float64  `json:"fair_value,omitempty"`
+		ImbalanceProfile    []state.BandImbalance `json:"imbalance_profile,omitempty"`
 		TradeCount          int       `json:"trade_count"`
+		TradeSizes          state.TradeSizeStats `json:"trade_sizes"`
 		LastTradeTimestamp  *time.Time `json:"last_trade_timestamp,omitempty"`
 		SignalCount         int       `json:"signal_count"`
 		LastSignalTimestamp *time.Time `json:"last_signal_timestamp,omitempty"`
+		Warmup              state.WarmupStatus `json:"warmup"`
 	}{
 		MarketTicker:  ticker,
 		MarketStatus:  string(market.Status),
 		HasOrderbook:  hasOrderbook,
+		BookState:     string(market.BookState),
+		TickSize:      market.Tick(),
 		BidLevels:     0,
 		AskLevels:     0,
 		TradeCount:    len(trades),
+		TradeSizes:    state.TradeSizeDistribution(trades, s.largeTradeContracts),
 		SignalCount:   0,
+		Warmup:        s.state.GetWarmupStatus(ticker),
 	}
 
 	if hasOrderbook {
@@ -315,11 +649,19 @@ func (s *Server) getMarketDebug(w http.ResponseWriter, r *http.Request) {
 		if spread, ok := orderbook.Spread(); ok {
 			spreadInt := int(spread)
 			debug.Spread = &spreadInt
+			spreadTicks := market.SpreadInTicks(spread)
+			debug.SpreadTicks = &spreadTicks
 		}
 
-		if microprice, ok := orderbook.Microprice(); ok {
+		if microprice, ok := s.scanner.Microprice(orderbook); ok {
 			debug.Microprice = &microprice
 		}
+
+		if fairValue, ok := s.scanner.FairValue(ticker); ok {
+			debug.FairValue = &fairValue
+		}
+
+		debug.ImbalanceProfile = orderbook.ImbalanceProfile(imbalanceBands)
 	}
 
 	if len(trades) > 0 {
@@ -339,8 +681,286 @@ func (s *Server) getMarketDebug(w http.ResponseWriter, r *http.Request) {
 	}
 	s.mu.RUnlock()
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(debug)
+	writeJSON(w, debug)
+}
+
+// topOfBook summarises the best levels of a market's orderbook
+type topOfBook struct {
+	BestBid    *int     `json:"best_bid,omitempty"`
+	BestAsk    *int     `json:"best_ask,omitempty"`
+	BidSize    *int     `json:"bid_size,omitempty"`
+	AskSize    *int     `json:"ask_size,omitempty"`
+	Mid        *float64 `json:"mid,omitempty"` // cents
+	Spread     *int     `json:"spread,omitempty"`
+	Microprice *float64 `json:"microprice,omitempty"` // cents, like mid
+	LastUpdate *time.Time `json:"last_update,omitempty"`
+}
+
+func newTopOfBook(orderbook *state.Orderbook) topOfBook {
+	var top topOfBook
+	top.LastUpdate = &orderbook.LastUpdate
+	if len(orderbook.Bids) > 0 {
+		top.BestBid = &orderbook.Bids[0].Price
+		top.BidSize = &orderbook.Bids[0].Quantity
+	}
+	if len(orderbook.Asks) > 0 {
+		top.BestAsk = &orderbook.Asks[0].Price
+		top.AskSize = &orderbook.Asks[0].Quantity
+	}
+	if spread, ok := orderbook.Spread(); ok {
+		mid := float64(orderbook.Bids[0].Price+orderbook.Asks[0].Price) / 2.0
+		top.Mid = &mid
+		top.Spread = &spread
+	}
+	if microprice, ok := orderbook.Microprice(); ok {
+		// Orderbook.Microprice is a probability; the book summary is in cents
+		cents := microprice * 100
+		top.Microprice = &cents
+	}
+	return top
+}
+
+// getMarketOverview returns everything the per-market view needs in one response,
+// read at a single point in time so sections are consistent with each other
+func (s *Server) getMarketOverview(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	market, exists := s.state.GetMarket(ticker)
+	if !exists {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Market not found")
+		return
+	}
+
+	response := struct {
+		Market      *state.Market                `json:"market"`
+		Metadata    *state.MarketMetadata        `json:"metadata,omitempty"`
+		TopOfBook   topOfBook                    `json:"top_of_book"`
+		Signals     []signals.Signal             `json:"signals"`
+		Alerts      []alerts.Alert               `json:"alerts"`
+		Opportunity *scanner.MarketOpportunity   `json:"opportunity,omitempty"`
+		Quant       *signals.QuantitativeSignal  `json:"quant,omitempty"`
+		Timestamp   time.Time                    `json:"timestamp"`
+	}{
+		Market:    market,
+		Signals:   make([]signals.Signal, 0),
+		Alerts:    make([]alerts.Alert, 0),
+		Timestamp: time.Now().UTC(),
+	}
+
+	if metadata, ok := s.state.GetMarketMetadata(ticker); ok {
+		response.Metadata = metadata
+	}
+
+	if orderbook, ok := s.state.GetOrderbook(ticker); ok {
+		response.TopOfBook = newTopOfBook(orderbook)
+
+		trades := s.state.GetRecentTrades(ticker, 5*time.Minute)
+		response.Quant = signals.ComputeQuantitativeSignals(ticker, orderbook, trades, market.ExpirationTime, s.micropriceLevels)
+	}
+
+	if opp, ok := s.scanner.ScanMarket(ticker); ok {
+		response.Opportunity = opp
+	}
+
+	// Most recent 50 signals and alerts for this market
+	const overviewLimit = 50
+	s.mu.RLock()
+	for i := len(s.signals) - 1; i >= 0 && len(response.Signals) < overviewLimit; i-- {
+		if s.signals[i].MarketTicker == ticker {
+			response.Signals = append(response.Signals, s.signals[i])
+		}
+	}
+	for i := len(s.alerts) - 1; i >= 0 && len(response.Alerts) < overviewLimit; i-- {
+		if s.alerts[i].MarketTicker == ticker {
+			response.Alerts = append(response.Alerts, s.alerts[i])
+		}
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, response)
+}
+
+// getQuantHistory returns recorded quant metrics for a market.
+// window is in seconds and defaults to one hour.
+func (s *Server) getQuantHistory(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	if _, exists := s.state.GetMarket(ticker); !exists {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Market not found")
+		return
+	}
+
+	window := time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		secs, err := parseInt(windowStr)
+		if err != nil || secs <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid window")
+			return
+		}
+		window = time.Duration(secs) * time.Second
+	}
+
+	// Rolling Sharpe: returns over sharpe_period seconds, ratio over sharpe_window returns
+	sharpePeriod := time.Minute
+	if v := r.URL.Query().Get("sharpe_period"); v != "" {
+		secs, err := parseInt(v)
+		if err != nil || secs <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid sharpe_period")
+			return
+		}
+		sharpePeriod = time.Duration(secs) * time.Second
+	}
+	sharpeWindow := 20
+	if v := r.URL.Query().Get("sharpe_window"); v != "" {
+		n, err := parseInt(v)
+		if err != nil || n < 2 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid sharpe_window")
+			return
+		}
+		sharpeWindow = n
+	}
+
+	since := time.Now().Add(-window)
+	history := s.state.GetTimeSeries().GetQuantHistory(ticker, since)
+	if history == nil {
+		history = []state.QuantPoint{}
+	}
+	rollingSharpe := s.state.GetTimeSeries().GetRollingSharpe(ticker, since, sharpePeriod, sharpeWindow)
+	if rollingSharpe == nil {
+		rollingSharpe = []state.SharpePoint{}
+	}
+
+	response := struct {
+		MarketTicker  string              `json:"market_ticker"`
+		WindowSecs    int                 `json:"window_secs"`
+		History       []state.QuantPoint  `json:"history"`
+		Count         int                 `json:"count"`
+		RollingSharpe []state.SharpePoint `json:"rolling_sharpe"` // empty until enough periods have data
+		SharpePeriodSecs int              `json:"sharpe_period_secs"`
+		SharpeWindow  int                 `json:"sharpe_window"`
+	}{
+		MarketTicker:  ticker,
+		WindowSecs:    int(window.Seconds()),
+		History:       history,
+		Count:         len(history),
+		RollingSharpe: rollingSharpe,
+		SharpePeriodSecs: int(sharpePeriod.Seconds()),
+		SharpeWindow:  sharpeWindow,
+	}
+
+	writeJSON(w, response)
+}
+
+// getCalibration reports how well prices of resolved markets matched their
+// outcomes, bucketed by price. buckets defaults to 10 (10-cent ranges).
+func (s *Server) getCalibration(w http.ResponseWriter, r *http.Request) {
+	buckets := 10
+	if v := r.URL.Query().Get("buckets"); v != "" {
+		n, err := parseInt(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid buckets")
+			return
+		}
+		buckets = n
+	}
+
+	report := state.Calibrate(s.state.GetResolutions(), buckets)
+
+	writeJSON(w, report)
+}
+
+// defaultBookGapMaxAgeSecs is the book age past which /diagnostics/missing-books
+// reports a market as stale; it allows for a couple of missed REST polls
+const defaultBookGapMaxAgeSecs = 180
+
+// getMissingBooks lists active markets that can't produce signals for lack of
+// a usable book: never fetched, empty, or older than max_age seconds
+func (s *Server) getMissingBooks(w http.ResponseWriter, r *http.Request) {
+	maxAgeSecs := defaultBookGapMaxAgeSecs
+	if v := r.URL.Query().Get("max_age"); v != "" {
+		n, err := parseInt(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid max_age")
+			return
+		}
+		maxAgeSecs = n
+	}
+
+	now := time.Now().UTC()
+	gaps := s.state.GetBookGaps(now, time.Duration(maxAgeSecs)*time.Second)
+
+	response := struct {
+		Markets    []state.BookGap `json:"markets"`
+		Count      int             `json:"count"`
+		MaxAgeSecs int             `json:"max_age_secs"`
+		Timestamp  time.Time       `json:"timestamp"`
+	}{
+		Markets:    gaps,
+		Count:      len(gaps),
+		MaxAgeSecs: maxAgeSecs,
+		Timestamp:  now,
+	}
+
+	writeJSON(w, response)
+}
+
+// maxCandles bounds the candles one OHLC request can produce
+const maxCandles = 5000
+
+// getOHLC returns mid-price candles for a market. interval is a duration such
+// as 1m, 5m or 1h (default 1m, minimum 1m); window is in seconds (default one day).
+func (s *Server) getOHLC(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	if _, exists := s.state.GetMarket(ticker); !exists {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Market not found")
+		return
+	}
+
+	interval := time.Minute
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Minute || d%time.Minute != 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid interval: use a whole number of minutes, e.g. 1m, 5m, 1h")
+			return
+		}
+		interval = d
+	}
+
+	window := 24 * time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		secs, err := parseInt(v)
+		if err != nil || secs <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid window")
+			return
+		}
+		window = time.Duration(secs) * time.Second
+	}
+	if window/interval > maxCandles {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, fmt.Sprintf("Window spans more than %d candles; use a longer interval", maxCandles))
+		return
+	}
+
+	candles := s.state.GetTimeSeries().GetCandles(ticker, time.Now().Add(-window), interval)
+	if candles == nil {
+		candles = []state.Bar{}
+	}
+
+	response := struct {
+		MarketTicker string      `json:"market_ticker"`
+		IntervalSecs int         `json:"interval_secs"`
+		WindowSecs   int         `json:"window_secs"`
+		Candles      []state.Bar `json:"candles"`
+		Count        int         `json:"count"`
+	}{
+		MarketTicker: ticker,
+		IntervalSecs: int(interval.Seconds()),
+		WindowSecs:   int(window.Seconds()),
+		Candles:      candles,
+		Count:        len(candles),
+	}
+
+	writeJSON(w, response)
 }
 
 func (s *Server) getHealth(w http.ResponseWriter, r *http.Request) {
@@ -348,19 +968,70 @@ func (s *Server) getHealth(w http.ResponseWriter, r *http.Request) {
 		Status    string    `json:"status"`
 		Timestamp time.Time `json:"timestamp"`
 		Markets   int       `json:"markets"`
+		Breaker   *ingestion.BreakerStatus `json:"rest_breaker,omitempty"`
 	}{
 		Status:    "healthy",
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 		Markets:   len(s.state.GetAllMarkets()),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	if s.breakerStatus != nil {
+		breaker := s.breakerStatus()
+		response.Breaker = &breaker
+		if breaker.State != ingestion.BreakerClosed {
+			response.Status = "degraded"
+		}
+	}
+
+	writeJSON(w, response)
 }
 
 func (s *Server) getOpportunities(w http.ResponseWriter, r *http.Request) {
-	scan := scanner.NewScanner(s.state)
-	opportunities := scan.ScanMarkets()
+	freshOnly := s.freshOnly
+	if v := r.URL.Query().Get("fresh_only"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid fresh_only")
+			return
+		}
+		freshOnly = parsed
+	}
+
+	opportunities := s.scanner.ScanMarkets()
+
+	// Fresh-only drops stale books outright rather than returning them flagged
+	if freshOnly {
+		fresh := make([]scanner.MarketOpportunity, 0, len(opportunities))
+		for _, opp := range opportunities {
+			if !opp.BookStale {
+				fresh = append(fresh, opp)
+			}
+		}
+		opportunities = fresh
+	}
+
+	// Optional category filter, using the same classification as /categories
+	if category := r.URL.Query().Get("category"); category != "" {
+		filtered := make([]scanner.MarketOpportunity, 0, len(opportunities))
+		for _, opp := range opportunities {
+			if strings.EqualFold(categorizeMarket(opp.Title, opp.MarketTicker), category) {
+				filtered = append(filtered, opp)
+			}
+		}
+		opportunities = filtered
+	}
+
+	// Liquidity-ranked unless asked to rank by tradability or activity
+	switch r.URL.Query().Get("sort") {
+	case "tradability":
+		sort.SliceStable(opportunities, func(i, j int) bool {
+			return opportunities[i].TradabilityScore > opportunities[j].TradabilityScore
+		})
+	case "activity":
+		sort.SliceStable(opportunities, func(i, j int) bool {
+			return opportunities[i].ActivityScore > opportunities[j].ActivityScore
+		})
+	}
 
 	response := struct {
 		Opportunities []scanner.MarketOpportunity `json:"opportunities"`
@@ -369,16 +1040,14 @@ func (s *Server) getOpportunities(w http.ResponseWriter, r *http.Request) {
 	}{
 		Opportunities: opportunities,
 		Count:         len(opportunities),
-		Timestamp:     time.Now(),
+		Timestamp:     time.Now().UTC(),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, response)
 }
 
 func (s *Server) getNoArbViolations(w http.ResponseWriter, r *http.Request) {
-	engine := scanner.NewNoArbEngine(s.state)
-	violations := engine.CheckNoArbViolations()
+	violations := s.noArbEngine.CheckNoArbViolations()
 
 	response := struct {
 		Violations []scanner.NoArbViolation `json:"violations"`
@@ -387,15 +1056,13 @@ func (s *Server) getNoArbViolations(w http.ResponseWriter, r *http.Request) {
 	}{
 		Violations: violations,
 		Count:      len(violations),
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UTC(),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, response)
 }
 
 func (s *Server) collectAlerts(ctx context.Context) {
-	alertEngine := alerts.NewEngine(s.state)
 	ticker := time.NewTicker(5 * time.Second) // Check every 5 seconds
 	defer ticker.Stop()
 
@@ -404,7 +1071,7 @@ func (s *Server) collectAlerts(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			newAlerts := alertEngine.CheckAlerts()
+			newAlerts := s.alertEngine.CheckAlerts()
 			if len(newAlerts) > 0 {
 				s.mu.Lock()
 				s.alerts = append(s.alerts, newAlerts...)
@@ -413,6 +1080,10 @@ func (s *Server) collectAlerts(ctx context.Context) {
 					s.alerts = s.alerts[len(s.alerts)-1000:]
 				}
 				s.mu.Unlock()
+
+				for _, alert := range newAlerts {
+					s.publishAlert(alert)
+				}
 			}
 		}
 	}
@@ -463,11 +1134,89 @@ func (s *Server) getAlerts(w http.ResponseWriter, r *http.Request) {
 	}{
 		Alerts:    filtered,
 		Count:     len(filtered),
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
+	}
+
+	writeJSON(w, response)
+}
+
+// defaultBacktestLookbackSecs is the move horizon when a backtest request doesn't set one
+const defaultBacktestLookbackSecs = 300
+
+// backtestRequest is the body of POST /backtest; lookback is in seconds
+type backtestRequest struct {
+	MarketTicker string `json:"market_ticker"`
+	AlertType    string `json:"alert_type"`
+	Lookback     int    `json:"lookback"`
+}
+
+// postBacktest replays the recorded alerts of one type for a market against
+// the book history and reports how often the following move bore them out
+func (s *Server) postBacktest(w http.ResponseWriter, r *http.Request) {
+	var req backtestRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid JSON body")
+		return
+	}
+	if req.MarketTicker == "" || req.AlertType == "" {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "market_ticker and alert_type are required")
+		return
+	}
+	if req.Lookback < 0 {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid lookback")
+		return
+	}
+	if req.Lookback == 0 {
+		req.Lookback = defaultBacktestLookbackSecs
+	}
+	lookback := time.Duration(req.Lookback) * time.Second
+
+	s.mu.RLock()
+	var history []alerts.Alert
+	for _, alert := range s.alerts {
+		if alert.MarketTicker == req.MarketTicker && string(alert.Type) == req.AlertType {
+			history = append(history, alert)
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(history) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, errCodeInsufficientHistory,
+			fmt.Sprintf("No recorded %s alerts for %s", req.AlertType, req.MarketTicker))
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	stats := s.alertEngine.Backtest(history, lookback)
+	if stats.SampleSize == 0 {
+		writeError(w, http.StatusUnprocessableEntity, errCodeInsufficientHistory,
+			fmt.Sprintf("None of the %d recorded alerts has book snapshots from before it through %ds after it", len(history), req.Lookback))
+		return
+	}
+
+	response := struct {
+		MarketTicker string `json:"market_ticker"`
+		AlertType    string `json:"alert_type"`
+		LookbackSecs int    `json:"lookback_secs"`
+		Alerts       int    `json:"alerts"`
+		alerts.AlertStats
+	}{
+		MarketTicker: req.MarketTicker,
+		AlertType:    req.AlertType,
+		LookbackSecs: req.Lookback,
+		Alerts:       len(history),
+		AlertStats:   stats,
+	}
+
+	writeJSON(w, response)
+}
+
+// MarketCategory returns the dashboard category for a market, or "" if unknown
+func (s *Server) MarketCategory(ticker string) string {
+	market, exists := s.state.GetMarket(ticker)
+	if !exists {
+		return ""
+	}
+	return categorizeMarket(market.Title, market.Ticker)
 }
 
 // categorizeMarket uses keyword matching to categorize markets based on their title
@@ -715,7 +1464,8 @@ func categorizeMarket(title, ticker string) string {
 	return "Misc"
 }
 
-func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
+// groupMarketsByCategory buckets active markets by category, then by event ticker
+func (s *Server) groupMarketsByCategory() map[string]map[string][]*state.Market {
 	markets := s.state.GetAllMarkets()
 	
 	// Group markets by intelligent categorization and event_ticker
@@ -740,6 +1490,160 @@ func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
 		
 		categoryMap[category][eventTicker] = append(categoryMap[category][eventTicker], market)
 	}
+
+	return categoryMap
+}
+
+// getCategoryList returns just category names with market and event counts
+func (s *Server) getCategoryList(w http.ResponseWriter, r *http.Request) {
+	categoryMap := s.groupMarketsByCategory()
+
+	type CategorySummary struct {
+		Category    string `json:"category"`
+		MarketCount int    `json:"market_count"`
+		EventCount  int    `json:"event_count"`
+	}
+
+	categories := make([]CategorySummary, 0, len(categoryMap))
+	for category, events := range categoryMap {
+		marketCount := 0
+		for _, markets := range events {
+			marketCount += len(markets)
+		}
+		categories = append(categories, CategorySummary{
+			Category:    category,
+			MarketCount: marketCount,
+			EventCount:  len(events),
+		})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category < categories[j].Category
+	})
+
+	response := struct {
+		Categories []CategorySummary `json:"categories"`
+		Count      int               `json:"count"`
+		Timestamp  time.Time         `json:"timestamp"`
+	}{
+		Categories: categories,
+		Count:      len(categories),
+		Timestamp:  time.Now().UTC(),
+	}
+
+	writeJSON(w, response)
+}
+
+// getCategoryActivity counts signals and alerts from the recent buffers over a
+// window (seconds, default one hour), and the current scan's opportunities, per
+// category, most signals first
+func (s *Server) getCategoryActivity(w http.ResponseWriter, r *http.Request) {
+	window := time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		secs, err := parseInt(v)
+		if err != nil || secs <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid window")
+			return
+		}
+		window = time.Duration(secs) * time.Second
+	}
+
+	type CategoryActivity struct {
+		Category         string                     `json:"category"`
+		MarketCount      int                        `json:"market_count"`
+		SignalCount      int                        `json:"signal_count"`
+		SignalCounts     map[signals.SignalType]int `json:"signal_counts"`
+		AlertCount       int                        `json:"alert_count"`
+		OpportunityCount int                        `json:"opportunity_count"`
+		ExecutableCount  int                        `json:"executable_count"` // opportunities that can fill 100 contracts
+	}
+
+	activity := make(map[string]*CategoryActivity)
+	entry := func(category string) *CategoryActivity {
+		if a, ok := activity[category]; ok {
+			return a
+		}
+		a := &CategoryActivity{Category: category, SignalCounts: make(map[signals.SignalType]int)}
+		activity[category] = a
+		return a
+	}
+
+	for category, events := range s.groupMarketsByCategory() {
+		a := entry(category)
+		for _, markets := range events {
+			a.MarketCount += len(markets)
+		}
+	}
+
+	// Categorize each ticker once; markets that are no longer tracked fall
+	// back to their ticker alone
+	categories := make(map[string]string)
+	categoryOf := func(ticker string) string {
+		if category, ok := categories[ticker]; ok {
+			return category
+		}
+		title := ""
+		if market, ok := s.state.GetMarket(ticker); ok {
+			title = market.Title
+		}
+		category := categorizeMarket(title, ticker)
+		categories[ticker] = category
+		return category
+	}
+
+	cutoff := time.Now().Add(-window)
+	s.mu.RLock()
+	for _, sig := range s.signals {
+		if sig.Timestamp.Before(cutoff) {
+			continue
+		}
+		a := entry(categoryOf(sig.MarketTicker))
+		a.SignalCount++
+		a.SignalCounts[sig.Type]++
+	}
+	for _, alert := range s.alerts {
+		if alert.Timestamp.Before(cutoff) {
+			continue
+		}
+		entry(categoryOf(alert.MarketTicker)).AlertCount++
+	}
+	s.mu.RUnlock()
+
+	for _, opp := range s.scanner.ScanMarkets() {
+		a := entry(categorizeMarket(opp.Title, opp.MarketTicker))
+		a.OpportunityCount++
+		if opp.CanExecute100 {
+			a.ExecutableCount++
+		}
+	}
+
+	results := make([]CategoryActivity, 0, len(activity))
+	for _, a := range activity {
+		results = append(results, *a)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].SignalCount != results[j].SignalCount {
+			return results[i].SignalCount > results[j].SignalCount
+		}
+		return results[i].Category < results[j].Category
+	})
+
+	response := struct {
+		Categories []CategoryActivity `json:"categories"`
+		Count      int                `json:"count"`
+		WindowSecs int                `json:"window_secs"`
+		Timestamp  time.Time          `json:"timestamp"`
+	}{
+		Categories: results,
+		Count:      len(results),
+		WindowSecs: int(window.Seconds()),
+		Timestamp:  time.Now().UTC(),
+	}
+
+	writeJSON(w, response)
+}
+
+func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
+	categoryMap := s.groupMarketsByCategory()
 	
 	// Build response structure
 	type CategoryGroup struct {
@@ -792,11 +1696,10 @@ func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
 	}{
 		Categories: categories,
 		Count:      len(categories),
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UTC(),
 	}
 	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, response)
 }
 
 func parseInt(s string) (int, error) {
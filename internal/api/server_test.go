@@ -0,0 +1,613 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/ingestion"
+	"github.com/kalshi-signal-feed/internal/scanner"
+	"github.com/kalshi-signal-feed/internal/signals"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// errorResponse is the JSON error envelope writeError produces
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// getJSON fetches path from the test server into out, returning the status
+func getJSON(t *testing.T, url string, out interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestGetSignalsMinConfidence(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	for i, confidence := range []float64{0.2, 0.5, 0.9} {
+		s.signals = append(s.signals, signals.Signal{
+			MarketTicker: "MKT-" + string(rune('A'+i)),
+			Type:         signals.SignalTypeVolumeSurge,
+			Metadata:     signals.SignalMetadata{Confidence: confidence},
+		})
+	}
+
+	var body struct {
+		Signals []signals.Signal `json:"signals"`
+		Count   int              `json:"count"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/signals?min_confidence=0.5", &body); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if body.Count != 2 || body.Signals[0].MarketTicker != "MKT-B" || body.Signals[1].MarketTicker != "MKT-C" {
+		t.Fatalf("got %+v, want MKT-B and MKT-C", body.Signals)
+	}
+
+	var errBody errorResponse
+	if status := getJSON(t, ts.URL+"/api/v1/signals?min_confidence=high", &errBody); status != http.StatusBadRequest || errBody.Error.Code != errCodeBadRequest {
+		t.Fatalf("status = %d, error = %+v, want 400 bad_request", status, errBody.Error)
+	}
+}
+
+// addTestMarket registers an active market with a fresh two-sided book
+func addTestMarket(s *Server, ticker string) {
+	s.state.RegisterMarket(&state.Market{Ticker: ticker, Title: ticker, Status: state.StatusActive, EventTicker: "EV", Category: "Politics"})
+	ob := state.NewOrderbook(ticker)
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 200}, {Price: 44, Quantity: 300}}
+	ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 150}, {Price: 48, Quantity: 250}}
+	s.state.UpdateOrderbook(ticker, ob)
+}
+
+func TestEnginesAreLongLived(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+	scan, noArb, alertEngine := s.scanner, s.noArbEngine, s.alertEngine
+
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"/api/v1/scanner/opportunities", "/api/v1/scanner/noarb"} {
+			if status := getJSON(t, ts.URL+path, nil); status != http.StatusOK {
+				t.Fatalf("%s: status %d", path, status)
+			}
+		}
+		s.alertEngine.CheckAlerts()
+	}
+
+	if s.scanner != scan || s.noArbEngine != noArb || s.alertEngine != alertEngine {
+		t.Fatal("engines were rebuilt between requests")
+	}
+}
+
+func TestMarketOverviewPopulatesEverySection(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+	s.state.SetMarketMetadata("MKT", &state.MarketMetadata{EventTitle: "Event", RulesPrimary: "Rules"})
+	s.state.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 46, Quantity: 10, Timestamp: time.Now()})
+	s.signals = append(s.signals,
+		signals.Signal{MarketTicker: "MKT", Type: signals.SignalTypeVolumeSurge},
+		signals.Signal{MarketTicker: "OTHER", Type: signals.SignalTypeVolumeSurge})
+	s.alerts = append(s.alerts,
+		alerts.Alert{MarketTicker: "MKT", Type: alerts.AlertTypeExecutionReady},
+		alerts.Alert{MarketTicker: "OTHER", Type: alerts.AlertTypeExecutionReady})
+
+	var overview struct {
+		Market      *state.Market               `json:"market"`
+		Metadata    *state.MarketMetadata       `json:"metadata"`
+		TopOfBook   topOfBook                   `json:"top_of_book"`
+		Signals     []signals.Signal            `json:"signals"`
+		Alerts      []alerts.Alert              `json:"alerts"`
+		Opportunity *scanner.MarketOpportunity  `json:"opportunity"`
+		Quant       *signals.QuantitativeSignal `json:"quant"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets/MKT/overview", &overview); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+
+	if overview.Market == nil || overview.Market.Ticker != "MKT" {
+		t.Error("market missing")
+	}
+	if overview.Metadata == nil || overview.Metadata.RulesPrimary != "Rules" {
+		t.Error("metadata missing")
+	}
+	if overview.TopOfBook.BestBid == nil || *overview.TopOfBook.BestBid != 45 {
+		t.Errorf("top of book = %+v", overview.TopOfBook)
+	}
+	if len(overview.Signals) != 1 || len(overview.Alerts) != 1 {
+		t.Errorf("got %d signals and %d alerts, want 1 each for MKT", len(overview.Signals), len(overview.Alerts))
+	}
+	if overview.Opportunity == nil || overview.Quant == nil {
+		t.Error("opportunity or quant missing")
+	}
+}
+
+func TestGetMarketsDefaultsToActive(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	s.state.RegisterMarket(&state.Market{Ticker: "OPEN", Status: state.StatusActive})
+	s.state.RegisterMarket(&state.Market{Ticker: "SHUT", Status: state.StatusClosed})
+
+	tickers := func(query string) []string {
+		t.Helper()
+		var body struct {
+			Markets []state.Market `json:"markets"`
+			Count   int            `json:"count"`
+		}
+		if status := getJSON(t, ts.URL+"/api/v1/markets"+query, &body); status != http.StatusOK {
+			t.Fatalf("%s: status = %d", query, status)
+		}
+		var got []string
+		for _, market := range body.Markets {
+			got = append(got, market.Ticker)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "[OPEN]"},
+		{"?include_inactive=false", "[OPEN]"},
+		{"?include_inactive=true", "[OPEN SHUT]"},
+		{"?status=closed", "[SHUT]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tickers(tt.query)); got != tt.want {
+			t.Errorf("markets%s = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	var errBody errorResponse
+	if status := getJSON(t, ts.URL+"/api/v1/markets?include_inactive=maybe", &errBody); status != http.StatusBadRequest {
+		t.Errorf("invalid include_inactive: status = %d, want 400", status)
+	}
+}
+
+func TestOpportunitiesFilteredByCategory(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "SENATE-OH")
+	addTestMarket(s, "SENATE-PA")
+	addTestMarket(s, "GOVERNOR-OH")
+
+	tickers := func(query string) string {
+		t.Helper()
+		var body struct {
+			Opportunities []scanner.MarketOpportunity `json:"opportunities"`
+		}
+		if status := getJSON(t, ts.URL+"/api/v1/scanner/opportunities"+query, &body); status != http.StatusOK {
+			t.Fatalf("%s: status = %d", query, status)
+		}
+		var got []string
+		for _, opp := range body.Opportunities {
+			got = append(got, opp.MarketTicker)
+		}
+		sort.Strings(got)
+		return fmt.Sprint(got)
+	}
+
+	if got := tickers(""); got != "[GOVERNOR-OH SENATE-OH SENATE-PA]" {
+		t.Fatalf("unfiltered = %s, want all three", got)
+	}
+	senate := url.QueryEscape(categorizeMarket("SENATE-OH", "SENATE-OH"))
+	if got := tickers("?category=" + senate); got != "[SENATE-OH SENATE-PA]" {
+		t.Errorf("category %s = %s, want the senate markets", senate, got)
+	}
+	if got := tickers("?category=" + strings.ToUpper(senate)); got != "[SENATE-OH SENATE-PA]" {
+		t.Errorf("category match is case-sensitive: %s", got)
+	}
+	if got := tickers("?category=Sports"); got != "[]" {
+		t.Errorf("unknown category = %s, want none", got)
+	}
+}
+
+func TestCategoryListMatchesFullCategories(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	for _, m := range []struct{ ticker, title, event string }{
+		{"SEN-OH-D", "Ohio senate race: Democrat", "SEN-OH"},
+		{"SEN-OH-R", "Ohio senate race: Republican", "SEN-OH"},
+		{"SEN-PA-D", "Pennsylvania senate race", "SEN-PA"},
+		{"GOV-OH-D", "Ohio governor", "GOV-OH"},
+	} {
+		s.state.RegisterMarket(&state.Market{Ticker: m.ticker, Title: m.title, EventTicker: m.event, Status: state.StatusActive})
+	}
+	s.state.RegisterMarket(&state.Market{Ticker: "OLD", Title: "Old senate race", EventTicker: "SEN-OLD", Status: state.StatusClosed})
+
+	var full struct {
+		Categories []struct {
+			Category     string   `json:"category"`
+			EventTickers []string `json:"event_tickers"`
+			TotalMarkets int      `json:"total_markets"`
+		} `json:"categories"`
+	}
+	var list struct {
+		Categories []struct {
+			Category    string `json:"category"`
+			MarketCount int    `json:"market_count"`
+			EventCount  int    `json:"event_count"`
+		} `json:"categories"`
+		Count int `json:"count"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/categories", &full); status != http.StatusOK {
+		t.Fatalf("categories: status = %d", status)
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/categories/list", &list); status != http.StatusOK {
+		t.Fatalf("categories/list: status = %d", status)
+	}
+
+	if len(list.Categories) != len(full.Categories) || list.Count != len(full.Categories) {
+		t.Fatalf("list has %d categories, full has %d", len(list.Categories), len(full.Categories))
+	}
+	want := make(map[string][2]int)
+	for _, c := range full.Categories {
+		want[c.Category] = [2]int{c.TotalMarkets, len(c.EventTickers)}
+	}
+	total := 0
+	for i, c := range list.Categories {
+		if got := [2]int{c.MarketCount, c.EventCount}; got != want[c.Category] {
+			t.Errorf("%s: markets/events = %v, full endpoint has %v", c.Category, got, want[c.Category])
+		}
+		if i > 0 && list.Categories[i-1].Category >= c.Category {
+			t.Errorf("list not sorted by name at %s", c.Category)
+		}
+		total += c.MarketCount
+	}
+	if total != 4 {
+		t.Errorf("list counts %d markets, want the 4 active ones", total)
+	}
+}
+
+func TestMarketsBookOnlyWhenRequested(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+
+	var plain struct {
+		Markets []map[string]json.RawMessage `json:"markets"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets", &plain); status != http.StatusOK || len(plain.Markets) != 1 {
+		t.Fatalf("status = %d, markets = %d", status, len(plain.Markets))
+	}
+	if _, ok := plain.Markets[0]["book"]; ok {
+		t.Error("book included without include_book")
+	}
+
+	var enriched struct {
+		Markets []struct {
+			Ticker string `json:"ticker"`
+			Book   *struct {
+				BestBid    *int     `json:"best_bid"`
+				BestAsk    *int     `json:"best_ask"`
+				Mid        *float64 `json:"mid"`
+				Spread     *int     `json:"spread"`
+				Microprice *float64 `json:"microprice"`
+			} `json:"book"`
+		} `json:"markets"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets?include_book=true", &enriched); status != http.StatusOK || len(enriched.Markets) != 1 {
+		t.Fatalf("status = %d, markets = %d", status, len(enriched.Markets))
+	}
+	book := enriched.Markets[0].Book
+	if book == nil || book.BestBid == nil || book.BestAsk == nil || book.Mid == nil || book.Spread == nil || book.Microprice == nil {
+		t.Fatalf("book = %+v, want every top-of-book field", book)
+	}
+	if *book.BestBid != 45 || *book.BestAsk != 47 || *book.Mid != 46 || *book.Spread != 2 {
+		t.Errorf("book = %d/%d mid %.1f spread %d, want 45/47 mid 46 spread 2", *book.BestBid, *book.BestAsk, *book.Mid, *book.Spread)
+	}
+	if *book.Microprice <= 45 || *book.Microprice >= 47 {
+		t.Errorf("microprice = %.2f, want inside the spread", *book.Microprice)
+	}
+}
+
+func TestOrderbookTruncatedToLevels(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{OrderbookLevels: 3})
+	ob := state.NewOrderbook("DEEP")
+	for i := 0; i < 20; i++ {
+		ob.Bids = append(ob.Bids, state.PriceLevel{Price: 45 - i, Quantity: 100})
+		ob.Asks = append(ob.Asks, state.PriceLevel{Price: 47 + i, Quantity: 100})
+	}
+	s.state.UpdateOrderbook("DEEP", ob)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"?levels=5", 5},
+		{"?levels=50", 20},
+		{"?levels=0", 20},
+	}
+	for _, tt := range tests {
+		var book state.Orderbook
+		if status := getJSON(t, ts.URL+"/api/v1/markets/DEEP/orderbook"+tt.query, &book); status != http.StatusOK {
+			t.Fatalf("%q: status = %d", tt.query, status)
+		}
+		if len(book.Bids) != tt.want || len(book.Asks) != tt.want {
+			t.Errorf("%q: got %d bids and %d asks, want %d each", tt.query, len(book.Bids), len(book.Asks), tt.want)
+		}
+		if len(book.Bids) > 0 && (book.Bids[0].Price != 45 || book.Asks[0].Price != 47) {
+			t.Errorf("%q: top of book %d/%d, want 45/47", tt.query, book.Bids[0].Price, book.Asks[0].Price)
+		}
+	}
+
+	for _, bad := range []string{"-1", "ten"} {
+		var errBody errorResponse
+		if status := getJSON(t, ts.URL+"/api/v1/markets/DEEP/orderbook?levels="+bad, &errBody); status != http.StatusBadRequest {
+			t.Errorf("levels=%s: status = %d, want 400", bad, status)
+		}
+	}
+
+	// The stored book keeps every level
+	if stored, _ := s.state.GetOrderbook("DEEP"); len(stored.Bids) != 20 || len(stored.Asks) != 20 {
+		t.Errorf("stored book has %d bids and %d asks, want 20 each", len(stored.Bids), len(stored.Asks))
+	}
+}
+
+func TestOHLCEndpoint(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+	s.state.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 46, Quantity: 10, Timestamp: time.Now()})
+
+	var body struct {
+		MarketTicker string      `json:"market_ticker"`
+		IntervalSecs int         `json:"interval_secs"`
+		WindowSecs   int         `json:"window_secs"`
+		Candles      []state.Bar `json:"candles"`
+		Count        int         `json:"count"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets/MKT/ohlc?interval=5m&window=3600", &body); status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if body.IntervalSecs != 300 || body.WindowSecs != 3600 || body.Count != len(body.Candles) {
+		t.Errorf("response = %+v", body)
+	}
+	if len(body.Candles) != 1 {
+		t.Fatalf("candles = %+v, want one from the current book", body.Candles)
+	}
+	c := body.Candles[0]
+	if c.Open != 0.46 || c.Close != 0.46 || c.Volume != 10 || !c.Start.Equal(c.Start.Truncate(5*time.Minute)) {
+		t.Errorf("candle = %+v, want mid 0.46 and volume 10 on a 5m boundary", c)
+	}
+
+	for _, path := range []string{
+		"/api/v1/markets/MKT/ohlc?interval=30s",
+		"/api/v1/markets/MKT/ohlc?interval=90s",
+		"/api/v1/markets/MKT/ohlc?interval=1m&window=31536000",
+		"/api/v1/markets/MKT/ohlc?window=-1",
+	} {
+		if status := getJSON(t, ts.URL+path, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, status)
+		}
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets/MISSING/ohlc", nil); status != http.StatusNotFound {
+		t.Errorf("unknown market: status = %d, want 404", status)
+	}
+}
+
+func TestMarketDebugIncludesImbalanceProfile(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+
+	var debug struct {
+		ImbalanceProfile []state.BandImbalance `json:"imbalance_profile"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets/MKT/debug", &debug); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if len(debug.ImbalanceProfile) != 4 {
+		t.Fatalf("imbalance profile = %+v, want 1, 2, 5 and 10¢ bands", debug.ImbalanceProfile)
+	}
+	// Mid 46¢: within 1¢ that's 200 bid vs 150 ask, within 2¢ the whole book
+	if band := debug.ImbalanceProfile[0]; band.Cents != 1 || band.BidDepth != 200 || band.AskDepth != 150 {
+		t.Errorf("1¢ band = %+v, want 200 bid / 150 ask", band)
+	}
+	if band := debug.ImbalanceProfile[1]; band.Cents != 2 || band.BidDepth != 500 || band.AskDepth != 400 {
+		t.Errorf("2¢ band = %+v, want 500 bid / 400 ask", band)
+	}
+}
+
+func TestHealthReportsBreakerState(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+
+	var health struct {
+		Status  string                   `json:"status"`
+		Breaker *ingestion.BreakerStatus `json:"rest_breaker"`
+	}
+	getJSON(t, ts.URL+"/api/v1/health", &health)
+	if health.Breaker != nil {
+		t.Errorf("breaker reported without a status func: %+v", health.Breaker)
+	}
+
+	openUntil := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	s.SetBreakerStatusFunc(func() ingestion.BreakerStatus {
+		return ingestion.BreakerStatus{State: ingestion.BreakerOpen, ConsecutiveFailures: 5, OpenUntil: &openUntil}
+	})
+	getJSON(t, ts.URL+"/api/v1/health", &health)
+	if health.Breaker == nil || health.Breaker.State != ingestion.BreakerOpen || health.Breaker.ConsecutiveFailures != 5 ||
+		health.Breaker.OpenUntil == nil || !health.Breaker.OpenUntil.Equal(openUntil) {
+		t.Errorf("rest_breaker = %+v, want open after 5 failures until %v", health.Breaker, openUntil)
+	}
+}
+
+func TestMarketDebugIncludesTradeSizes(t *testing.T) {
+	s := NewServer(config.APIConfig{}, config.ScannerConfig{LargeTradeContracts: 100}, state.NewEngine(), make(chan signals.Signal))
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+	addTestMarket(s, "MKT")
+	for _, size := range []int{5, 10, 5, 250} {
+		s.state.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 46, Quantity: size, Timestamp: time.Now()})
+	}
+
+	var debug struct {
+		TradeSizes state.TradeSizeStats `json:"trade_sizes"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/markets/MKT/debug", &debug); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	want := state.TradeSizeStats{Count: 4, Mean: 67.5, Median: 7.5, P95: 250, LargeThreshold: 100, LargeTrades: 1}
+	if debug.TradeSizes != want {
+		t.Errorf("trade sizes = %+v, want %+v", debug.TradeSizes, want)
+	}
+}
+
+func TestOpportunitiesFreshOnly(t *testing.T) {
+	s := NewServer(config.APIConfig{}, config.ScannerConfig{MaxBookAgeSecs: 60, FreshOnly: true}, state.NewEngine(), make(chan signals.Signal))
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	addTestMarket(s, "FRESH")
+	s.state.RegisterMarket(&state.Market{Ticker: "STALE", Title: "STALE", Status: state.StatusActive})
+	stale := state.NewOrderbook("STALE")
+	stale.Bids = []state.PriceLevel{{Price: 45, Quantity: 200}}
+	stale.Asks = []state.PriceLevel{{Price: 47, Quantity: 150}}
+	stale.LastUpdate = time.Now().Add(-10 * time.Minute)
+	s.state.UpdateOrderbook("STALE", stale)
+
+	tickers := func(query string) map[string]bool {
+		t.Helper()
+		var body struct {
+			Opportunities []scanner.MarketOpportunity `json:"opportunities"`
+		}
+		if status := getJSON(t, ts.URL+"/api/v1/scanner/opportunities"+query, &body); status != http.StatusOK {
+			t.Fatalf("%q: status = %d", query, status)
+		}
+		got := map[string]bool{}
+		for _, opp := range body.Opportunities {
+			got[opp.MarketTicker] = opp.BookStale
+		}
+		return got
+	}
+
+	// The config default drops the stale book outright
+	for _, query := range []string{"", "?fresh_only=true"} {
+		got := tickers(query)
+		if stale, ok := got["FRESH"]; len(got) != 1 || !ok || stale {
+			t.Errorf("%q: %v, want only FRESH", query, got)
+		}
+	}
+	// Overridden per request, it's returned flagged instead
+	if got := tickers("?fresh_only=false"); len(got) != 2 || !got["STALE"] || got["FRESH"] {
+		t.Errorf("fresh_only=false: %v, want both with STALE flagged", got)
+	}
+
+	var body errorResponse
+	if status := getJSON(t, ts.URL+"/api/v1/scanner/opportunities?fresh_only=maybe", &body); status != http.StatusBadRequest {
+		t.Errorf("invalid fresh_only: status = %d, want 400", status)
+	}
+}
+
+func TestMissingBooksEndpoint(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "POPULATED")
+	s.state.RegisterMarket(&state.Market{Ticker: "NEVER", Status: state.StatusActive})
+	s.state.RegisterMarket(&state.Market{Ticker: "STALE", Status: state.StatusActive})
+	stale := state.NewOrderbook("STALE")
+	stale.Bids = []state.PriceLevel{{Price: 45, Quantity: 100}}
+	stale.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+	stale.LastUpdate = time.Now().Add(-5 * time.Minute)
+	s.state.UpdateOrderbook("STALE", stale)
+
+	type report struct {
+		Markets    []state.BookGap `json:"markets"`
+		Count      int             `json:"count"`
+		MaxAgeSecs int             `json:"max_age_secs"`
+	}
+	reasons := func(r report) map[string]string {
+		got := map[string]string{}
+		for _, gap := range r.Markets {
+			got[gap.Ticker] = gap.Reason
+		}
+		return got
+	}
+
+	var body report
+	if status := getJSON(t, ts.URL+"/api/v1/diagnostics/missing-books", &body); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	got := reasons(body)
+	if body.MaxAgeSecs != 180 || body.Count != 2 || got["NEVER"] != state.BookGapNeverFetched || got["STALE"] != state.BookGapStale {
+		t.Errorf("default report = %+v, want NEVER and STALE at 180s", body)
+	}
+
+	// A 10-minute allowance leaves only the market never fetched
+	body = report{}
+	getJSON(t, ts.URL+"/api/v1/diagnostics/missing-books?max_age=600", &body)
+	if got := reasons(body); len(got) != 1 || got["NEVER"] == "" {
+		t.Errorf("max_age=600: %v, want only NEVER", got)
+	}
+
+	if status := getJSON(t, ts.URL+"/api/v1/diagnostics/missing-books?max_age=0", nil); status != http.StatusBadRequest {
+		t.Errorf("max_age=0: status = %d, want 400", status)
+	}
+}
+
+func TestCategoryActivityAggregatesByCategory(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	for ticker, title := range map[string]string{
+		"SEN-OH": "Who will win the Senate race in Ohio?",
+		"SEN-TX": "Senate election in Texas",
+		"GOV-OH": "Who will be Governor of Ohio?",
+	} {
+		s.state.RegisterMarket(&state.Market{Ticker: ticker, Title: title, Status: state.StatusActive, EventTicker: ticker})
+	}
+	// Only SEN-OH has a two-sided book, so it's the one opportunity
+	ob := state.NewOrderbook("SEN-OH")
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 200}}
+	ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 150}}
+	s.state.UpdateOrderbook("SEN-OH", ob)
+
+	now := time.Now()
+	s.signals = append(s.signals,
+		signals.Signal{MarketTicker: "SEN-OH", Type: signals.SignalTypeOrderbookImbalance, Timestamp: now.Add(-2 * time.Hour)},
+		signals.Signal{MarketTicker: "SEN-OH", Type: signals.SignalTypeOrderbookImbalance, Timestamp: now},
+		signals.Signal{MarketTicker: "SEN-OH", Type: signals.SignalTypeOrderbookImbalance, Timestamp: now},
+		signals.Signal{MarketTicker: "SEN-TX", Type: signals.SignalTypeVolumeSurge, Timestamp: now},
+		signals.Signal{MarketTicker: "GOV-OH", Type: signals.SignalTypeOrderbookImbalance, Timestamp: now})
+	s.alerts = append(s.alerts,
+		alerts.Alert{MarketTicker: "GOV-OH", Type: alerts.AlertTypeExecutionReady, Timestamp: now})
+
+	var response struct {
+		Categories []struct {
+			Category         string                     `json:"category"`
+			MarketCount      int                        `json:"market_count"`
+			SignalCount      int                        `json:"signal_count"`
+			SignalCounts     map[signals.SignalType]int `json:"signal_counts"`
+			AlertCount       int                        `json:"alert_count"`
+			OpportunityCount int                        `json:"opportunity_count"`
+		} `json:"categories"`
+		WindowSecs int `json:"window_secs"`
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/categories/activity", &response); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if response.WindowSecs != 3600 || len(response.Categories) != 2 {
+		t.Fatalf("window %ds, %d categories; want 3600s and 2", response.WindowSecs, len(response.Categories))
+	}
+
+	// The busiest category comes first; the 2h-old signal is outside the window
+	senate, governor := response.Categories[0], response.Categories[1]
+	if senate.Category != "Elections - Senate" || senate.MarketCount != 2 || senate.SignalCount != 3 ||
+		senate.SignalCounts[signals.SignalTypeOrderbookImbalance] != 2 || senate.SignalCounts[signals.SignalTypeVolumeSurge] != 1 ||
+		senate.AlertCount != 0 || senate.OpportunityCount != 1 {
+		t.Errorf("senate = %+v", senate)
+	}
+	if governor.Category != "Elections - Governor" || governor.MarketCount != 1 || governor.SignalCount != 1 ||
+		governor.AlertCount != 1 || governor.OpportunityCount != 0 {
+		t.Errorf("governor = %+v", governor)
+	}
+}
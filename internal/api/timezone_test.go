@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+// withLocalZone runs the test as if the host's TZ were loc
+func withLocalZone(t *testing.T, loc *time.Location) {
+	t.Helper()
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func TestTimestampsAreUTCUnderNonUTCZone(t *testing.T) {
+	withLocalZone(t, time.FixedZone("EST", -5*60*60))
+	s, ts := newTestServer(t, config.APIConfig{})
+	addTestMarket(s, "MKT")
+
+	for path, field := range map[string]string{
+		"/api/v1/health":                    "timestamp",
+		"/api/v1/diagnostics/missing-books": "timestamp",
+		"/api/v1/markets/MKT/orderbook":     "last_update",
+	} {
+		var body map[string]interface{}
+		if code := getJSON(t, ts.URL+path, &body); code != 200 {
+			t.Fatalf("%s: status = %d", path, code)
+		}
+		raw, _ := body[field].(string)
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			t.Errorf("%s: %s = %q is not RFC3339: %v", path, field, raw, err)
+			continue
+		}
+		if !strings.HasSuffix(raw, "Z") || parsed.Location() != time.UTC {
+			t.Errorf("%s: %s = %q, want UTC", path, field, raw)
+		}
+	}
+}
+
+func TestSavedBuffersStampedInUTC(t *testing.T) {
+	withLocalZone(t, time.FixedZone("EST", -5*60*60))
+	path := filepath.Join(t.TempDir(), "buffers.json")
+	s, _ := newTestServer(t, config.APIConfig{BufferStatePath: path, PersistedBufferSize: 10})
+	if err := s.saveBuffers(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		SavedAt string `json:"saved_at"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(saved.SavedAt, "Z") {
+		t.Errorf("saved_at = %q, want UTC", saved.SavedAt)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// loadTLSConfig loads the configured certificate and key, returning nil when
+// TLS isn't configured
+func (s *Server) loadTLSConfig() (*tls.Config, error) {
+	certFile, keyFile := s.config.TLSCertFile, s.config.TLSKeyFile
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS needs both tls_cert_file and tls_key_file")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		// HTTP/1.1 only: the WebSocket endpoint needs connection upgrades
+		NextProtos: []string{"http/1.1"},
+	}, nil
+}
+
+// redirectToHTTPS answers plain HTTP requests with a permanent redirect to the
+// same path on the HTTPS port
+func (s *Server) redirectToHTTPS() http.Handler {
+	_, httpsPort, _ := net.SplitHostPort(s.config.BindAddress)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// writeSelfSignedCert writes a localhost certificate and key to dir and
+// returns their paths along with a pool that trusts the certificate
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestServesHTTPSWithSelfSignedCert(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t, t.TempDir())
+	s := NewServer(config.APIConfig{
+		BindAddress:         "127.0.0.1:0",
+		TLSCertFile:         certFile,
+		TLSKeyFile:          keyFile,
+		HTTPRedirectAddress: "127.0.0.1:0",
+	}, config.ScannerConfig{}, state.NewEngine(), make(chan signals.Signal))
+	if err := s.Listen(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := &http.Server{Handler: s.routes()}
+	go server.Serve(s.listener)
+	defer server.Close()
+	redirect := &http.Server{Handler: s.redirectToHTTPS()}
+	go redirect.Serve(s.redirectListener)
+	defer redirect.Close()
+
+	httpsURL := "https://" + s.listener.Addr().String() + "/api/v1/health"
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	resp, err := client.Get(httpsURL)
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("HTTPS /health status = %d, want 200", resp.StatusCode)
+	}
+	if resp.TLS == nil {
+		t.Error("response wasn't served over TLS")
+	}
+
+	// Plain HTTP on the TLS port is refused
+	if resp, err := http.Get("http://" + s.listener.Addr().String() + "/api/v1/health"); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("plain HTTP served on the TLS port")
+		}
+	}
+
+	// Plain HTTP on the redirect port points at the HTTPS port
+	noFollow := &http.Client{
+		Timeout:       5 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err = noFollow.Get("http://" + s.redirectListener.Addr().String() + "/api/v1/health?x=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		t.Errorf("redirect status = %d, want 308", resp.StatusCode)
+	}
+	if loc := resp.Header.Get("Location"); !strings.HasPrefix(loc, "https://") || !strings.HasSuffix(loc, "/api/v1/health?x=1") {
+		t.Errorf("redirect Location = %q", loc)
+	}
+}
+
+func TestListenRejectsHalfConfiguredTLS(t *testing.T) {
+	certFile, _, _ := writeSelfSignedCert(t, t.TempDir())
+	s := NewServer(config.APIConfig{BindAddress: "127.0.0.1:0", TLSCertFile: certFile},
+		config.ScannerConfig{}, state.NewEngine(), make(chan signals.Signal))
+	if err := s.Listen(); err == nil {
+		s.listener.Close()
+		t.Fatal("Listen accepted a certificate without a key")
+	}
+}
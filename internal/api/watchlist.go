@@ -0,0 +1,392 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// maxWatchlistMarkets bounds the size of one watchlist
+const maxWatchlistMarkets = 500
+
+var watchlistNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// watchlistStore holds named sets of market tickers. Every change is written
+// to path (when set) so watchlists survive restarts.
+type watchlistStore struct {
+	mu    sync.RWMutex
+	path  string
+	lists map[string][]string // name -> sorted, de-duplicated tickers
+}
+
+func newWatchlistStore(path string) *watchlistStore {
+	return &watchlistStore{
+		path:  path,
+		lists: make(map[string][]string),
+	}
+}
+
+// load reads the saved watchlists; a missing file is not an error
+func (ws *watchlistStore) load() error {
+	if ws.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(ws.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read watchlists: %w", err)
+	}
+
+	var lists map[string][]string
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return fmt.Errorf("failed to parse watchlists: %w", err)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for name, markets := range lists {
+		ws.lists[name] = normalizeTickers(markets)
+	}
+	return nil
+}
+
+// get returns a copy of a watchlist's markets
+func (ws *watchlistStore) get(name string) ([]string, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	markets, exists := ws.lists[name]
+	if !exists {
+		return nil, false
+	}
+	return append([]string(nil), markets...), true
+}
+
+// names returns every watchlist name in sorted order
+func (ws *watchlistStore) names() []string {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	names := make([]string, 0, len(ws.lists))
+	for name := range ws.lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contains reports whether ticker is on the watchlist, and whether the
+// watchlist still exists
+func (ws *watchlistStore) contains(name, ticker string) (member, exists bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	markets, exists := ws.lists[name]
+	if !exists {
+		return false, false
+	}
+	i := sort.SearchStrings(markets, ticker)
+	return i < len(markets) && markets[i] == ticker, true
+}
+
+// put creates or replaces a watchlist and returns its stored markets. If the
+// change can't be saved it is undone, so memory never runs ahead of disk.
+func (ws *watchlistStore) put(name string, markets []string) ([]string, error) {
+	markets = normalizeTickers(markets)
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	previous, existed := ws.lists[name]
+	ws.lists[name] = markets
+	if err := ws.saveLocked(); err != nil {
+		if existed {
+			ws.lists[name] = previous
+		} else {
+			delete(ws.lists, name)
+		}
+		return nil, err
+	}
+	return append([]string(nil), markets...), nil
+}
+
+// remove deletes a watchlist, reporting false if it didn't exist
+func (ws *watchlistStore) remove(name string) (bool, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	previous, exists := ws.lists[name]
+	if !exists {
+		return false, nil
+	}
+	delete(ws.lists, name)
+	if err := ws.saveLocked(); err != nil {
+		ws.lists[name] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+func (ws *watchlistStore) saveLocked() error {
+	if ws.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(ws.lists)
+	if err != nil {
+		return fmt.Errorf("failed to marshal watchlists: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash can't leave a truncated file
+	tmp := ws.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watchlists: %w", err)
+	}
+	return os.Rename(tmp, ws.path)
+}
+
+// normalizeTickers trims, de-duplicates and sorts tickers, dropping blanks
+func normalizeTickers(tickers []string) []string {
+	seen := make(map[string]bool, len(tickers))
+	out := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.TrimSpace(ticker)
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		out = append(out, ticker)
+	}
+	sort.Strings(out)
+	return out
+}
+
+type watchlistResponse struct {
+	Name    string   `json:"name"`
+	Markets []string `json:"markets"`
+	Count   int      `json:"count"`
+}
+
+func (s *Server) getWatchlists(w http.ResponseWriter, r *http.Request) {
+	lists := make([]watchlistResponse, 0)
+	for _, name := range s.watchlists.names() {
+		if markets, exists := s.watchlists.get(name); exists {
+			lists = append(lists, watchlistResponse{Name: name, Markets: markets, Count: len(markets)})
+		}
+	}
+
+	response := struct {
+		Watchlists []watchlistResponse `json:"watchlists"`
+		Count      int                 `json:"count"`
+	}{
+		Watchlists: lists,
+		Count:      len(lists),
+	}
+
+	writeJSON(w, response)
+}
+
+// putWatchlist creates or replaces a watchlist from {"markets": [...]}
+func (s *Server) putWatchlist(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !watchlistNamePattern.MatchString(name) {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid watchlist name: use up to 64 letters, digits, '-' or '_'")
+		return
+	}
+
+	var req struct {
+		Markets []string `json:"markets"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "Invalid JSON body")
+		return
+	}
+	// Limits apply to the distinct tickers, so repeats don't count against them
+	requested := normalizeTickers(req.Markets)
+	if len(requested) == 0 {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "markets must list at least one ticker")
+		return
+	}
+	if len(requested) > maxWatchlistMarkets {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, fmt.Sprintf("A watchlist holds at most %d markets", maxWatchlistMarkets))
+		return
+	}
+
+	markets, err := s.watchlists.put(name, requested)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errCodeInternal, fmt.Sprintf("Failed to save watchlist: %v", err))
+		return
+	}
+
+	writeJSON(w, watchlistResponse{Name: name, Markets: markets, Count: len(markets)})
+}
+
+func (s *Server) deleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	removed, err := s.watchlists.remove(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errCodeInternal, fmt.Sprintf("Failed to save watchlists: %v", err))
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Watchlist not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// watchlistMarket is one member's state in a watchlist summary. Tickers that
+// aren't tracked (yet) are listed with Tracked false.
+type watchlistMarket struct {
+	Ticker              string     `json:"ticker"`
+	Tracked             bool       `json:"tracked"`
+	Title               string     `json:"title,omitempty"`
+	Status              string     `json:"status,omitempty"`
+	Book                *topOfBook `json:"book,omitempty"`
+	SignalCount         int        `json:"signal_count"`
+	AlertCount          int        `json:"alert_count"`
+	LastSignalTimestamp *time.Time `json:"last_signal_timestamp,omitempty"`
+}
+
+// getWatchlist returns a watchlist's members with their top of book and
+// signal/alert counts from the recent buffers, plus totals across members
+func (s *Server) getWatchlist(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	tickers, exists := s.watchlists.get(name)
+	if !exists {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Watchlist not found")
+		return
+	}
+
+	markets := make([]watchlistMarket, len(tickers))
+	index := make(map[string]int, len(tickers))
+	for i, ticker := range tickers {
+		index[ticker] = i
+		markets[i].Ticker = ticker
+		if market, ok := s.state.GetMarket(ticker); ok {
+			markets[i].Tracked = true
+			markets[i].Title = market.Title
+			markets[i].Status = string(market.Status)
+		}
+		if orderbook, ok := s.state.GetOrderbook(ticker); ok {
+			top := newTopOfBook(orderbook)
+			markets[i].Book = &top
+		}
+	}
+
+	signalCounts := make(map[signals.SignalType]int)
+	var signalTotal, alertTotal int
+
+	s.mu.RLock()
+	for _, sig := range s.signals {
+		i, member := index[sig.MarketTicker]
+		if !member {
+			continue
+		}
+		markets[i].SignalCount++
+		if last := markets[i].LastSignalTimestamp; last == nil || sig.Timestamp.After(*last) {
+			ts := sig.Timestamp
+			markets[i].LastSignalTimestamp = &ts
+		}
+		signalCounts[sig.Type]++
+		signalTotal++
+	}
+	for _, alert := range s.alerts {
+		if i, member := index[alert.MarketTicker]; member {
+			markets[i].AlertCount++
+			alertTotal++
+		}
+	}
+	s.mu.RUnlock()
+
+	response := struct {
+		Name         string                     `json:"name"`
+		Markets      []watchlistMarket          `json:"markets"`
+		Count        int                        `json:"count"`
+		SignalCount  int                        `json:"signal_count"`
+		SignalCounts map[signals.SignalType]int `json:"signal_counts"`
+		AlertCount   int                        `json:"alert_count"`
+		Timestamp    time.Time                  `json:"timestamp"`
+	}{
+		Name:         name,
+		Markets:      markets,
+		Count:        len(markets),
+		SignalCount:  signalTotal,
+		SignalCounts: signalCounts,
+		AlertCount:   alertTotal,
+		Timestamp:    time.Now().UTC(),
+	}
+
+	writeJSON(w, response)
+}
+
+// streamWatchlist streams the signals and alerts of a watchlist's markets as
+// Server-Sent Events, each a {"kind": ..., "data": ...} object as on the
+// WebSocket. Membership is checked per event, so edits to the watchlist apply
+// to open streams; deleting it ends them at the next event.
+func (s *Server) streamWatchlist(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, exists := s.watchlists.get(name); !exists {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "Watchlist not found")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errCodeInternal, "Streaming not supported")
+		return
+	}
+
+	if !s.acquireStream() {
+		writeError(w, http.StatusServiceUnavailable, errCodeUnavailable, "Too many stream clients")
+		return
+	}
+	defer s.releaseStream()
+
+	events := s.subscribe()
+	defer s.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			member, exists := s.watchlists.contains(name, event.marketTicker)
+			if !exists {
+				return
+			}
+			if !member {
+				continue
+			}
+			data, err := marshalJSON(event)
+			if err != nil {
+				requestLogf(r, "Failed to encode watchlist event: %v\n", err)
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
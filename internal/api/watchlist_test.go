@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+// putWatchlistRequest PUTs markets to a watchlist and returns the status
+func putWatchlistRequest(t *testing.T, url string, markets []string) int {
+	t.Helper()
+	body, err := json.Marshal(map[string][]string{"markets": markets})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWatchlistStreamCarriesOnlyMembers(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	if status := putWatchlistRequest(t, ts.URL+"/api/v1/watchlists/mine", []string{"MKT-A", "MKT-B"}); status != http.StatusOK {
+		t.Fatalf("PUT watchlist: status %d", status)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/watchlists/mine/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stream: status %d", resp.StatusCode)
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.Contains(line, "connected") {
+		t.Fatalf("greeting = %q", line)
+	}
+	waitForSubscribers(t, s, 1)
+
+	s.publishSignal(signals.Signal{MarketTicker: "OTHER", Type: signals.SignalTypeVolumeSurge})
+	s.publishSignal(signals.Signal{MarketTicker: "MKT-A", Type: signals.SignalTypeVolumeSurge})
+	s.publishAlert(alerts.Alert{MarketTicker: "OTHER", Type: alerts.AlertTypeExecutionReady})
+	s.publishAlert(alerts.Alert{MarketTicker: "MKT-B", Type: alerts.AlertTypeExecutionReady})
+
+	type event struct {
+		Kind string `json:"kind"`
+		Data struct {
+			MarketTicker string `json:"market_ticker"`
+		} `json:"data"`
+	}
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			if strings.HasPrefix(line, "data: ") {
+				lines <- strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	want := []string{"signal MKT-A", "alert MKT-B"}
+	for _, expected := range want {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream closed early")
+			}
+			var got event
+			if err := json.Unmarshal([]byte(line), &got); err != nil {
+				t.Fatalf("event %q: %v", line, err)
+			}
+			if desc := got.Kind + " " + got.Data.MarketTicker; desc != expected {
+				t.Fatalf("event = %s, want %s", desc, expected)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", expected)
+		}
+	}
+}
+
+func TestWatchlistSaveFailureRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	ws := newWatchlistStore(filepath.Join(dir, "watchlists.json"))
+	if _, err := ws.put("mine", []string{"MKT-A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Point the store at a directory that doesn't exist so every save fails
+	ws.path = filepath.Join(dir, "missing", "watchlists.json")
+
+	if _, err := ws.put("mine", []string{"MKT-B"}); err == nil {
+		t.Fatal("replace: want a save error")
+	}
+	if markets, _ := ws.get("mine"); len(markets) != 1 || markets[0] != "MKT-A" {
+		t.Errorf("after failed replace: %v, want [MKT-A]", markets)
+	}
+
+	if _, err := ws.put("new", []string{"MKT-C"}); err == nil {
+		t.Fatal("create: want a save error")
+	}
+	if _, exists := ws.get("new"); exists {
+		t.Error("failed create left the watchlist in memory")
+	}
+
+	if removed, err := ws.remove("mine"); err == nil || removed {
+		t.Fatalf("remove = %v, %v; want a save error", removed, err)
+	}
+	if _, exists := ws.get("mine"); !exists {
+		t.Error("failed remove dropped the watchlist from memory")
+	}
+}
+
+func TestWatchlistLimitCountsDistinctTickers(t *testing.T) {
+	_, ts := newTestServer(t, config.APIConfig{})
+	url := ts.URL + "/api/v1/watchlists/big"
+
+	// Repeats of a full list collapse to maxWatchlistMarkets distinct tickers
+	var repeated []string
+	for i := 0; i < maxWatchlistMarkets; i++ {
+		ticker := fmt.Sprintf("MKT-%d", i)
+		repeated = append(repeated, ticker, ticker)
+	}
+	if status := putWatchlistRequest(t, url, repeated); status != http.StatusOK {
+		t.Errorf("%d tickers with repeats: status %d, want 200", len(repeated), status)
+	}
+
+	distinct := append(repeated[:0:0], repeated...)
+	distinct = append(distinct, "MKT-EXTRA")
+	if status := putWatchlistRequest(t, url, distinct); status != http.StatusBadRequest {
+		t.Errorf("%d distinct tickers: status %d, want 400", maxWatchlistMarkets+1, status)
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kalshi-signal-feed/internal/alerts"
+	"github.com/kalshi-signal-feed/internal/signals"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = 30 * time.Second
+)
+
+// streamEvent is a signal or alert fanned out to live subscribers
+type streamEvent struct {
+	Kind         string      `json:"kind"` // "signal" or "alert"
+	Data         interface{} `json:"data"`
+	marketTicker string
+	eventType    string
+}
+
+// wsSubscription is the message a client sends to narrow what it receives.
+// Empty lists mean "everything"; alerts are only sent when Alerts is true.
+// Watchlist adds that watchlist's markets, as of the subscribe message, to
+// MarketTickers.
+type wsSubscription struct {
+	Action        string   `json:"action"` // "subscribe"
+	MarketTickers []string `json:"market_tickers"`
+	Watchlist     string   `json:"watchlist,omitempty"`
+	Types         []string `json:"types"`
+	Alerts        bool     `json:"alerts"`
+}
+
+type wsFilter struct {
+	mu      sync.RWMutex
+	markets map[string]bool
+	types   map[string]bool
+	alerts  bool
+}
+
+func (f *wsFilter) update(sub wsSubscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.markets = toSet(sub.MarketTickers)
+	f.types = toSet(sub.Types)
+	f.alerts = sub.Alerts
+}
+
+func (f *wsFilter) matches(event streamEvent) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if event.Kind == "alert" && !f.alerts {
+		return false
+	}
+	if len(f.markets) > 0 && !f.markets[event.marketTicker] {
+		return false
+	}
+	if len(f.types) > 0 && !f.types[event.eventType] {
+		return false
+	}
+	return true
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// subscribe registers a channel that receives every published stream event
+func (s *Server) subscribe() chan streamEvent {
+	ch := make(chan streamEvent, 100)
+	s.subMu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subMu.Unlock()
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan streamEvent) {
+	s.subMu.Lock()
+	delete(s.subscribers, ch)
+	s.subMu.Unlock()
+}
+
+// acquireStream reserves a slot for a streaming client, reporting false when
+// MaxStreamClients are already connected. Callers release it on disconnect.
+func (s *Server) acquireStream() bool {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.config.MaxStreamClients > 0 && s.streams >= s.config.MaxStreamClients {
+		return false
+	}
+	s.streams++
+	return true
+}
+
+func (s *Server) releaseStream() {
+	s.subMu.Lock()
+	s.streams--
+	s.subMu.Unlock()
+}
+
+// publish fans an event out to subscribers, dropping it for any that are backed up
+func (s *Server) publish(event streamEvent) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber too slow, skip
+		}
+	}
+}
+
+func (s *Server) publishSignal(sig signals.Signal) {
+	s.publish(streamEvent{
+		Kind:         "signal",
+		Data:         sig,
+		marketTicker: sig.MarketTicker,
+		eventType:    string(sig.Type),
+	})
+}
+
+func (s *Server) publishAlert(alert alerts.Alert) {
+	s.publish(streamEvent{
+		Kind:         "alert",
+		Data:         alert,
+		marketTicker: alert.MarketTicker,
+		eventType:    string(alert.Type),
+	})
+}
+
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.config.CORSOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// streamSignalsWS pushes signals (and alerts, if subscribed) as JSON frames
+func (s *Server) streamSignalsWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.checkOrigin,
+	}
+
+	// Refuse before upgrading so the client gets a plain 503
+	if !s.acquireStream() {
+		writeError(w, http.StatusServiceUnavailable, errCodeUnavailable, "Too many stream clients")
+		return
+	}
+	defer s.releaseStream()
+
+	// The upgrade writes its own response, so carry the request ID over to it
+	conn, err := upgrader.Upgrade(w, r, http.Header{requestIDHeader: w.Header().Values(requestIDHeader)})
+	if err != nil {
+		// Upgrade already wrote an HTTP error response
+		return
+	}
+	defer conn.Close()
+
+	events := s.subscribe()
+	defer s.unsubscribe(events)
+
+	filter := &wsFilter{}
+	done := make(chan struct{})
+
+	// Reader: handles subscription messages and pongs, exits on disconnect
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(4096)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var sub wsSubscription
+			if err := json.Unmarshal(message, &sub); err != nil {
+				requestLogf(r, "Invalid WebSocket subscription: %v\n", err)
+				continue
+			}
+			if sub.Action == "subscribe" {
+				if sub.Watchlist != "" {
+					members, exists := s.watchlists.get(sub.Watchlist)
+					if !exists {
+						requestLogf(r, "WebSocket subscription to unknown watchlist %q\n", sub.Watchlist)
+						continue
+					}
+					sub.MarketTickers = append(sub.MarketTickers, members...)
+				}
+				filter.update(sub)
+			}
+		}
+	}()
+
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := conn.WriteJSON(map[string]string{"type": "connected"}); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-done:
+			return
+		case event := <-events:
+			if !filter.matches(event) {
+				continue
+			}
+			data, err := marshalJSON(event)
+			if err != nil {
+				requestLogf(r, "Failed to encode WebSocket event: %v\n", err)
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
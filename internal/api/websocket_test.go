@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/signals"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func newTestServer(t *testing.T, cfg config.APIConfig) (*Server, *httptest.Server) {
+	t.Helper()
+	s := NewServer(cfg, config.ScannerConfig{}, state.NewEngine(), make(chan signals.Signal))
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// dialWS connects to the signal WebSocket and reads the greeting
+func dialWS(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws/signals"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var greeting map[string]string
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&greeting); err != nil || greeting["type"] != "connected" {
+		t.Fatalf("greeting = %v, %v", greeting, err)
+	}
+	return conn
+}
+
+// waitForSubscribers blocks until n stream subscribers are registered, so a
+// publish isn't lost to a client that hasn't subscribed yet
+func waitForSubscribers(t *testing.T, s *Server, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.subMu.RLock()
+		count := len(s.subscribers)
+		s.subMu.RUnlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("fewer than %d subscribers", n)
+}
+
+func TestWebSocketReceivesPublishedSignal(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{})
+	conn := dialWS(t, ts)
+	waitForSubscribers(t, s, 1)
+
+	s.publishSignal(signals.Signal{MarketTicker: "MKT-A", Type: signals.SignalTypeVolumeSurge, Value: 4})
+
+	var event struct {
+		Kind string         `json:"kind"`
+		Data signals.Signal `json:"data"`
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Kind != "signal" || event.Data.MarketTicker != "MKT-A" || event.Data.Type != signals.SignalTypeVolumeSurge {
+		t.Fatalf("got %+v", event)
+	}
+}
+
+// streamCount is the number of stream slots currently held
+func streamCount(s *Server) int {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	return s.streams
+}
+
+func TestStreamClientLimit(t *testing.T) {
+	s, ts := newTestServer(t, config.APIConfig{MaxStreamClients: 2})
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws/signals"
+
+	// One WebSocket and one SSE client fill the limit between them
+	conn := dialWS(t, ts)
+	sse, err := http.Get(ts.URL + "/api/v1/stream/signals")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sse.Body.Close()
+	if sse.StatusCode != http.StatusOK {
+		t.Fatalf("SSE under the limit: status %d", sse.StatusCode)
+	}
+	if line, _ := bufio.NewReader(sse.Body).ReadString('\n'); !strings.Contains(line, "connected") {
+		t.Fatalf("SSE greeting = %q", line)
+	}
+	if n := streamCount(s); n != 2 {
+		t.Fatalf("%d stream slots held, want 2", n)
+	}
+
+	// Past the limit, both kinds are refused with 503
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("WebSocket past the limit: err %v, want a 503 handshake", err)
+	}
+	if status := getJSON(t, ts.URL+"/api/v1/stream/signals", nil); status != http.StatusServiceUnavailable {
+		t.Errorf("SSE past the limit: status %d, want 503", status)
+	}
+
+	// A disconnect frees its slot for the next client
+	conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for streamCount(s) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d stream slots held after a disconnect, want 1", streamCount(s))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	dialWS(t, ts)
+}
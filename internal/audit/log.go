@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+// Entry is a single line in the audit log
+type Entry struct {
+	Kind      string      `json:"kind"` // "signal", "signal_dropped" or "alert"
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Log is an append-only JSON-lines writer for emitted signals and alerts.
+// Writes are queued on a buffered channel so callers on the hot path never block;
+// if the queue is full the entry is dropped and counted.
+type Log struct {
+	path        string
+	maxSize     int64
+	rotateDaily bool
+
+	entries chan Entry
+	file    *os.File
+	writer  *bufio.Writer
+	size    int64
+	day     string
+
+	mu      sync.Mutex
+	dropped int64
+}
+
+func NewLog(cfg config.AuditConfig) (*Log, error) {
+	l := &Log{
+		path:        cfg.Path,
+		maxSize:     int64(cfg.MaxSizeMB) * 1024 * 1024,
+		rotateDaily: cfg.RotateDaily,
+		entries:     make(chan Entry, 1000),
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Write queues an item for the audit log. Safe to call on a nil Log.
+func (l *Log) Write(kind string, data interface{}) {
+	if l == nil {
+		return
+	}
+
+	select {
+	case l.entries <- Entry{Kind: kind, Timestamp: time.Now().UTC(), Data: data}:
+	default:
+		// Queue full, drop rather than block the caller
+		l.mu.Lock()
+		l.dropped++
+		l.mu.Unlock()
+	}
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+func (l *Log) Dropped() int64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+
+// Run drains queued entries to disk until the context is cancelled
+func (l *Log) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(1 * time.Second)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Drain whatever is left before closing
+			for {
+				select {
+				case entry := <-l.entries:
+					l.writeEntry(entry)
+				default:
+					return l.close()
+				}
+			}
+		case entry := <-l.entries:
+			l.writeEntry(entry)
+		case <-flushTicker.C:
+			if err := l.writer.Flush(); err != nil {
+				fmt.Printf("Audit log flush error: %v\n", err)
+			}
+		}
+	}
+}
+
+func (l *Log) writeEntry(entry Entry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("Audit log marshal error: %v\n", err)
+		return
+	}
+	line = append(line, '\n')
+
+	if l.shouldRotate(entry.Timestamp, int64(len(line))) {
+		if err := l.rotate(); err != nil {
+			fmt.Printf("Audit log rotate error: %v\n", err)
+			return
+		}
+	}
+
+	n, err := l.writer.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		fmt.Printf("Audit log write error: %v\n", err)
+	}
+}
+
+func (l *Log) shouldRotate(now time.Time, next int64) bool {
+	if l.maxSize > 0 && l.size > 0 && l.size+next > l.maxSize {
+		return true
+	}
+	if l.rotateDaily {
+		if day := now.UTC().Format("2006-01-02"); day != l.day {
+			if l.size > 0 {
+				return true
+			}
+			// Nothing written yet today: keep the empty file for the new day
+			l.day = day
+		}
+	}
+	return false
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+	l.size = info.Size()
+	l.day = time.Now().UTC().Format("2006-01-02")
+	return nil
+}
+
+// rotate moves the current file aside with a timestamp suffix and opens a fresh one
+func (l *Log) rotate() error {
+	if err := l.close(); err != nil {
+		return err
+	}
+
+	rotated, err := rotatedPath(l.path, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return l.open()
+}
+
+// rotatedPath names a rotated file after the given time to the nanosecond,
+// adding a counter if that name is somehow taken, so rotations never overwrite
+// each other
+func rotatedPath(path string, now time.Time) (string, error) {
+	base := path + "." + now.UTC().Format("20060102150405.000000000")
+	rotated := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			return rotated, nil
+		} else if err != nil {
+			return "", fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+		rotated = fmt.Sprintf("%s.%d", base, i)
+	}
+}
+
+func (l *Log) close() error {
+	if err := l.writer.Flush(); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to flush audit log: %w", err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+)
+
+func TestRotateWithinOneSecondKeepsBothFiles(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLog(config.AuditConfig{Path: filepath.Join(dir, "audit.jsonl")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+
+	for i := 0; i < 3; i++ {
+		l.writeEntry(Entry{Kind: "signal", Timestamp: time.Now(), Data: i})
+		if err := l.rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three rotated files plus the fresh current one
+	if len(files) != 4 {
+		t.Fatalf("got %d files, want 4", len(files))
+	}
+}
+
+func TestRotatedPathAvoidsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	now := time.Now()
+
+	first, err := rotatedPath(path, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(first, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := rotatedPath(path, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Fatalf("rotatedPath reused %s", first)
+	}
+}
+
+func TestDailyRolloverOfEmptyFileAdvancesDay(t *testing.T) {
+	l, err := NewLog(config.AuditConfig{Path: filepath.Join(t.TempDir(), "audit.jsonl"), RotateDaily: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+
+	tomorrow := time.Now().UTC().Add(24 * time.Hour)
+	if l.shouldRotate(tomorrow, 10) {
+		t.Fatal("empty file should not rotate")
+	}
+	if want := tomorrow.Format("2006-01-02"); l.day != want {
+		t.Fatalf("day = %s, want %s", l.day, want)
+	}
+
+	// Once written to, the file belongs to the new day and doesn't rotate
+	// again until the day after
+	l.size = 10
+	if l.shouldRotate(tomorrow, 10) {
+		t.Fatal("file rotated twice on the same day")
+	}
+	if !l.shouldRotate(tomorrow.Add(24*time.Hour), 10) {
+		t.Fatal("file did not rotate on the next day")
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -16,77 +17,319 @@ type Config struct {
 	Signals   SignalConfig
 	API       APIConfig
 	Alerting  AlertingConfig
+	Audit     AuditConfig
+	Scanner   ScannerConfig
 }
 
 type KalshiConfig struct {
-	APIBaseURL      string
-	WebSocketURL    string
+	Environment     string // "prod" or "demo"; picks the default URLs
+	APIBaseURL      string // "" uses the environment's default
+	WebSocketURL    string // "" uses the environment's default
 	APIKeyID        string
 	PrivateKeyPath  string
 }
 
 type IngestionConfig struct {
 	WebSocketReconnectDelaySecs int
-	RESTPollIntervalSecs        int
+	RESTPollIntervalSecs        int // orderbook poll interval
+	QuietPollCycles             int // quiet markets are polled every Nth orderbook cycle (1 polls every cycle)
+	MarketRefreshIntervalSecs   int // wait between full market list refreshes
 	RateLimitPerSecond           int
+	RateLimitBurst               int // requests allowed back to back; 1 spaces every request evenly
+	RateLimitJitterMs            int // random extra delay up to this long after each rate-limit wait
+	PinnedMarketsPath            string
+	MarketRetentionHours         int     // markets inactive this long are forgotten, unless pinned (0 keeps every market)
+	MaxLevelParseFailureRatio    float64 // orderbook updates with a larger share of unparseable levels are rejected
+	BreakerFailureThreshold      int     // consecutive REST failures that open the circuit breaker (0 disables)
+	BreakerCooldownSecs          int     // REST calls are skipped this long before a probe is let through
+	RequestTimeoutSecs           int     // a REST request (including reading its body) that takes longer fails
+
+	// Debugging aid, environment only: log every REST request and response body
+	LogHTTPBodies   bool
+	LogHTTPMaxBytes int // bodies are truncated to this many bytes in the log
 }
 
 type SignalConfig struct {
 	ComputationIntervalSecs int
 	DriftWindowSecs         int
 	DriftThreshold           float64
+	DriftBaseline            string // "trades" (trade prices) or "snapshots" (rolling window of earlier book reference prices)
+	ReferencePrice           string // single fair price for drift: "mid", "microprice" or "last" (trade)
 	ImbalanceThreshold      float64
 	VolumeSurgeThreshold    float64
 	VolumeWindowSecs         int
+	VolumeBaselineMultiplier int // baseline window length in multiples of VolumeWindowSecs
+	MinTradeSamples          int // volume (and trade-baseline drift) signals need at least this many trades
+	WarmupMinSnapshots       int // no signals for a new market until it has received this many books (unchanged ones included)...
+	WarmupSecs               int // ...and has been tracked this long
+	StaggerSlots             int // spread each interval's work across this many slots (1 = all at once)
+	EventDebounceMs          int // minimum gap between update-driven evaluations of one market
+	ScoreSaturationRatio     float64 // value/threshold ratio at which a signal's normalized score reaches 1
+	WithdrawalDepthThreshold int     // contracts pulled from the book that rank a liquidity withdrawal like a threshold crossing
+	FlickerThreshold         float64 // top-of-book changes per second that flag unstable quoting
+	FlickerWindowSecs        int     // window the flicker rate is measured over
+	QuantIntervalSecs        int     // quant metrics are computed on this slower schedule
+	QuantMinActivity         float64 // ...only for markets with at least this decayed trade rate (trades per minute)
 }
 
 type APIConfig struct {
 	BindAddress string
 	CORSOrigins []string
+	OrderbookLevels int // levels per side returned by the orderbook endpoint unless ?levels= is given (0 = all)
+	MaxStreamClients int // concurrent SSE and WebSocket streams; more are refused with 503 (0 = unlimited)
+
+	// HTTPS is served when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	HTTPRedirectAddress string // with TLS, plain HTTP here is redirected to HTTPS ("" disables)
+
+	APIKey string // required on /api requests other than /health when set ("" leaves the API open)
+
+	// Recent signals and alerts are saved here on shutdown and reloaded on start ("" disables)
+	BufferStatePath     string
+	PersistedBufferSize int // newest signals and alerts kept in the saved state, each
+
+	WatchlistsPath string // named watchlists are saved here on every change ("" keeps them in memory)
 }
 
 type AlertingConfig struct {
 	Enabled            bool
 	SlackWebhookURL    string
 	DiscordWebhookURL  string
+	TelegramBotToken   string
+	TelegramChatID     string
 	AlertCooldownSecs  int
+	MinConfidence      float64 // signals below this confidence are not sent to webhooks
+	CooldownStatePath  string  // where cooldowns are saved across restarts ("" disables)
+	SendConcurrency    int     // webhook sends in flight at once, across all sinks
+	SendQueueSize      int     // sends waiting for a worker; beyond this they are dropped
+	SendTimeoutSecs    int     // a webhook request that takes longer fails (0 = no timeout)
+
+	// Daily window ("HH:MM" to "HH:MM" in QuietHoursTimezone, wrapping past
+	// midnight if start > end) when only signals at or above
+	// QuietHoursMinSeverity are sent. Empty start and end disables it.
+	QuietHoursStart       string
+	QuietHoursEnd         string
+	QuietHoursTimezone    string
+	QuietHoursMinSeverity string
+
+	// Minimum severity (info, low, medium, high, critical) each sink receives
+	SlackMinSeverity    string
+	DiscordMinSeverity  string
+	TelegramMinSeverity string
+
+	// Optional text/template per signal type (e.g. "volume_surge"); types without
+	// one use the built-in message format
+	Templates        map[string]string
+	DashboardBaseURL string // alerts link to <base>/markets/{ticker} when set
+
+	// Additional alert destinations. The single Slack/Discord/Telegram fields
+	// above remain as shorthand for one unfiltered sink of each type.
+	Sinks []SinkConfig
+}
+
+// SinkConfig is one alert destination with optional routing filters
+type SinkConfig struct {
+	Name        string
+	Type        string   // slack, discord, telegram, or webhook (generic JSON POST)
+	URL         string   // webhook URL (slack, discord, webhook)
+	BotToken    string   // telegram only
+	ChatID      string   // telegram only
+	Categories  []string // only markets in these categories (empty = all)
+	MinSeverity string   // minimum signal severity ("" = info)
 }
 
-func Load() (*Config, error) {
+// MarketURL returns the dashboard link for a market, or "" when no base URL is configured
+func MarketURL(baseURL, ticker string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(baseURL, "/") + "/markets/" + url.PathEscape(ticker)
+}
+
+// ScannerConfig holds trading cost assumptions used for edge estimates
+type ScannerConfig struct {
+	FeeRate             float64 // fraction of price charged per leg (taker)
+	MakerFeeRate        float64 // fraction of price charged per leg for passive fills
+	ExecutionStyle      string  // "taker" (cross the spread) or "maker" (post at the touch)
+	SlippageBufferCents float64 // extra slippage assumed per leg, on top of the book walk
+	FairValueVWAPWeight float64 // max weight given to recent-trade VWAP in fair value
+	FairValueMinTrades  int     // trades needed before VWAP gets its full weight
+	NoArbCooldownSecs   int     // suppress repeat no-arb alerts for the same event
+	NoArbEdgeChangeCents float64 // edge change that re-alerts within the cooldown
+	NoArbEvents         []string // event tickers to check for no-arb (empty = all events)
+	NoArbMinDollarEdge  float64  // net edge times executable size, in dollars, needed to be actionable
+
+	// Imbalance-pressure alerts need both conditions: |imbalance| above the
+	// threshold, and microprice still at least the lag away from mid
+	ImbalancePressureThreshold float64
+	ImbalancePriceLagCents     float64
+
+	// Alerts list the market's signals from this many seconds before (0 disables)
+	AlertSignalLookbackSecs int
+
+	RecentTradeWindowSecs int // window for an opportunity's recent-trade count and intensity
+	ActivityHalfLifeSecs  int // half-life of trades in the decayed activity score
+	MaxBookAgeSecs        int // books older than this are stale: not executable, no execution alerts
+	BookPollIntervalSecs  int // the ingestion orderbook poll interval, copied in at load (not a scanner key)
+	FreshOnly             bool // /scanner/opportunities omits stale books unless ?fresh_only=false
+	MicropriceLevels      int // book levels per side weighted into the microprice (1 = top of book)
+
+	// Relative weights of the components of an opportunity's tradability score
+	TradabilityLiquidityWeight float64
+	TradabilityFreshnessWeight float64
+	TradabilityActivityWeight  float64
+	TradabilityTwoSidedWeight  float64
+
+	// Trades of at least this many contracts count as large in trade-size stats
+	LargeTradeContracts int
+
+	// An expiring-soon alert fires once per market as its time to expiration
+	// drops below each of these (minutes)
+	ExpiryAlertMinutes []int
+}
+
+// AuditConfig controls the JSON-lines audit log. An empty Path disables it.
+type AuditConfig struct {
+	Path        string
+	MaxSizeMB   int
+	RotateDaily bool
+}
+
+// defaultConfigPath is read when it exists and no explicit path is given
+const defaultConfigPath = "config/default.toml"
+
+// Load builds the configuration from environment variables overridden by a TOML
+// file. configPath, or else KALSHI__CONFIG_FILE, names that file explicitly and
+// must exist; otherwise config/default.toml is used if present.
+func Load(configPath string) (*Config, error) {
 	cfg := &Config{
 		Kalshi: KalshiConfig{
-			APIBaseURL:     getEnv("KALSHI__KALSHI__API_BASE_URL", "https://api.elections.kalshi.com/trade-api/v2"),
-			WebSocketURL:   getEnv("KALSHI__KALSHI__WEBSOCKET_URL", "wss://api.elections.kalshi.com/trade-api/v2/ws"),
+			Environment:    getEnv("KALSHI__KALSHI__ENVIRONMENT", EnvironmentProd),
+			APIBaseURL:     getEnv("KALSHI__KALSHI__API_BASE_URL", ""),
+			WebSocketURL:   getEnv("KALSHI__KALSHI__WEBSOCKET_URL", ""),
 			APIKeyID:       getEnv("KALSHI__KALSHI__API_KEY_ID", ""),
 			PrivateKeyPath: getEnv("KALSHI__KALSHI__PRIVATE_KEY_PATH", "market_signal_bot.txt"),
 		},
 		Ingestion: IngestionConfig{
 			WebSocketReconnectDelaySecs: getEnvInt("KALSHI__INGESTION__WEBSOCKET_RECONNECT_DELAY_SECS", 5),
 			RESTPollIntervalSecs:        getEnvInt("KALSHI__INGESTION__REST_POLL_INTERVAL_SECS", 60),
+			QuietPollCycles:             getEnvInt("KALSHI__INGESTION__QUIET_POLL_CYCLES", 2),
+			MarketRefreshIntervalSecs:   getEnvInt("KALSHI__INGESTION__MARKET_REFRESH_INTERVAL_SECS", 60),
 			RateLimitPerSecond:          getEnvInt("KALSHI__INGESTION__RATE_LIMIT_PER_SECOND", 10),
+			RateLimitBurst:              getEnvInt("KALSHI__INGESTION__RATE_LIMIT_BURST", 1),
+			RateLimitJitterMs:           getEnvInt("KALSHI__INGESTION__RATE_LIMIT_JITTER_MS", 20),
+			PinnedMarketsPath:           getEnv("KALSHI__INGESTION__PINNED_MARKETS_PATH", "pinned_markets.json"),
+			MarketRetentionHours:        getEnvInt("KALSHI__INGESTION__MARKET_RETENTION_HOURS", 24),
+			MaxLevelParseFailureRatio:   getEnvFloat("KALSHI__INGESTION__MAX_LEVEL_PARSE_FAILURE_RATIO", 0.1),
+			BreakerFailureThreshold:     getEnvInt("KALSHI__INGESTION__BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerCooldownSecs:         getEnvInt("KALSHI__INGESTION__BREAKER_COOLDOWN_SECS", 30),
+			RequestTimeoutSecs:          getEnvInt("KALSHI__INGESTION__REQUEST_TIMEOUT_SECS", 30),
+			LogHTTPBodies:               getEnvBool("KALSHI__INGESTION__LOG_HTTP_BODIES", false),
+			LogHTTPMaxBytes:             getEnvInt("KALSHI__INGESTION__LOG_HTTP_MAX_BYTES", 4096),
 		},
 		Signals: SignalConfig{
 			ComputationIntervalSecs: getEnvInt("KALSHI__SIGNALS__COMPUTATION_INTERVAL_SECS", 1),
 			DriftWindowSecs:         getEnvInt("KALSHI__SIGNALS__DRIFT_WINDOW_SECS", 60),
+			DriftBaseline:           getEnv("KALSHI__SIGNALS__DRIFT_BASELINE", "trades"),
+			ReferencePrice:          getEnv("KALSHI__SIGNALS__REFERENCE_PRICE", "mid"),
 			DriftThreshold:          getEnvFloat("KALSHI__SIGNALS__DRIFT_THRESHOLD", 2.0),
 			ImbalanceThreshold:      getEnvFloat("KALSHI__SIGNALS__IMBALANCE_THRESHOLD", 0.3),
 			VolumeSurgeThreshold:    getEnvFloat("KALSHI__SIGNALS__VOLUME_SURGE_THRESHOLD", 3.0),
 			VolumeWindowSecs:         getEnvInt("KALSHI__SIGNALS__VOLUME_WINDOW_SECS", 30),
+			VolumeBaselineMultiplier: getEnvInt("KALSHI__SIGNALS__VOLUME_BASELINE_MULTIPLIER", 5),
+			MinTradeSamples:          getEnvInt("KALSHI__SIGNALS__MIN_TRADE_SAMPLES", 10),
+			WarmupMinSnapshots:       getEnvInt("KALSHI__SIGNALS__WARMUP_MIN_SNAPSHOTS", 30),
+			WarmupSecs:               getEnvInt("KALSHI__SIGNALS__WARMUP_SECS", 60),
+			StaggerSlots:             getEnvInt("KALSHI__SIGNALS__STAGGER_SLOTS", 10),
+			EventDebounceMs:          getEnvInt("KALSHI__SIGNALS__EVENT_DEBOUNCE_MS", 250),
+			ScoreSaturationRatio:     getEnvFloat("KALSHI__SIGNALS__SCORE_SATURATION_RATIO", 3.0),
+			WithdrawalDepthThreshold: getEnvInt("KALSHI__SIGNALS__WITHDRAWAL_DEPTH_THRESHOLD", 500),
+			FlickerThreshold:         getEnvFloat("KALSHI__SIGNALS__FLICKER_THRESHOLD", 2.0),
+			FlickerWindowSecs:        getEnvInt("KALSHI__SIGNALS__FLICKER_WINDOW_SECS", 10),
+			QuantIntervalSecs:        getEnvInt("KALSHI__SIGNALS__QUANT_INTERVAL_SECS", 10),
+			QuantMinActivity:         getEnvFloat("KALSHI__SIGNALS__QUANT_MIN_ACTIVITY", 0.1),
 		},
 		API: APIConfig{
 			BindAddress: getBindAddress(),
 			CORSOrigins: getEnvSlice("KALSHI__API__CORS_ORIGINS", []string{"*"}),
+			OrderbookLevels: getEnvInt("KALSHI__API__ORDERBOOK_LEVELS", 10),
+			BufferStatePath:     getEnv("KALSHI__API__BUFFER_STATE_PATH", "api_buffers.json"),
+			PersistedBufferSize: getEnvInt("KALSHI__API__PERSISTED_BUFFER_SIZE", 200),
+			WatchlistsPath:      getEnv("KALSHI__API__WATCHLISTS_PATH", "watchlists.json"),
+			MaxStreamClients:    getEnvInt("KALSHI__API__MAX_STREAM_CLIENTS", 100),
+			TLSCertFile:         getEnv("KALSHI__API__TLS_CERT_FILE", ""),
+			TLSKeyFile:          getEnv("KALSHI__API__TLS_KEY_FILE", ""),
+			HTTPRedirectAddress: getEnv("KALSHI__API__HTTP_REDIRECT_ADDRESS", ""),
+			APIKey:              getEnv("KALSHI__API__API_KEY", ""),
 		},
 		Alerting: AlertingConfig{
 			Enabled:           getEnvBool("KALSHI__ALERTING__ENABLED", true),
 			SlackWebhookURL:   getEnv("KALSHI__ALERTING__SLACK_WEBHOOK_URL", ""),
 			DiscordWebhookURL: getEnv("KALSHI__ALERTING__DISCORD_WEBHOOK_URL", ""),
+			TelegramBotToken:  getEnv("KALSHI__ALERTING__TELEGRAM_BOT_TOKEN", ""),
+			TelegramChatID:    getEnv("KALSHI__ALERTING__TELEGRAM_CHAT_ID", ""),
 			AlertCooldownSecs: getEnvInt("KALSHI__ALERTING__ALERT_COOLDOWN_SECS", 300),
+			MinConfidence:     getEnvFloat("KALSHI__ALERTING__MIN_CONFIDENCE", 0.0),
+			CooldownStatePath: getEnv("KALSHI__ALERTING__COOLDOWN_STATE_PATH", "alert_cooldowns.json"),
+			SendConcurrency:   getEnvInt("KALSHI__ALERTING__SEND_CONCURRENCY", 4),
+			SendQueueSize:     getEnvInt("KALSHI__ALERTING__SEND_QUEUE_SIZE", 100),
+			SendTimeoutSecs:   getEnvInt("KALSHI__ALERTING__SEND_TIMEOUT_SECS", 10),
+			QuietHoursStart:       getEnv("KALSHI__ALERTING__QUIET_HOURS_START", ""),
+			QuietHoursEnd:         getEnv("KALSHI__ALERTING__QUIET_HOURS_END", ""),
+			QuietHoursTimezone:    getEnv("KALSHI__ALERTING__QUIET_HOURS_TIMEZONE", "UTC"),
+			QuietHoursMinSeverity: getEnv("KALSHI__ALERTING__QUIET_HOURS_MIN_SEVERITY", "high"),
+			SlackMinSeverity:    getEnv("KALSHI__ALERTING__SLACK_MIN_SEVERITY", "info"),
+			DiscordMinSeverity:  getEnv("KALSHI__ALERTING__DISCORD_MIN_SEVERITY", "info"),
+			TelegramMinSeverity: getEnv("KALSHI__ALERTING__TELEGRAM_MIN_SEVERITY", "info"),
+			Templates:           make(map[string]string),
+			DashboardBaseURL:    getEnv("KALSHI__ALERTING__DASHBOARD_BASE_URL", ""),
+		},
+		Scanner: ScannerConfig{
+			FeeRate:             getEnvFloat("KALSHI__SCANNER__FEE_RATE", 0.05),
+			MakerFeeRate:        getEnvFloat("KALSHI__SCANNER__MAKER_FEE_RATE", 0.0),
+			ExecutionStyle:      getEnv("KALSHI__SCANNER__EXECUTION_STYLE", "taker"),
+			SlippageBufferCents: getEnvFloat("KALSHI__SCANNER__SLIPPAGE_BUFFER_CENTS", 1.0),
+			FairValueVWAPWeight: getEnvFloat("KALSHI__SCANNER__FAIR_VALUE_VWAP_WEIGHT", 0.4),
+			FairValueMinTrades:  getEnvInt("KALSHI__SCANNER__FAIR_VALUE_MIN_TRADES", 10),
+			NoArbCooldownSecs:   getEnvInt("KALSHI__SCANNER__NOARB_COOLDOWN_SECS", 300),
+			NoArbEdgeChangeCents: getEnvFloat("KALSHI__SCANNER__NOARB_EDGE_CHANGE_CENTS", 1.0),
+			NoArbEvents:         getEnvSlice("KALSHI__SCANNER__NOARB_EVENTS", nil),
+			NoArbMinDollarEdge:  getEnvFloat("KALSHI__SCANNER__NOARB_MIN_DOLLAR_EDGE", 1.0),
+			ImbalancePressureThreshold: getEnvFloat("KALSHI__SCANNER__IMBALANCE_PRESSURE_THRESHOLD", 0.6),
+			ImbalancePriceLagCents:     getEnvFloat("KALSHI__SCANNER__IMBALANCE_PRICE_LAG_CENTS", 1.0),
+			AlertSignalLookbackSecs:    getEnvInt("KALSHI__SCANNER__ALERT_SIGNAL_LOOKBACK_SECS", 300),
+			RecentTradeWindowSecs:      getEnvInt("KALSHI__SCANNER__RECENT_TRADE_WINDOW_SECS", 30),
+			ActivityHalfLifeSecs:       getEnvInt("KALSHI__SCANNER__ACTIVITY_HALF_LIFE_SECS", 60),
+			MaxBookAgeSecs:             getEnvInt("KALSHI__SCANNER__MAX_BOOK_AGE_SECS", 0),
+			FreshOnly:                  getEnvBool("KALSHI__SCANNER__FRESH_ONLY", false),
+			MicropriceLevels:           getEnvInt("KALSHI__SCANNER__MICROPRICE_LEVELS", 1),
+			TradabilityLiquidityWeight: getEnvFloat("KALSHI__SCANNER__TRADABILITY_LIQUIDITY_WEIGHT", 0.4),
+			TradabilityFreshnessWeight: getEnvFloat("KALSHI__SCANNER__TRADABILITY_FRESHNESS_WEIGHT", 0.2),
+			TradabilityActivityWeight:  getEnvFloat("KALSHI__SCANNER__TRADABILITY_ACTIVITY_WEIGHT", 0.2),
+			TradabilityTwoSidedWeight:  getEnvFloat("KALSHI__SCANNER__TRADABILITY_TWO_SIDED_WEIGHT", 0.2),
+			LargeTradeContracts:        getEnvInt("KALSHI__SCANNER__LARGE_TRADE_CONTRACTS", 100),
+			ExpiryAlertMinutes:         getEnvIntSlice("KALSHI__SCANNER__EXPIRY_ALERT_MINUTES", []int{60, 15}),
+		},
+		Audit: AuditConfig{
+			Path:        getEnv("KALSHI__AUDIT__PATH", ""),
+			MaxSizeMB:   getEnvInt("KALSHI__AUDIT__MAX_SIZE_MB", 100),
+			RotateDaily: getEnvBool("KALSHI__AUDIT__ROTATE_DAILY", true),
 		},
 	}
 
-	// Load TOML config file if it exists
-	tomlPath := "config/default.toml"
+	// Load TOML config file: an explicit path must exist, the default is optional
+	tomlPath := configPath
+	if tomlPath == "" {
+		tomlPath = os.Getenv("KALSHI__CONFIG_FILE")
+	}
+	if tomlPath != "" {
+		if _, err := os.Stat(tomlPath); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", tomlPath, err)
+		}
+	} else {
+		tomlPath = defaultConfigPath
+	}
 	if _, err := os.Stat(tomlPath); err == nil {
 		data, err := os.ReadFile(tomlPath)
 		if err != nil {
@@ -99,6 +342,8 @@ func Load() (*Config, error) {
 			Signals   map[string]interface{} `toml:"signals"`
 			API       map[string]interface{} `toml:"api"`
 			Alerting  map[string]interface{} `toml:"alerting"`
+			Audit     map[string]interface{} `toml:"audit"`
+			Scanner   map[string]interface{} `toml:"scanner"`
 		}
 
 		if err := toml.Unmarshal(data, &tomlConfig); err != nil {
@@ -106,27 +351,78 @@ func Load() (*Config, error) {
 		}
 
 		// Override with TOML values
+		if kalshi, ok := tomlConfig.Kalshi["environment"].(string); ok {
+			cfg.Kalshi.Environment = kalshi
+		}
 		if kalshi, ok := tomlConfig.Kalshi["api_base_url"].(string); ok {
 			cfg.Kalshi.APIBaseURL = kalshi
 		}
 		if kalshi, ok := tomlConfig.Kalshi["websocket_url"].(string); ok {
 			cfg.Kalshi.WebSocketURL = kalshi
 		}
+		if kalshi, ok := tomlConfig.Kalshi["api_key_id"].(string); ok {
+			cfg.Kalshi.APIKeyID = kalshi
+		}
+		if kalshi, ok := tomlConfig.Kalshi["private_key_path"].(string); ok {
+			cfg.Kalshi.PrivateKeyPath = kalshi
+		}
 		if kalshi, ok := tomlConfig.Ingestion["websocket_reconnect_delay_secs"].(int64); ok {
 			cfg.Ingestion.WebSocketReconnectDelaySecs = int(kalshi)
 		}
 		if kalshi, ok := tomlConfig.Ingestion["rest_poll_interval_secs"].(int64); ok {
 			cfg.Ingestion.RESTPollIntervalSecs = int(kalshi)
 		}
+		if kalshi, ok := tomlConfig.Ingestion["quiet_poll_cycles"].(int64); ok {
+			cfg.Ingestion.QuietPollCycles = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["market_refresh_interval_secs"].(int64); ok {
+			cfg.Ingestion.MarketRefreshIntervalSecs = int(kalshi)
+		}
 		if kalshi, ok := tomlConfig.Ingestion["rate_limit_per_second"].(int64); ok {
 			cfg.Ingestion.RateLimitPerSecond = int(kalshi)
 		}
+		if kalshi, ok := tomlConfig.Ingestion["rate_limit_burst"].(int64); ok {
+			cfg.Ingestion.RateLimitBurst = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["rate_limit_jitter_ms"].(int64); ok {
+			cfg.Ingestion.RateLimitJitterMs = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["pinned_markets_path"].(string); ok {
+			cfg.Ingestion.PinnedMarketsPath = kalshi
+		}
+		if kalshi, ok := tomlConfig.Ingestion["market_retention_hours"].(int64); ok {
+			cfg.Ingestion.MarketRetentionHours = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["max_level_parse_failure_ratio"].(float64); ok {
+			cfg.Ingestion.MaxLevelParseFailureRatio = kalshi
+		}
+		if kalshi, ok := tomlConfig.Ingestion["breaker_failure_threshold"].(int64); ok {
+			cfg.Ingestion.BreakerFailureThreshold = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["breaker_cooldown_secs"].(int64); ok {
+			cfg.Ingestion.BreakerCooldownSecs = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["request_timeout_secs"].(int64); ok {
+			cfg.Ingestion.RequestTimeoutSecs = int(kalshi)
+		}
+		if kalshi, ok := tomlConfig.Ingestion["log_http_bodies"].(bool); ok {
+			cfg.Ingestion.LogHTTPBodies = kalshi
+		}
+		if kalshi, ok := tomlConfig.Ingestion["log_http_max_bytes"].(int64); ok {
+			cfg.Ingestion.LogHTTPMaxBytes = int(kalshi)
+		}
 		if sig, ok := tomlConfig.Signals["computation_interval_secs"].(int64); ok {
 			cfg.Signals.ComputationIntervalSecs = int(sig)
 		}
 		if sig, ok := tomlConfig.Signals["drift_window_secs"].(int64); ok {
 			cfg.Signals.DriftWindowSecs = int(sig)
 		}
+		if sig, ok := tomlConfig.Signals["drift_baseline"].(string); ok {
+			cfg.Signals.DriftBaseline = sig
+		}
+		if sig, ok := tomlConfig.Signals["reference_price"].(string); ok {
+			cfg.Signals.ReferencePrice = sig
+		}
 		if sig, ok := tomlConfig.Signals["drift_threshold"].(float64); ok {
 			cfg.Signals.DriftThreshold = sig
 		}
@@ -139,9 +435,72 @@ func Load() (*Config, error) {
 		if sig, ok := tomlConfig.Signals["volume_window_secs"].(int64); ok {
 			cfg.Signals.VolumeWindowSecs = int(sig)
 		}
+		if sig, ok := tomlConfig.Signals["volume_baseline_multiplier"].(int64); ok {
+			cfg.Signals.VolumeBaselineMultiplier = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["min_trade_samples"].(int64); ok {
+			cfg.Signals.MinTradeSamples = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["warmup_min_snapshots"].(int64); ok {
+			cfg.Signals.WarmupMinSnapshots = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["warmup_secs"].(int64); ok {
+			cfg.Signals.WarmupSecs = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["stagger_slots"].(int64); ok {
+			cfg.Signals.StaggerSlots = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["event_debounce_ms"].(int64); ok {
+			cfg.Signals.EventDebounceMs = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["score_saturation_ratio"].(float64); ok {
+			cfg.Signals.ScoreSaturationRatio = sig
+		}
+		if sig, ok := tomlConfig.Signals["withdrawal_depth_threshold"].(int64); ok {
+			cfg.Signals.WithdrawalDepthThreshold = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["flicker_threshold"].(float64); ok {
+			cfg.Signals.FlickerThreshold = sig
+		}
+		if sig, ok := tomlConfig.Signals["flicker_window_secs"].(int64); ok {
+			cfg.Signals.FlickerWindowSecs = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["quant_interval_secs"].(int64); ok {
+			cfg.Signals.QuantIntervalSecs = int(sig)
+		}
+		if sig, ok := tomlConfig.Signals["quant_min_activity"].(float64); ok {
+			cfg.Signals.QuantMinActivity = sig
+		}
 		if api, ok := tomlConfig.API["bind_address"].(string); ok {
 			cfg.API.BindAddress = api
 		}
+		if api, ok := tomlConfig.API["orderbook_levels"].(int64); ok {
+			cfg.API.OrderbookLevels = int(api)
+		}
+		if api, ok := tomlConfig.API["buffer_state_path"].(string); ok {
+			cfg.API.BufferStatePath = api
+		}
+		if api, ok := tomlConfig.API["persisted_buffer_size"].(int64); ok {
+			cfg.API.PersistedBufferSize = int(api)
+		}
+		if api, ok := tomlConfig.API["watchlists_path"].(string); ok {
+			cfg.API.WatchlistsPath = api
+		}
+		if api, ok := tomlConfig.API["max_stream_clients"].(int64); ok {
+			cfg.API.MaxStreamClients = int(api)
+		}
+		if api, ok := tomlConfig.API["tls_cert_file"].(string); ok {
+			cfg.API.TLSCertFile = api
+		}
+		if api, ok := tomlConfig.API["tls_key_file"].(string); ok {
+			cfg.API.TLSKeyFile = api
+		}
+		if api, ok := tomlConfig.API["http_redirect_address"].(string); ok {
+			cfg.API.HTTPRedirectAddress = api
+		}
+		if api, ok := tomlConfig.API["api_key"].(string); ok {
+			cfg.API.APIKey = api
+		}
 		if api, ok := tomlConfig.API["cors_origins"].([]interface{}); ok {
 			origins := make([]string, 0, len(api))
 			for _, v := range api {
@@ -154,9 +513,182 @@ func Load() (*Config, error) {
 		if alert, ok := tomlConfig.Alerting["enabled"].(bool); ok {
 			cfg.Alerting.Enabled = alert
 		}
+		if alert, ok := tomlConfig.Alerting["slack_webhook_url"].(string); ok {
+			cfg.Alerting.SlackWebhookURL = alert
+		}
+		if alert, ok := tomlConfig.Alerting["discord_webhook_url"].(string); ok {
+			cfg.Alerting.DiscordWebhookURL = alert
+		}
+		if alert, ok := tomlConfig.Alerting["telegram_bot_token"].(string); ok {
+			cfg.Alerting.TelegramBotToken = alert
+		}
+		if alert, ok := tomlConfig.Alerting["telegram_chat_id"].(string); ok {
+			cfg.Alerting.TelegramChatID = alert
+		}
 		if alert, ok := tomlConfig.Alerting["alert_cooldown_secs"].(int64); ok {
 			cfg.Alerting.AlertCooldownSecs = int(alert)
 		}
+		if alert, ok := tomlConfig.Alerting["min_confidence"].(float64); ok {
+			cfg.Alerting.MinConfidence = alert
+		}
+		if alert, ok := tomlConfig.Alerting["cooldown_state_path"].(string); ok {
+			cfg.Alerting.CooldownStatePath = alert
+		}
+		if alert, ok := tomlConfig.Alerting["send_concurrency"].(int64); ok {
+			cfg.Alerting.SendConcurrency = int(alert)
+		}
+		if alert, ok := tomlConfig.Alerting["send_queue_size"].(int64); ok {
+			cfg.Alerting.SendQueueSize = int(alert)
+		}
+		if alert, ok := tomlConfig.Alerting["send_timeout_secs"].(int64); ok {
+			cfg.Alerting.SendTimeoutSecs = int(alert)
+		}
+		if alert, ok := tomlConfig.Alerting["quiet_hours_start"].(string); ok {
+			cfg.Alerting.QuietHoursStart = alert
+		}
+		if alert, ok := tomlConfig.Alerting["quiet_hours_end"].(string); ok {
+			cfg.Alerting.QuietHoursEnd = alert
+		}
+		if alert, ok := tomlConfig.Alerting["quiet_hours_timezone"].(string); ok {
+			cfg.Alerting.QuietHoursTimezone = alert
+		}
+		if alert, ok := tomlConfig.Alerting["quiet_hours_min_severity"].(string); ok {
+			cfg.Alerting.QuietHoursMinSeverity = alert
+		}
+		if alert, ok := tomlConfig.Alerting["slack_min_severity"].(string); ok {
+			cfg.Alerting.SlackMinSeverity = alert
+		}
+		if alert, ok := tomlConfig.Alerting["discord_min_severity"].(string); ok {
+			cfg.Alerting.DiscordMinSeverity = alert
+		}
+		if alert, ok := tomlConfig.Alerting["telegram_min_severity"].(string); ok {
+			cfg.Alerting.TelegramMinSeverity = alert
+		}
+		if alert, ok := tomlConfig.Alerting["dashboard_base_url"].(string); ok {
+			cfg.Alerting.DashboardBaseURL = alert
+		}
+		if sinks, ok := tomlConfig.Alerting["sinks"].([]interface{}); ok {
+			for _, v := range sinks {
+				if table, ok := v.(map[string]interface{}); ok {
+					cfg.Alerting.Sinks = append(cfg.Alerting.Sinks, parseSinkConfig(table))
+				}
+			}
+		}
+		if templates, ok := tomlConfig.Alerting["templates"].(map[string]interface{}); ok {
+			for signalType, tmpl := range templates {
+				if text, ok := tmpl.(string); ok {
+					cfg.Alerting.Templates[signalType] = text
+				}
+			}
+		}
+		if scan, ok := tomlConfig.Scanner["fee_rate"].(float64); ok {
+			cfg.Scanner.FeeRate = scan
+		}
+		if scan, ok := tomlConfig.Scanner["maker_fee_rate"].(float64); ok {
+			cfg.Scanner.MakerFeeRate = scan
+		}
+		if scan, ok := tomlConfig.Scanner["execution_style"].(string); ok {
+			cfg.Scanner.ExecutionStyle = scan
+		}
+		if scan, ok := tomlConfig.Scanner["slippage_buffer_cents"].(float64); ok {
+			cfg.Scanner.SlippageBufferCents = scan
+		}
+		if scan, ok := tomlConfig.Scanner["fair_value_vwap_weight"].(float64); ok {
+			cfg.Scanner.FairValueVWAPWeight = scan
+		}
+		if scan, ok := tomlConfig.Scanner["fair_value_min_trades"].(int64); ok {
+			cfg.Scanner.FairValueMinTrades = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["noarb_cooldown_secs"].(int64); ok {
+			cfg.Scanner.NoArbCooldownSecs = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["noarb_edge_change_cents"].(float64); ok {
+			cfg.Scanner.NoArbEdgeChangeCents = scan
+		}
+		if scan, ok := tomlConfig.Scanner["noarb_min_dollar_edge"].(float64); ok {
+			cfg.Scanner.NoArbMinDollarEdge = scan
+		}
+		if scan, ok := tomlConfig.Scanner["imbalance_pressure_threshold"].(float64); ok {
+			cfg.Scanner.ImbalancePressureThreshold = scan
+		}
+		if scan, ok := tomlConfig.Scanner["imbalance_price_lag_cents"].(float64); ok {
+			cfg.Scanner.ImbalancePriceLagCents = scan
+		}
+		if scan, ok := tomlConfig.Scanner["alert_signal_lookback_secs"].(int64); ok {
+			cfg.Scanner.AlertSignalLookbackSecs = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["recent_trade_window_secs"].(int64); ok {
+			cfg.Scanner.RecentTradeWindowSecs = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["activity_half_life_secs"].(int64); ok {
+			cfg.Scanner.ActivityHalfLifeSecs = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["max_book_age_secs"].(int64); ok {
+			cfg.Scanner.MaxBookAgeSecs = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["fresh_only"].(bool); ok {
+			cfg.Scanner.FreshOnly = scan
+		}
+		if scan, ok := tomlConfig.Scanner["microprice_levels"].(int64); ok {
+			cfg.Scanner.MicropriceLevels = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["tradability_liquidity_weight"].(float64); ok {
+			cfg.Scanner.TradabilityLiquidityWeight = scan
+		}
+		if scan, ok := tomlConfig.Scanner["tradability_freshness_weight"].(float64); ok {
+			cfg.Scanner.TradabilityFreshnessWeight = scan
+		}
+		if scan, ok := tomlConfig.Scanner["tradability_activity_weight"].(float64); ok {
+			cfg.Scanner.TradabilityActivityWeight = scan
+		}
+		if scan, ok := tomlConfig.Scanner["tradability_two_sided_weight"].(float64); ok {
+			cfg.Scanner.TradabilityTwoSidedWeight = scan
+		}
+		if scan, ok := tomlConfig.Scanner["large_trade_contracts"].(int64); ok {
+			cfg.Scanner.LargeTradeContracts = int(scan)
+		}
+		if scan, ok := tomlConfig.Scanner["noarb_events"].([]interface{}); ok {
+			events := make([]string, 0, len(scan))
+			for _, v := range scan {
+				if s, ok := v.(string); ok {
+					events = append(events, s)
+				}
+			}
+			cfg.Scanner.NoArbEvents = events
+		}
+		if scan, ok := tomlConfig.Scanner["expiry_alert_minutes"].([]interface{}); ok {
+			minutes := make([]int, 0, len(scan))
+			for _, v := range scan {
+				if m, ok := v.(int64); ok {
+					minutes = append(minutes, int(m))
+				}
+			}
+			cfg.Scanner.ExpiryAlertMinutes = minutes
+		}
+		if audit, ok := tomlConfig.Audit["path"].(string); ok {
+			cfg.Audit.Path = audit
+		}
+		if audit, ok := tomlConfig.Audit["max_size_mb"].(int64); ok {
+			cfg.Audit.MaxSizeMB = int(audit)
+		}
+		if audit, ok := tomlConfig.Audit["rotate_daily"].(bool); ok {
+			cfg.Audit.RotateDaily = audit
+		}
+	}
+
+	if err := cfg.Kalshi.applyEnvironment(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Signals.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Alerting.validate(); err != nil {
+		return nil, err
+	}
+
+	cfg.Scanner.BookPollIntervalSecs = cfg.Ingestion.RESTPollIntervalSecs
+	if cfg.Scanner.MaxBookAgeSecs <= 0 {
+		cfg.Scanner.MaxBookAgeSecs = defaultMaxBookAgeSecs(cfg.Ingestion)
 	}
 
 	// Validate private key path
@@ -172,6 +704,66 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// defaultMaxBookAgeSecs is the slowest REST poll cadence (quiet markets are
+// fetched every QuietPollCycles intervals) plus half an interval of slack, so a
+// book is only stale once it has missed a poll it should have had. Books are
+// only refreshed by REST polls; the websocket carries tickers and trades.
+func defaultMaxBookAgeSecs(ingestion IngestionConfig) int {
+	interval := max(ingestion.RESTPollIntervalSecs, 1)
+	return interval*max(ingestion.QuietPollCycles, 1) + interval/2
+}
+
+// validate rejects signal settings the processor can't work with
+func (s *SignalConfig) validate() error {
+	if s.VolumeBaselineMultiplier < 2 {
+		return fmt.Errorf("signals volume_baseline_multiplier is %d, must be at least 2 so the baseline spans more than the recent window", s.VolumeBaselineMultiplier)
+	}
+	return nil
+}
+
+// severityNames are the signal severities a min_severity setting may name
+var severityNames = map[string]bool{"info": true, "low": true, "medium": true, "high": true, "critical": true}
+
+// validate rejects misspelled severities, which would otherwise rank as info
+// and silently filter nothing
+func (a *AlertingConfig) validate() error {
+	settings := []struct{ key, value string }{
+		{"quiet_hours_min_severity", a.QuietHoursMinSeverity},
+		{"slack_min_severity", a.SlackMinSeverity},
+		{"discord_min_severity", a.DiscordMinSeverity},
+		{"telegram_min_severity", a.TelegramMinSeverity},
+	}
+	for _, sink := range a.Sinks {
+		settings = append(settings, struct{ key, value string }{"sinks." + sink.Name + ".min_severity", sink.MinSeverity})
+	}
+
+	for _, setting := range settings {
+		if setting.value != "" && !severityNames[setting.value] {
+			return fmt.Errorf("alerting %s is %q, must be one of info, low, medium, high, critical", setting.key, setting.value)
+		}
+	}
+	return nil
+}
+
+// parseSinkConfig reads one [[alerting.sinks]] table
+func parseSinkConfig(table map[string]interface{}) SinkConfig {
+	var sink SinkConfig
+	sink.Name, _ = table["name"].(string)
+	sink.Type, _ = table["type"].(string)
+	sink.URL, _ = table["url"].(string)
+	sink.BotToken, _ = table["bot_token"].(string)
+	sink.ChatID, _ = table["chat_id"].(string)
+	sink.MinSeverity, _ = table["min_severity"].(string)
+	if categories, ok := table["categories"].([]interface{}); ok {
+		for _, c := range categories {
+			if category, ok := c.(string); ok {
+				sink.Categories = append(sink.Categories, category)
+			}
+		}
+	}
+	return sink
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -213,6 +805,24 @@ func getEnvSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvIntSlice parses a comma-separated list of integers, falling back to the
+// default if any entry is invalid
+func getEnvIntSlice(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		intValue, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return defaultValue
+		}
+		values = append(values, intValue)
+	}
+	return values
+}
+
 func getBindAddress() string {
 	// Railway and Render set PORT environment variable
 	if port := os.Getenv("PORT"); port != "" {
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a TOML file into a temp dir and returns its path
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRejectsShortVolumeBaseline(t *testing.T) {
+	for _, multiplier := range []string{"0", "1", "-3"} {
+		path := writeConfig(t, "[signals]\nvolume_baseline_multiplier = "+multiplier+"\n")
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), "volume_baseline_multiplier") {
+			t.Errorf("multiplier %s: err = %v, want a volume_baseline_multiplier error", multiplier, err)
+		}
+	}
+
+	cfg, err := Load(writeConfig(t, "[signals]\nvolume_baseline_multiplier = 8\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Signals.VolumeBaselineMultiplier != 8 {
+		t.Errorf("multiplier = %d, want 8", cfg.Signals.VolumeBaselineMultiplier)
+	}
+}
+
+func TestLoadFromExplicitPath(t *testing.T) {
+	path := writeConfig(t, "[api]\nbind_address = \"127.0.0.1:9123\"\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.API.BindAddress != "127.0.0.1:9123" {
+		t.Errorf("--config path: bind address = %q, want the file's", cfg.API.BindAddress)
+	}
+
+	t.Setenv("KALSHI__CONFIG_FILE", path)
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.API.BindAddress != "127.0.0.1:9123" {
+		t.Errorf("KALSHI__CONFIG_FILE: bind address = %q, want the file's", cfg.API.BindAddress)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.toml")
+	if _, err := Load(missing); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("missing file: err = %v, want an error naming %s", err, missing)
+	}
+}
+
+// fullConfig sets every TOML key to a value that differs from its default
+const fullConfig = `
+[kalshi]
+environment = "demo"
+api_base_url = "https://rest.example.com"
+websocket_url = "wss://ws.example.com"
+api_key_id = "key-id"
+private_key_path = "/keys/kalshi.pem"
+
+[ingestion]
+websocket_reconnect_delay_secs = 7
+rest_poll_interval_secs = 17
+quiet_poll_cycles = 4
+market_refresh_interval_secs = 120
+rate_limit_per_second = 20
+rate_limit_burst = 3
+rate_limit_jitter_ms = 50
+pinned_markets_path = "/data/pins.json"
+market_retention_hours = 48
+max_level_parse_failure_ratio = 0.25
+breaker_failure_threshold = 9
+breaker_cooldown_secs = 45
+request_timeout_secs = 12
+log_http_bodies = true
+log_http_max_bytes = 1024
+
+[signals]
+computation_interval_secs = 2
+drift_window_secs = 90
+drift_threshold = 2.5
+drift_baseline = "snapshots"
+reference_price = "microprice"
+imbalance_threshold = 0.4
+volume_surge_threshold = 4.0
+volume_window_secs = 45
+volume_baseline_multiplier = 6
+min_trade_samples = 12
+warmup_min_snapshots = 40
+warmup_secs = 90
+stagger_slots = 5
+event_debounce_ms = 500
+score_saturation_ratio = 4.0
+withdrawal_depth_threshold = 250
+flicker_threshold = 3.0
+flicker_window_secs = 20
+quant_interval_secs = 30
+quant_min_activity = 0.5
+
+[api]
+bind_address = "127.0.0.1:9000"
+cors_origins = ["https://dash.example.com"]
+orderbook_levels = 5
+max_stream_clients = 10
+tls_cert_file = "/tls/cert.pem"
+tls_key_file = "/tls/key.pem"
+http_redirect_address = ":80"
+api_key = "secret"
+buffer_state_path = "/data/buffers.json"
+persisted_buffer_size = 50
+watchlists_path = "/data/watchlists.json"
+
+[alerting]
+enabled = false
+slack_webhook_url = "https://hooks.slack.com/services/x"
+discord_webhook_url = "https://discord.com/api/webhooks/x"
+telegram_bot_token = "bot-token"
+telegram_chat_id = "chat-id"
+alert_cooldown_secs = 600
+min_confidence = 0.7
+cooldown_state_path = "/data/cooldowns.json"
+send_concurrency = 8
+send_queue_size = 200
+send_timeout_secs = 5
+quiet_hours_start = "22:00"
+quiet_hours_end = "07:00"
+quiet_hours_timezone = "America/New_York"
+quiet_hours_min_severity = "medium"
+slack_min_severity = "low"
+discord_min_severity = "medium"
+telegram_min_severity = "high"
+dashboard_base_url = "https://dash.example.com"
+
+[[alerting.sinks]]
+name = "pager"
+type = "webhook"
+url = "https://pager.example.com"
+categories = ["Politics"]
+min_severity = "critical"
+
+[alerting.templates]
+volume_surge = "{{.MarketTicker}}"
+
+[scanner]
+fee_rate = 0.07
+maker_fee_rate = 0.01
+execution_style = "maker"
+slippage_buffer_cents = 2.0
+fair_value_vwap_weight = 0.5
+fair_value_min_trades = 20
+noarb_cooldown_secs = 600
+noarb_edge_change_cents = 2.0
+noarb_events = ["EV-1"]
+noarb_min_dollar_edge = 5.0
+imbalance_pressure_threshold = 0.7
+imbalance_price_lag_cents = 2.0
+alert_signal_lookback_secs = 600
+recent_trade_window_secs = 60
+activity_half_life_secs = 120
+max_book_age_secs = 240
+fresh_only = true
+microprice_levels = 2
+tradability_liquidity_weight = 0.3
+tradability_freshness_weight = 0.3
+tradability_activity_weight = 0.3
+tradability_two_sided_weight = 0.1
+large_trade_contracts = 250
+expiry_alert_minutes = [30, 5]
+
+[audit]
+path = "/data/audit.jsonl"
+max_size_mb = 10
+rotate_daily = false
+`
+
+func TestLoadFullTOML(t *testing.T) {
+	defaults, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(writeConfig(t, fullConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every field must come from the file, so none may still hold its default
+	var unchanged []string
+	var compare func(path string, got, def reflect.Value)
+	compare = func(path string, got, def reflect.Value) {
+		if got.Kind() == reflect.Struct {
+			for i := 0; i < got.NumField(); i++ {
+				compare(path+"."+got.Type().Field(i).Name, got.Field(i), def.Field(i))
+			}
+			return
+		}
+		if reflect.DeepEqual(got.Interface(), def.Interface()) {
+			unchanged = append(unchanged, path)
+		}
+	}
+	compare("Config", reflect.ValueOf(*cfg), reflect.ValueOf(*defaults))
+	if len(unchanged) > 0 {
+		t.Errorf("not read from TOML: %s", strings.Join(unchanged, ", "))
+	}
+
+	// Spot-check the auth and webhook fields a TOML-only deployment needs
+	if cfg.Kalshi.APIKeyID != "key-id" || cfg.Kalshi.PrivateKeyPath != "/keys/kalshi.pem" {
+		t.Errorf("kalshi auth = %q, %q", cfg.Kalshi.APIKeyID, cfg.Kalshi.PrivateKeyPath)
+	}
+	if cfg.Alerting.SlackWebhookURL != "https://hooks.slack.com/services/x" ||
+		cfg.Alerting.DiscordWebhookURL != "https://discord.com/api/webhooks/x" {
+		t.Errorf("webhooks = %q, %q", cfg.Alerting.SlackWebhookURL, cfg.Alerting.DiscordWebhookURL)
+	}
+	if len(cfg.Alerting.Sinks) != 1 || cfg.Alerting.Sinks[0].MinSeverity != "critical" {
+		t.Errorf("sinks = %+v", cfg.Alerting.Sinks)
+	}
+}
+
+func TestMaxBookAgeDefaultsToPollCadence(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 60s polls, quiet markets every 2nd cycle, plus half an interval
+	if cfg.Scanner.MaxBookAgeSecs != 150 || cfg.Scanner.BookPollIntervalSecs != 60 {
+		t.Errorf("defaults: max book age %ds, poll interval %ds, want 150s and 60s",
+			cfg.Scanner.MaxBookAgeSecs, cfg.Scanner.BookPollIntervalSecs)
+	}
+
+	cfg, err = Load(writeConfig(t, "[ingestion]\nrest_poll_interval_secs = 20\nquiet_poll_cycles = 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Scanner.MaxBookAgeSecs != 90 {
+		t.Errorf("20s polls every 4th cycle: max book age %ds, want 90s", cfg.Scanner.MaxBookAgeSecs)
+	}
+
+	cfg, err = Load(writeConfig(t, "[ingestion]\nrest_poll_interval_secs = 20\n[scanner]\nmax_book_age_secs = 15\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Scanner.MaxBookAgeSecs != 15 {
+		t.Errorf("explicit max book age %ds, want 15s", cfg.Scanner.MaxBookAgeSecs)
+	}
+}
+
+func TestLoadRejectsUnknownSeverities(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+	}{
+		{"quiet hours", "[alerting]\nquiet_hours_min_severity = \"hgih\"\n"},
+		{"built-in sink", "[alerting]\nslack_min_severity = \"urgent\"\n"},
+		{"configured sink", "[alerting]\n[[alerting.sinks]]\nname = \"pager\"\ntype = \"webhook\"\nurl = \"http://pager.invalid\"\nmin_severity = \"Critical\"\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Load(writeConfig(t, tt.toml)); err == nil {
+			t.Errorf("%s: misspelled severity loaded without error", tt.name)
+		}
+	}
+
+	cfg, err := Load(writeConfig(t, "[alerting]\nquiet_hours_min_severity = \"critical\"\n"))
+	if err != nil {
+		t.Fatalf("valid severity rejected: %v", err)
+	}
+	if cfg.Alerting.QuietHoursMinSeverity != "critical" {
+		t.Errorf("quiet_hours_min_severity = %q, want critical", cfg.Alerting.QuietHoursMinSeverity)
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Kalshi environments selectable with [kalshi] environment
+const (
+	EnvironmentProd = "prod"
+	EnvironmentDemo = "demo"
+)
+
+// environmentURLs are the REST and WebSocket URLs each environment defaults to
+var environmentURLs = map[string]struct{ rest, ws string }{
+	EnvironmentProd: {"https://api.elections.kalshi.com/trade-api/v2", "wss://api.elections.kalshi.com/trade-api/v2/ws"},
+	EnvironmentDemo: {"https://demo-api.kalshi.co/trade-api/v2", "wss://demo-api.kalshi.co/trade-api/v2/ws"},
+}
+
+// applyEnvironment fills whichever Kalshi URLs weren't set explicitly with the
+// environment's defaults
+func (k *KalshiConfig) applyEnvironment() error {
+	if k.Environment == "" {
+		k.Environment = EnvironmentProd
+	}
+	urls, ok := environmentURLs[k.Environment]
+	if !ok {
+		return fmt.Errorf("unknown kalshi environment %q (want %q or %q)", k.Environment, EnvironmentProd, EnvironmentDemo)
+	}
+
+	if k.APIBaseURL == "" {
+		k.APIBaseURL = urls.rest
+	}
+	if k.WebSocketURL == "" {
+		k.WebSocketURL = urls.ws
+	}
+	return nil
+}
+
+// EnvironmentWarnings reports REST and WebSocket URLs that point at different
+// Kalshi environments from each other or from the configured one. URLs on
+// other hosts (e.g. a local proxy) aren't checked.
+func (k KalshiConfig) EnvironmentWarnings() []string {
+	restEnv := urlEnvironment(k.APIBaseURL)
+	wsEnv := urlEnvironment(k.WebSocketURL)
+
+	var warnings []string
+	if restEnv != "" && wsEnv != "" && restEnv != wsEnv {
+		warnings = append(warnings, fmt.Sprintf("Kalshi REST URL %s is %s but WebSocket URL %s is %s", k.APIBaseURL, restEnv, k.WebSocketURL, wsEnv))
+	}
+	for _, u := range []struct{ name, url, env string }{
+		{"REST", k.APIBaseURL, restEnv},
+		{"WebSocket", k.WebSocketURL, wsEnv},
+	} {
+		if u.env != "" && u.env != k.Environment {
+			warnings = append(warnings, fmt.Sprintf("Kalshi environment is %s but the %s URL %s is %s", k.Environment, u.name, u.url, u.env))
+		}
+	}
+	return warnings
+}
+
+// urlEnvironment classifies a Kalshi URL by host, returning "" for hosts that
+// aren't Kalshi's
+func urlEnvironment(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case strings.HasSuffix(host, "kalshi.co") && strings.Contains(host, "demo"):
+		return EnvironmentDemo
+	case strings.HasSuffix(host, "kalshi.com"):
+		return EnvironmentProd
+	default:
+		return ""
+	}
+}
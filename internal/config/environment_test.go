@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDemoEnvironmentDefaults(t *testing.T) {
+	k := KalshiConfig{Environment: EnvironmentDemo}
+	if err := k.applyEnvironment(); err != nil {
+		t.Fatal(err)
+	}
+	demo := environmentURLs[EnvironmentDemo]
+	if k.APIBaseURL != demo.rest || k.WebSocketURL != demo.ws {
+		t.Errorf("demo URLs = %s / %s, want %s / %s", k.APIBaseURL, k.WebSocketURL, demo.rest, demo.ws)
+	}
+	if warnings := k.EnvironmentWarnings(); len(warnings) != 0 {
+		t.Errorf("consistent demo URLs warned: %v", warnings)
+	}
+
+	if err := (&KalshiConfig{Environment: "staging"}).applyEnvironment(); err == nil {
+		t.Error("unknown environment accepted")
+	}
+}
+
+func TestEnvironmentMismatchWarns(t *testing.T) {
+	prod, demo := environmentURLs[EnvironmentProd], environmentURLs[EnvironmentDemo]
+	k := KalshiConfig{Environment: EnvironmentDemo, APIBaseURL: demo.rest, WebSocketURL: prod.ws}
+	warnings := k.EnvironmentWarnings()
+	if len(warnings) == 0 {
+		t.Fatal("demo REST with prod WebSocket didn't warn")
+	}
+	if joined := strings.Join(warnings, "\n"); !strings.Contains(joined, prod.ws) {
+		t.Errorf("warnings don't name the mismatched URL: %v", warnings)
+	}
+
+	// A proxy on another host isn't classified
+	local := KalshiConfig{Environment: EnvironmentProd, APIBaseURL: "http://localhost:8080", WebSocketURL: prod.ws}
+	if warnings := local.EnvironmentWarnings(); len(warnings) != 0 {
+		t.Errorf("local proxy warned: %v", warnings)
+	}
+}
+
+func TestEnvironmentEnvVarWorksWithDefaultConfig(t *testing.T) {
+	t.Setenv("KALSHI__KALSHI__ENVIRONMENT", "demo")
+	cfg, err := Load("../../config/default.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Kalshi.Environment != EnvironmentDemo {
+		t.Errorf("environment = %q under the shipped config, want the env var's demo", cfg.Kalshi.Environment)
+	}
+	if demo := environmentURLs[EnvironmentDemo]; cfg.Kalshi.APIBaseURL != demo.rest {
+		t.Errorf("REST URL = %s, want the demo default %s", cfg.Kalshi.APIBaseURL, demo.rest)
+	}
+}
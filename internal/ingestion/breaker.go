@@ -0,0 +1,123 @@
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of making a REST call while the circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("Kalshi REST circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerStatus is a snapshot of the REST circuit breaker for health reporting
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// circuitBreaker stops REST calls during a Kalshi outage. After threshold
+// consecutive failures it opens and rejects calls for cooldown, then lets a
+// single probe through; the probe's outcome closes or reopens it.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+
+	state     string
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// allow reports whether a call may go ahead. Each allowed call must be
+// followed by record.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Before(b.openUntil) {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		fmt.Println("Kalshi REST circuit breaker half-open, probing")
+		return true
+	case BreakerHalfOpen:
+		// One probe at a time
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record notes the outcome of an allowed call
+func (b *circuitBreaker) record(failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		if b.state != BreakerClosed {
+			fmt.Println("Kalshi REST circuit breaker closed")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.threshold > 0 && (b.state == BreakerHalfOpen || b.failures >= b.threshold) {
+		if b.state != BreakerOpen {
+			fmt.Printf("Kalshi REST circuit breaker open after %d consecutive failures, pausing %s\n", b.failures, b.cooldown)
+		}
+		b.state = BreakerOpen
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// retryIn is how long until an open breaker lets a probe through
+func (b *circuitBreaker) retryIn(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen || !now.Before(b.openUntil) {
+		return time.Second // half-open with a probe in flight, or about to be
+	}
+	return b.openUntil.Sub(now)
+}
+
+func (b *circuitBreaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state == BreakerOpen {
+		openUntil := b.openUntil
+		status.OpenUntil = &openUntil
+	}
+	return status
+}
@@ -0,0 +1,82 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerTripsAndSkipsCalls(t *testing.T) {
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	client, _ := newTestRESTClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"orderbook_fp": {"yes_dollars": [], "no_dollars": []}}`))
+	}))
+	client.breaker = newCircuitBreaker(3, 50*time.Millisecond)
+	ctx := context.Background()
+
+	// Three consecutive failures trip it
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetOrderbook(ctx, "MKT"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want the 503", i+1, err)
+		}
+	}
+	if status := client.BreakerStatus(); status.State != BreakerOpen || status.ConsecutiveFailures != 3 || status.OpenUntil == nil {
+		t.Fatalf("status after 3 failures = %+v, want open", status)
+	}
+
+	// While open, calls fail fast without reaching Kalshi
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetOrderbook(ctx, "MKT"); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call while open: err = %v, want ErrCircuitOpen", err)
+		}
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("%d requests reached the server, want 3", n)
+	}
+
+	// After the cooldown a failed probe reopens it at once
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.GetOrderbook(ctx, "MKT"); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe: err = %v, want the 503", err)
+	}
+	if status := client.BreakerStatus(); status.State != BreakerOpen {
+		t.Fatalf("status after a failed probe = %+v, want open", status)
+	}
+
+	// A successful probe closes it
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.GetOrderbook(ctx, "MKT"); err != nil {
+		t.Fatalf("probe after recovery: %v", err)
+	}
+	if status := client.BreakerStatus(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("status after a good probe = %+v, want closed", status)
+	}
+	if n := requests.Load(); n != 5 {
+		t.Errorf("%d requests reached the server, want 3 failures and 2 probes", n)
+	}
+}
+
+func TestBreakerAllowsOneProbeAtATime(t *testing.T) {
+	b := newCircuitBreaker(1, time.Minute)
+	now := time.Now()
+	b.allow(now)
+	b.record(true, now)
+
+	later := now.Add(2 * time.Minute)
+	if !b.allow(later) {
+		t.Fatal("no probe after the cooldown")
+	}
+	if b.allow(later) {
+		t.Error("second call allowed while the probe is in flight")
+	}
+}
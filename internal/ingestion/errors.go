@@ -0,0 +1,105 @@
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sentinel errors for Kalshi responses that callers react to rather than just
+// log. Errors returned by the REST client wrap these; test with errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrNotFound     = errors.New("not found")
+)
+
+// ErrAuthNotConfigured is returned when Kalshi rejects a request as
+// unauthenticated and no API credentials are configured. Errors wrapping it
+// also match ErrUnauthorized.
+var ErrAuthNotConfigured = errors.New("Kalshi requires authentication but no API credentials are configured (set KALSHI__KALSHI__API_KEY_ID and KALSHI__KALSHI__PRIVATE_KEY_PATH)")
+
+// defaultRateLimitBackoff is how long to back off after a 429 that doesn't
+// say when to retry
+const defaultRateLimitBackoff = 5 * time.Second
+
+// defaultUnauthorizedBackoff is how long the market poller waits before
+// retrying after Kalshi rejects its credentials
+const defaultUnauthorizedBackoff = time.Minute
+
+// StatusError is a non-200 response from the Kalshi REST API
+type StatusError struct {
+	Resource   string // what was being fetched, e.g. "orderbook"
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header of a 429, if any
+
+	kind           error // one of the sentinels above, or nil
+	authConfigured bool
+}
+
+func (e *StatusError) Error() string {
+	switch {
+	case e.kind == ErrUnauthorized && !e.authConfigured:
+		return fmt.Sprintf("failed to fetch %s: %v", e.Resource, ErrAuthNotConfigured)
+	case e.kind == ErrUnauthorized:
+		return fmt.Sprintf("failed to fetch %s: Kalshi rejected the configured credentials (status %d): %s", e.Resource, e.StatusCode, e.Body)
+	default:
+		return fmt.Sprintf("failed to fetch %s: status %d, body: %s", e.Resource, e.StatusCode, e.Body)
+	}
+}
+
+func (e *StatusError) Unwrap() []error {
+	if e.kind == nil {
+		return nil
+	}
+	if e.kind == ErrUnauthorized && !e.authConfigured {
+		return []error{ErrUnauthorized, ErrAuthNotConfigured}
+	}
+	return []error{e.kind}
+}
+
+// newStatusError classifies a non-200 response. authConfigured distinguishes
+// rejected credentials from missing ones on a 401/403.
+func newStatusError(resource string, statusCode int, header http.Header, body string, authConfigured bool) *StatusError {
+	e := &StatusError{Resource: resource, StatusCode: statusCode, Body: body, authConfigured: authConfigured}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.kind = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		e.kind = ErrRateLimited
+		e.RetryAfter = parseRetryAfter(header.Get("Retry-After"))
+	case http.StatusNotFound:
+		e.kind = ErrNotFound
+	}
+	return e
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, returning 0 if it's absent or unparseable
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// rateLimitBackoff is how long to pause after err, which wraps ErrRateLimited
+func rateLimitBackoff(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter
+	}
+	return defaultRateLimitBackoff
+}
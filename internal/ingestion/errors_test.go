@@ -0,0 +1,131 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnauthorizedWithoutCredentials(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		client, _ := newTestRESTClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"code":"missing_auth_header"}`))
+		}))
+
+		_, err := client.GetOrderbook(context.Background(), "MKT")
+		if err == nil {
+			t.Fatalf("%d: no error", status)
+		}
+		if !errors.Is(err, ErrUnauthorized) || !errors.Is(err, ErrAuthNotConfigured) {
+			t.Errorf("%d: error %v doesn't match ErrUnauthorized and ErrAuthNotConfigured", status, err)
+		}
+		if msg := err.Error(); !strings.Contains(msg, "KALSHI__KALSHI__API_KEY_ID") || strings.Contains(msg, "missing_auth_header") {
+			t.Errorf("%d: error %q should explain the missing credentials, not echo the body", status, msg)
+		}
+	}
+
+	// With credentials configured, a 401 means they were rejected
+	err := newStatusError("orderbook", http.StatusUnauthorized, http.Header{}, "bad signature", true)
+	if !errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrAuthNotConfigured) {
+		t.Errorf("configured credentials: error %v, want ErrUnauthorized only", err)
+	}
+	if !strings.Contains(err.Error(), "rejected the configured credentials") {
+		t.Errorf("configured credentials: error %q should say they were rejected", err)
+	}
+}
+
+func TestStatusErrorTypePerStatusCode(t *testing.T) {
+	tests := []struct {
+		status     int
+		header     string
+		want       error
+		retryAfter time.Duration
+	}{
+		{http.StatusUnauthorized, "", ErrUnauthorized, 0},
+		{http.StatusForbidden, "", ErrUnauthorized, 0},
+		{http.StatusTooManyRequests, "7", ErrRateLimited, 7 * time.Second},
+		{http.StatusTooManyRequests, "", ErrRateLimited, 0},
+		{http.StatusNotFound, "", ErrNotFound, 0},
+		{http.StatusInternalServerError, "", nil, 0},
+		{http.StatusBadGateway, "", nil, 0},
+	}
+	sentinels := []error{ErrUnauthorized, ErrRateLimited, ErrNotFound}
+
+	for _, tt := range tests {
+		client, _ := newTestRESTClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.header != "" {
+				w.Header().Set("Retry-After", tt.header)
+			}
+			w.WriteHeader(tt.status)
+		}))
+
+		_, err := client.GetOrderbook(context.Background(), "MKT")
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+			t.Errorf("%d: error %v, want a StatusError with that code", tt.status, err)
+			continue
+		}
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("%d: errors.Is(%v) = %v", tt.status, sentinel, got)
+			}
+		}
+		if statusErr.RetryAfter != tt.retryAfter {
+			t.Errorf("%d: retry after %v, want %v", tt.status, statusErr.RetryAfter, tt.retryAfter)
+		}
+	}
+
+	if got := rateLimitBackoff(newStatusError("markets", http.StatusTooManyRequests, http.Header{}, "", false)); got != defaultRateLimitBackoff {
+		t.Errorf("429 without Retry-After backs off %v, want the default %v", got, defaultRateLimitBackoff)
+	}
+}
+
+func TestPollMarketsRetriesAfterUnauthorized(t *testing.T) {
+	stub := &marketsStub{
+		series:  []string{"SER"},
+		markets: map[string][]KalshiMarket{"SER": {{Ticker: "SER-A", Status: "active"}}},
+		fail:    map[string]int{"SER": http.StatusUnauthorized},
+	}
+	client, engine := newTestRESTClient(t, stub)
+	client.unauthorizedBackoff = 10 * time.Millisecond
+	client.refreshInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.PollMarkets(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for stub.marketRequests() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d market requests after a 401, want retries", stub.marketRequests())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("PollMarkets returned %v on a 401, want it to keep retrying", err)
+	default:
+	}
+
+	// Once the credentials are accepted again, discovery carries on
+	stub.mu.Lock()
+	delete(stub.fail, "SER")
+	stub.mu.Unlock()
+	for {
+		if _, ok := engine.GetMarket("SER-A"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("market not registered after the 401s stopped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
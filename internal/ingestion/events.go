@@ -0,0 +1,96 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+type GetEventResponse struct {
+	Event   KalshiEvent         `json:"event"`
+	Markets []KalshiEventMarket `json:"markets"`
+}
+
+type KalshiEvent struct {
+	EventTicker string `json:"event_ticker"`
+	Title       string `json:"title"`
+	SubTitle    string `json:"sub_title,omitempty"`
+}
+
+type KalshiEventMarket struct {
+	Ticker         string `json:"ticker"`
+	Subtitle       string `json:"subtitle,omitempty"`
+	RulesPrimary   string `json:"rules_primary,omitempty"`
+	RulesSecondary string `json:"rules_secondary,omitempty"`
+}
+
+// enrichEvents fetches rules and descriptions for events whose market set has
+// changed since the last fetch. eventMarkets maps event_ticker -> market count.
+func (c *RESTClient) enrichEvents(ctx context.Context, eventMarkets map[string]int) {
+	for eventTicker, count := range eventMarkets {
+		if c.enrichedEvents[eventTicker] == count {
+			continue // Unchanged since last fetch
+		}
+
+		if err := c.waitRateLimit(ctx); err != nil {
+			return
+		}
+
+		resp, err := c.fetchEvent(ctx, eventTicker)
+		if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrCircuitOpen) {
+			// Leave the remaining events for the next cycle
+			fmt.Printf("Deferring event metadata fetches: %v\n", err)
+			return
+		}
+		if errors.Is(err, ErrNotFound) {
+			// Don't refetch a missing event until its market set changes
+			c.enrichedEvents[eventTicker] = count
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error fetching event %s: %v\n", eventTicker, err)
+			continue
+		}
+
+		for _, m := range resp.Markets {
+			c.state.SetMarketMetadata(m.Ticker, &state.MarketMetadata{
+				EventTitle:     resp.Event.Title,
+				EventSubtitle:  resp.Event.SubTitle,
+				Subtitle:       m.Subtitle,
+				RulesPrimary:   m.RulesPrimary,
+				RulesSecondary: m.RulesSecondary,
+			})
+		}
+
+		c.enrichedEvents[eventTicker] = count
+	}
+}
+
+func (c *RESTClient) fetchEvent(ctx context.Context, eventTicker string) (*GetEventResponse, error) {
+	url := c.baseURL + "/events/" + eventTicker
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.statusError("event", resp)
+	}
+
+	var eventResp GetEventResponse
+	if err := json.NewDecoder(resp.Body).Decode(&eventResp); err != nil {
+		return nil, err
+	}
+
+	return &eventResp, nil
+}
@@ -0,0 +1,71 @@
+package ingestion
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// loggingTransport logs each REST request and its full response body, for
+// diagnosing parse failures when Kalshi changes a payload. Auth headers are
+// redacted and logged bodies are truncated to maxBytes.
+type loggingTransport struct {
+	next     http.RoundTripper // nil means http.DefaultTransport
+	maxBytes int
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	fmt.Printf("HTTP request: %s %s headers=%s\n", req.Method, req.URL, redactHeaders(req.Header))
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		fmt.Printf("HTTP error: %s %s: %v\n", req.Method, req.URL, err)
+		return nil, err
+	}
+
+	// Read the whole body so it can be logged, then hand the caller a copy
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		fmt.Printf("HTTP response: %s %s status=%d (body read failed: %v)\n", req.Method, req.URL, resp.StatusCode, err)
+		return resp, nil
+	}
+
+	fmt.Printf("HTTP response: %s %s status=%d body=%s\n", req.Method, req.URL, resp.StatusCode, t.truncate(body))
+	return resp, nil
+}
+
+func (t *loggingTransport) truncate(body []byte) string {
+	if t.maxBytes <= 0 || len(body) <= t.maxBytes {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", body[:t.maxBytes], len(body)-t.maxBytes)
+}
+
+// redactHeaders formats headers for logging with Kalshi auth values hidden
+func redactHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := strings.Join(header[k], ",")
+		if strings.HasPrefix(strings.ToUpper(k), "KALSHI-ACCESS-") || strings.EqualFold(k, "Authorization") {
+			value = "[redacted]"
+		}
+		parts = append(parts, k+"="+value)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
@@ -0,0 +1,94 @@
+package ingestion
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestBodiesLoggedOnlyInDebugMode(t *testing.T) {
+	body := `{"orderbook_fp": {"yes_dollars": [["0.4500", "100.00"]], "no_dollars": [["0.5300", "100.00"]]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	fetch := func(ingestionCfg config.IngestionConfig) string {
+		ingestionCfg.RateLimitPerSecond = 1000
+		client, err := NewRESTClient(config.KalshiConfig{APIBaseURL: server.URL}, ingestionCfg, state.NewEngine())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return captureStdout(t, func() {
+			if _, err := client.GetOrderbook(context.Background(), "MKT"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	if out := fetch(config.IngestionConfig{}); strings.Contains(out, "HTTP re") || strings.Contains(out, "yes_dollars") {
+		t.Errorf("bodies logged with debug logging off:\n%s", out)
+	}
+
+	out := fetch(config.IngestionConfig{LogHTTPBodies: true, LogHTTPMaxBytes: 30})
+	if !strings.Contains(out, "HTTP request: GET "+server.URL+"/markets/MKT/orderbook") {
+		t.Errorf("request not logged:\n%s", out)
+	}
+	want := "body=" + body[:30] + "... (" + strconv.Itoa(len(body)-30) + " more bytes)"
+	if !strings.Contains(out, want) {
+		t.Errorf("response body not logged truncated to 30 bytes; want %q in:\n%s", want, out)
+	}
+
+	// Without a size limit the whole body is logged
+	out = fetch(config.IngestionConfig{LogHTTPBodies: true})
+	if !strings.Contains(out, "body="+body) {
+		t.Errorf("untruncated body not logged:\n%s", out)
+	}
+}
+
+func TestRedactHeadersHidesAuth(t *testing.T) {
+	header := http.Header{}
+	header.Set("KALSHI-ACCESS-KEY", "key-id")
+	header.Set("KALSHI-ACCESS-SIGNATURE", "sig")
+	header.Set("Authorization", "Bearer token")
+	header.Set("Accept", "application/json")
+
+	got := redactHeaders(header)
+	for _, secret := range []string{"key-id", "sig", "Bearer token"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("redacted headers %s leak %q", got, secret)
+		}
+	}
+	if !strings.Contains(got, "Accept=application/json") {
+		t.Errorf("redacted headers %s dropped a non-auth header", got)
+	}
+}
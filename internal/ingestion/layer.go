@@ -2,7 +2,10 @@ package ingestion
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/kalshi-signal-feed/internal/config"
@@ -14,8 +17,21 @@ type Layer struct {
 	wsHandler   *WebSocketHandler
 	state       *state.Engine
 	pollInterval time.Duration
+	maxParseFailureRatio float64
+
+	quietPollCycles int // quiet markets are fetched every this many cycles
+	pollCycle       int // orderbook cycles run, for spacing out quiet markets
+
+	// Out-of-band full-book refetches requested by the WebSocket handler
+	resnapshots    chan string
+	resnapshotMu   sync.Mutex
+	lastResnapshot map[string]time.Time
 }
 
+// quietActivityRate is the decayed trade rate (trades per minute) below which
+// a market is polled every quietPollCycles cycles instead of every cycle
+const quietActivityRate = 0.1
+
 func NewLayer(kalshiCfg config.KalshiConfig, ingestionCfg config.IngestionConfig, stateEngine *state.Engine) (*Layer, error) {
 	restClient, err := NewRESTClient(kalshiCfg, ingestionCfg, stateEngine)
 	if err != nil {
@@ -23,13 +39,25 @@ func NewLayer(kalshiCfg config.KalshiConfig, ingestionCfg config.IngestionConfig
 	}
 
 	wsHandler := NewWebSocketHandler(kalshiCfg, ingestionCfg, stateEngine)
+	restClient.SetSubscriber(wsHandler)
 
-	return &Layer{
+	layer := &Layer{
 		restClient:   restClient,
 		wsHandler:    wsHandler,
 		state:        stateEngine,
 		pollInterval: time.Duration(ingestionCfg.RESTPollIntervalSecs) * time.Second,
-	}, nil
+		maxParseFailureRatio: ingestionCfg.MaxLevelParseFailureRatio,
+		quietPollCycles:      max(ingestionCfg.QuietPollCycles, 1),
+		resnapshots:          make(chan string, resnapshotQueueSize),
+		lastResnapshot:       make(map[string]time.Time),
+	}
+	wsHandler.SetResnapshotter(layer)
+	return layer, nil
+}
+
+// BreakerStatus reports the state of the REST circuit breaker
+func (l *Layer) BreakerStatus() BreakerStatus {
+	return l.restClient.BreakerStatus()
 }
 
 func (l *Layer) Run(ctx context.Context) error {
@@ -45,6 +73,9 @@ func (l *Layer) Run(ctx context.Context) error {
 		l.PollOrderbooks(ctx)
 	}()
 
+	// Refetch books that trades show to have drifted between polls
+	go l.runResnapshots(ctx)
+
 	// Start REST polling for markets
 	if err := l.restClient.PollMarkets(ctx); err != nil && err != context.Canceled {
 		return fmt.Errorf("REST client error: %w", err)
@@ -75,34 +106,99 @@ func (l *Layer) fetchAllOrderbooks(ctx context.Context) {
 	markets := l.state.GetAllMarkets()
 	activeCount := 0
 	successCount := 0
-	
+	skippedQuiet := 0
+
+	now := time.Now()
+	quietDue := l.pollCycle%l.quietPollCycles == 0
+	l.pollCycle++
+
+	// Active markets plus any pinned markets, regardless of status. Markets of
+	// the same event are fetched back to back, so no-arb can check each group
+	// as soon as its legs are in rather than after the whole cycle. Events are
+	// ordered by their busiest leg's activity score, so a cycle cut short by
+	// rate limiting has already refreshed the markets that trade most.
+	activity := make(map[string]float64, len(markets))
+	eventActivity := make(map[string]float64)
+	for _, market := range markets {
+		score := l.state.ActivityScore(market.Ticker, now)
+		activity[market.Ticker] = score
+		eventActivity[market.EventTicker] = max(eventActivity[market.EventTicker], score)
+	}
+	sort.Slice(markets, func(i, j int) bool {
+		a, b := markets[i], markets[j]
+		if a.EventTicker != b.EventTicker {
+			if eventActivity[a.EventTicker] != eventActivity[b.EventTicker] {
+				return eventActivity[a.EventTicker] > eventActivity[b.EventTicker]
+			}
+			return a.EventTicker < b.EventTicker
+		}
+		return a.Ticker < b.Ticker
+	})
+	tickers := make([]string, 0, len(markets))
+	seen := make(map[string]bool)
 	for _, market := range markets {
-		if market.Status != state.StatusActive {
+		pinned := l.state.IsPinned(market.Ticker)
+		if market.Status != state.StatusActive && !pinned {
 			continue
 		}
+		seen[market.Ticker] = true
+		// Quiet markets sit out cycles between their turns, once they have a book
+		if !quietDue && !pinned && activity[market.Ticker] < quietActivityRate && l.state.BookFetched(market.Ticker) {
+			skippedQuiet++
+			continue
+		}
+		tickers = append(tickers, market.Ticker)
+	}
+	for _, ticker := range l.state.PinnedMarkets() {
+		if !seen[ticker] {
+			tickers = append(tickers, ticker)
+		}
+	}
+	
+	for _, ticker := range tickers {
 		activeCount++
 
 		// Use a context with timeout for each orderbook fetch
 		fetchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		orderbook, err := l.restClient.GetOrderbook(fetchCtx, market.Ticker)
+		orderbook, err := l.restClient.GetOrderbook(fetchCtx, ticker)
 		cancel()
 		
+		if errors.Is(err, ErrRateLimited) {
+			// Skip the rest of this cycle and pause before the next
+			backoff := rateLimitBackoff(err)
+			fmt.Printf("Orderbook poll rate limited, pausing %s\n", backoff)
+			if sleepContext(ctx, backoff) != nil {
+				return
+			}
+			break
+		}
+		if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrCircuitOpen) {
+			// Every remaining fetch would fail the same way
+			fmt.Printf("Orderbook poll aborted: %v\n", err)
+			break
+		}
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
 		if err != nil {
 			// Only log errors occasionally to avoid spam
 			if activeCount%10 == 0 {
-				fmt.Printf("Error fetching orderbook for %s: %v\n", market.Ticker, err)
+				fmt.Printf("Error fetching orderbook for %s: %v\n", ticker, err)
 			}
 			continue
 		}
 
-		ob := state.NewOrderbook(market.Ticker)
-		ob.UpdateFromKalshi(orderbook)
-		l.state.UpdateOrderbook(market.Ticker, ob)
+		ob := state.NewOrderbook(ticker)
+		if err := ob.UpdateFromKalshi(orderbook, l.maxParseFailureRatio); err != nil {
+			fmt.Printf("%v\n", err)
+			continue
+		}
+		l.state.UpdateOrderbook(ticker, ob)
 		successCount++
 	}
 	
 	if activeCount > 0 {
-		fmt.Printf("Orderbook poll: %d/%d active markets updated\n", successCount, activeCount)
+		fmt.Printf("Orderbook poll: %d/%d active markets updated (%d quiet skipped)\n", successCount, activeCount, skippedQuiet)
 	}
 }
 
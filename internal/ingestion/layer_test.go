@@ -0,0 +1,109 @@
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// orderbookStub serves a small two-sided book for every market and records
+// which tickers were fetched
+type orderbookStub struct {
+	mu      sync.Mutex
+	fetched []string
+}
+
+func (s *orderbookStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/markets/") || !strings.HasSuffix(r.URL.Path, "/orderbook") {
+		http.NotFound(w, r)
+		return
+	}
+	ticker := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/markets/"), "/orderbook")
+	s.mu.Lock()
+	s.fetched = append(s.fetched, ticker)
+	s.mu.Unlock()
+	w.Write([]byte(`{"orderbook_fp": {"yes_dollars": [["0.4500", "100.00"]], "no_dollars": [["0.5300", "100.00"]]}}`))
+}
+
+func (s *orderbookStub) fetchedTickers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.fetched...)
+}
+
+// newTestLayer builds a layer around a REST client pointed at handler
+func newTestLayer(t *testing.T, handler http.Handler, cfg config.IngestionConfig) (*Layer, *state.Engine) {
+	t.Helper()
+	client, engine := newTestRESTClient(t, handler)
+	return &Layer{
+		restClient:           client,
+		state:                engine,
+		maxParseFailureRatio: cfg.MaxLevelParseFailureRatio,
+		quietPollCycles:      max(cfg.QuietPollCycles, 1),
+		resnapshots:          make(chan string, resnapshotQueueSize),
+		lastResnapshot:       make(map[string]time.Time),
+	}, engine
+}
+
+func TestPinnedInactiveMarketIsPolled(t *testing.T) {
+	stub := &orderbookStub{}
+	layer, engine := newTestLayer(t, stub, config.IngestionConfig{QuietPollCycles: 1})
+
+	engine.RegisterMarket(&state.Market{Ticker: "OPEN", Status: state.StatusActive})
+	engine.RegisterMarket(&state.Market{Ticker: "CLOSED", Status: state.StatusClosed})
+	engine.RegisterMarket(&state.Market{Ticker: "PINNED", Status: state.StatusInactive})
+	if err := engine.PinMarket("PINNED"); err != nil {
+		t.Fatal(err)
+	}
+
+	layer.fetchAllOrderbooks(context.Background())
+
+	fetched := make(map[string]bool)
+	for _, ticker := range stub.fetchedTickers() {
+		fetched[ticker] = true
+	}
+	if !fetched["PINNED"] || !fetched["OPEN"] || fetched["CLOSED"] {
+		t.Fatalf("fetched %v, want OPEN and PINNED only", stub.fetchedTickers())
+	}
+	if ob, _ := engine.GetOrderbook("PINNED"); len(ob.Bids) == 0 {
+		t.Error("pinned market's book was not stored")
+	}
+}
+
+func TestOrderbooksFetchedGroupedByEvent(t *testing.T) {
+	stub := &orderbookStub{}
+	layer, engine := newTestLayer(t, stub, config.IngestionConfig{QuietPollCycles: 1})
+
+	// Ticker order interleaves the events, as map order might
+	markets := map[string]string{
+		"A-1": "EV-2", "B-1": "EV-1", "C-1": "EV-3", "D-1": "EV-2",
+		"E-1": "EV-1", "F-1": "EV-3", "G-1": "EV-2", "H-1": "EV-1",
+	}
+	for ticker, event := range markets {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive, EventTicker: event})
+	}
+
+	layer.fetchAllOrderbooks(context.Background())
+
+	fetched := stub.fetchedTickers()
+	if len(fetched) != len(markets) {
+		t.Fatalf("fetched %v, want all %d markets", fetched, len(markets))
+	}
+	// Once an event's run of fetches ends, none of its markets come later
+	finished := make(map[string]bool)
+	for i, ticker := range fetched {
+		event := markets[ticker]
+		if finished[event] {
+			t.Fatalf("fetch order %v splits %s", fetched, event)
+		}
+		if i > 0 && markets[fetched[i-1]] != event {
+			finished[markets[fetched[i-1]]] = true
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package ingestion
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// waitRateLimit blocks until the rate limiter admits another request, then
+// waits a random extra delay of up to rateJitter so concurrent pollers that
+// become ready together don't hit the API in lockstep.
+func (c *RESTClient) waitRateLimit(ctx context.Context) error {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if c.rateJitter <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, time.Duration(rand.Int63n(int64(c.rateJitter))))
+}
@@ -0,0 +1,63 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// waitTimes calls waitRateLimit n times back to back and returns when each
+// call returned
+func waitTimes(t *testing.T, ingestionCfg config.IngestionConfig, n int) []time.Time {
+	t.Helper()
+	client, err := NewRESTClient(config.KalshiConfig{}, ingestionCfg, state.NewEngine())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	times := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		if err := client.waitRateLimit(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		times = append(times, time.Now())
+	}
+	return times
+}
+
+func TestRateLimiterSpacesRequestsEvenly(t *testing.T) {
+	// 50/s with a burst of 1: request i is admitted no sooner than i*20ms
+	// after the first. A late wakeup can shorten the gap after it, but
+	// requests never clump ahead of that schedule.
+	times := waitTimes(t, config.IngestionConfig{RateLimitPerSecond: 50, RateLimitBurst: 1}, 11)
+	for i := 1; i < len(times); i++ {
+		if elapsed, want := times[i].Sub(times[0]), time.Duration(i)*20*time.Millisecond; elapsed < want-2*time.Millisecond {
+			t.Errorf("request %d came %s after the first, want at least %s", i, elapsed, want)
+		}
+	}
+}
+
+func TestRateLimiterBurstIsSeparateFromRate(t *testing.T) {
+	// Same rate, burst of 5: the first five go at once, then 20ms spacing
+	times := waitTimes(t, config.IngestionConfig{RateLimitPerSecond: 50, RateLimitBurst: 5}, 7)
+	if burst := times[4].Sub(times[0]); burst > 10*time.Millisecond {
+		t.Errorf("first 5 requests took %s, want them admitted together", burst)
+	}
+	for i := 5; i < len(times); i++ {
+		if elapsed, want := times[i].Sub(times[0]), time.Duration(i-4)*20*time.Millisecond; elapsed < want-2*time.Millisecond {
+			t.Errorf("request %d came %s after the first, want at least %s", i, elapsed, want)
+		}
+	}
+}
+
+func TestRateLimiterJitterOnlyAddsDelay(t *testing.T) {
+	times := waitTimes(t, config.IngestionConfig{RateLimitPerSecond: 50, RateLimitBurst: 1, RateLimitJitterMs: 10}, 6)
+	for i := 1; i < len(times); i++ {
+		if elapsed, want := times[i].Sub(times[0]), time.Duration(i)*20*time.Millisecond; elapsed < want-10*time.Millisecond {
+			t.Errorf("request %d came %s after the first, want about %s plus jitter", i, elapsed, want)
+		}
+	}
+}
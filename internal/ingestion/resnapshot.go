@@ -0,0 +1,100 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+const (
+	// resnapshotCooldown is the least time between out-of-band refetches of
+	// one market, so a burst of off-book trades costs one REST call
+	resnapshotCooldown = 10 * time.Second
+	// resnapshotQueueSize bounds pending refetches; requests beyond it are
+	// dropped and the next poll covers them
+	resnapshotQueueSize = 100
+)
+
+// bookResnapshotter is the part of the layer the WebSocket handler asks for a
+// full-book refetch when a market's book has evidently drifted
+type bookResnapshotter interface {
+	RequestResnapshot(ticker, reason string) bool
+}
+
+// SetResnapshotter has trades that print outside the stored touch request a
+// full-book refetch
+func (w *WebSocketHandler) SetResnapshotter(resnapshotter bookResnapshotter) {
+	w.resnapshotter = resnapshotter
+}
+
+// checkTradeAgainstBook requests a refetch when a trade printed beyond the
+// stored best bid or ask, meaning book updates were missed
+func (w *WebSocketHandler) checkTradeAgainstBook(trade *state.Trade) {
+	if w.resnapshotter == nil {
+		return
+	}
+	ob, ok := w.state.GetOrderbook(trade.MarketTicker)
+	if !ok || !ob.OutsideTouch(trade.Price) {
+		return
+	}
+
+	bid, ask := "-", "-"
+	if len(ob.Bids) > 0 {
+		bid = fmt.Sprintf("%d¢", ob.Bids[0].Price)
+	}
+	if len(ob.Asks) > 0 {
+		ask = fmt.Sprintf("%d¢", ob.Asks[0].Price)
+	}
+	reason := fmt.Sprintf("trade at %d¢ outside %s/%s, book %s old",
+		trade.Price, bid, ask, time.Since(ob.LastUpdate).Round(time.Second))
+	w.resnapshotter.RequestResnapshot(trade.MarketTicker, reason)
+}
+
+// RequestResnapshot queues a full-book REST refetch of ticker, at most once
+// per resnapshotCooldown per market. It never blocks and reports whether the
+// request was queued.
+func (l *Layer) RequestResnapshot(ticker, reason string) bool {
+	now := time.Now()
+
+	l.resnapshotMu.Lock()
+	defer l.resnapshotMu.Unlock()
+
+	if last, ok := l.lastResnapshot[ticker]; ok && now.Sub(last) < resnapshotCooldown {
+		return false
+	}
+	select {
+	case l.resnapshots <- ticker:
+		l.lastResnapshot[ticker] = now
+		fmt.Printf("Stale book for %s (%s), refetching\n", ticker, reason)
+		return true
+	default:
+		return false
+	}
+}
+
+// runResnapshots serves queued refetches until ctx is done
+func (l *Layer) runResnapshots(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ticker := <-l.resnapshots:
+			fetchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			orderbook, err := l.restClient.GetOrderbook(fetchCtx, ticker)
+			cancel()
+			if err != nil {
+				fmt.Printf("Resnapshot of %s failed: %v\n", ticker, err)
+				continue
+			}
+
+			ob := state.NewOrderbook(ticker)
+			if err := ob.UpdateFromKalshi(orderbook, l.maxParseFailureRatio); err != nil {
+				fmt.Printf("%v\n", err)
+				continue
+			}
+			l.state.UpdateOrderbook(ticker, ob)
+		}
+	}
+}
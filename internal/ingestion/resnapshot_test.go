@@ -0,0 +1,77 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestOffBookTradeRequestsResnapshot(t *testing.T) {
+	stub := &orderbookStub{}
+	layer, engine := newTestLayer(t, stub, config.IngestionConfig{})
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{}, engine)
+	w.SetResnapshotter(layer)
+
+	// A stale book at 40/42 while the market has moved up to 45/47
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 40, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 42, Quantity: 100}}
+	engine.UpdateOrderbook("MKT", ob)
+
+	trade := func(price float64) {
+		t.Helper()
+		if err := w.handleTradeUpdate(map[string]interface{}{"ticker": "MKT", "price": price, "count": 10.0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Inside the touch is consistent with the book
+	trade(0.41)
+	if n := len(layer.resnapshots); n != 0 {
+		t.Fatalf("trade inside the touch queued %d refetches, want 0", n)
+	}
+
+	// Beyond the ask queues one refetch; a second print in the cooldown doesn't
+	trade(0.46)
+	trade(0.47)
+	if n := len(layer.resnapshots); n != 1 {
+		t.Fatalf("off-book trades queued %d refetches, want 1", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go layer.runResnapshots(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		ob, _ := engine.GetOrderbook("MKT")
+		if len(ob.Bids) > 0 && ob.Bids[0].Price == 45 && len(ob.Asks) > 0 && ob.Asks[0].Price == 47 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("book not refetched: bids %v asks %v", ob.Bids, ob.Asks)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if fetched := stub.fetchedTickers(); len(fetched) != 1 || fetched[0] != "MKT" {
+		t.Errorf("fetched %v, want [MKT]", fetched)
+	}
+}
+
+func TestTradeWithoutBookRequestsNoResnapshot(t *testing.T) {
+	layer, engine := newTestLayer(t, &orderbookStub{}, config.IngestionConfig{})
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{}, engine)
+	w.SetResnapshotter(layer)
+
+	// No stored book means no touch to be outside of; the poll fetches it
+	if err := w.handleTradeUpdate(map[string]interface{}{"ticker": "NOBOOK", "price": 0.9, "count": 1.0}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(layer.resnapshots); n != 0 {
+		t.Errorf("trade with no book queued %d refetches, want 0", n)
+	}
+}
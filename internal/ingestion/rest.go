@@ -3,6 +3,7 @@ package ingestion
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,8 +21,55 @@ type RESTClient struct {
 	client      *http.Client
 	state       *state.Engine
 	rateLimiter *rate.Limiter
+	rateJitter  time.Duration
+	breaker     *circuitBreaker
+
+	// Wait before retrying a market fetch Kalshi rejected as unauthorized
+	unauthorizedBackoff time.Duration
+
+	// Wait between full market refresh cycles
+	refreshInterval time.Duration
+
+	// event_ticker -> market count at last metadata fetch
+	enrichedEvents map[string]int
+
+	// Status strings already reported as unrecognised
+	unknownStatuses map[string]bool
+
+	// Live feed kept in step with the active markets found each cycle
+	subscriber  marketSubscriber
+	liveMarkets map[string]bool
+
+	// Active markets per series at its last complete fetch, carried over when
+	// a fetch fails so the series' markets aren't unsubscribed
+	seriesActive map[string]map[string]bool
+
+	// Markets that left the open listing and are awaiting a result
+	pendingResolutions map[string]bool
+
+	// Markets inactive this long are pruned from state (0 disables)
+	marketRetention time.Duration
+}
+
+// marketSubscriber is the part of the WebSocket handler the market poller drives
+type marketSubscriber interface {
+	Subscribe(tickers []string)
+	Unsubscribe(tickers []string)
 }
 
+// SetSubscriber has each poll cycle subscribe newly active markets and
+// unsubscribe ones that closed or dropped out of the listing
+func (c *RESTClient) SetSubscriber(subscriber marketSubscriber) {
+	c.subscriber = subscriber
+}
+
+const (
+	// defaultRequestTimeout applies when no positive request timeout is configured
+	defaultRequestTimeout = 30 * time.Second
+	// maxErrorBodyBytes bounds how much of an error response is read into a StatusError
+	maxErrorBodyBytes = 4096
+)
+
 type GetMarketsResponse struct {
 	Markets []KalshiMarket `json:"markets"`
 	Cursor  *string        `json:"cursor"`
@@ -33,14 +81,30 @@ type KalshiMarket struct {
 	Category       string  `json:"category"`
 	Status         string  `json:"status"`
 	ExpirationTime *string `json:"expiration_time"`
+	OpenTime       *string `json:"open_time,omitempty"`
+	CloseTime      *string `json:"close_time,omitempty"`
 	EventTicker    string  `json:"event_ticker"`
+	MarketType     string  `json:"market_type,omitempty"` // "binary" or "scalar"
 	YesSubTitle    string  `json:"yes_sub_title,omitempty"`
 	NoSubTitle     string  `json:"no_sub_title,omitempty"`
+	TickSize       int     `json:"tick_size,omitempty"`
+	Result         string  `json:"result,omitempty"` // "yes" or "no" once determined
+}
+
+type GetMarketResponse struct {
+	Market KalshiMarket `json:"market"`
 }
 
 func NewRESTClient(cfg config.KalshiConfig, ingestionCfg config.IngestionConfig, stateEngine *state.Engine) (*RESTClient, error) {
+	timeout := time.Duration(ingestionCfg.RequestTimeoutSecs) * time.Second
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout: timeout,
+	}
+	if ingestionCfg.LogHTTPBodies {
+		client.Transport = &loggingTransport{maxBytes: ingestionCfg.LogHTTPMaxBytes}
 	}
 
 	var auth *Auth
@@ -56,7 +120,11 @@ func NewRESTClient(cfg config.KalshiConfig, ingestionCfg config.IngestionConfig,
 		}
 	}
 
-	rateLimiter := rate.NewLimiter(rate.Limit(ingestionCfg.RateLimitPerSecond), ingestionCfg.RateLimitPerSecond)
+	burst := ingestionCfg.RateLimitBurst
+	if burst < 1 {
+		burst = 1
+	}
+	rateLimiter := rate.NewLimiter(rate.Limit(ingestionCfg.RateLimitPerSecond), burst)
 
 	return &RESTClient{
 		baseURL:     cfg.APIBaseURL,
@@ -64,6 +132,15 @@ func NewRESTClient(cfg config.KalshiConfig, ingestionCfg config.IngestionConfig,
 		client:      client,
 		state:       stateEngine,
 		rateLimiter: rateLimiter,
+		rateJitter:  time.Duration(max(ingestionCfg.RateLimitJitterMs, 0)) * time.Millisecond,
+		breaker:     newCircuitBreaker(ingestionCfg.BreakerFailureThreshold, time.Duration(ingestionCfg.BreakerCooldownSecs)*time.Second),
+		unauthorizedBackoff: defaultUnauthorizedBackoff,
+		refreshInterval: time.Duration(ingestionCfg.MarketRefreshIntervalSecs) * time.Second,
+		enrichedEvents: make(map[string]int),
+		unknownStatuses: make(map[string]bool),
+		pendingResolutions: make(map[string]bool),
+		seriesActive:       make(map[string]map[string]bool),
+		marketRetention: time.Duration(max(ingestionCfg.MarketRetentionHours, 0)) * time.Hour,
 	}, nil
 }
 
@@ -84,6 +161,9 @@ func (c *RESTClient) PollMarkets(ctx context.Context) error {
 		default:
 		}
 
+		eventMarkets := make(map[string]int)
+		activeMarkets := make(map[string]bool)
+
 		// Fetch markets for each politics series
 		for _, seriesTicker := range politicsSeries {
 			select {
@@ -93,15 +173,48 @@ func (c *RESTClient) PollMarkets(ctx context.Context) error {
 			}
 
 			// Wait for rate limit
-			if err := c.rateLimiter.Wait(ctx); err != nil {
+			if err := c.waitRateLimit(ctx); err != nil {
 				return err
 			}
 
+			seriesActive := make(map[string]bool)
 			var cursor *string
 			for {
 				resp, err := c.fetchMarkets(ctx, &seriesTicker, cursor)
+				if errors.Is(err, ErrRateLimited) {
+					// Back off and retry the same page
+					backoff := rateLimitBackoff(err)
+					fmt.Printf("Rate limited fetching markets for series %s, retrying in %s\n", seriesTicker, backoff)
+					if err := sleepContext(ctx, backoff); err != nil {
+						return err
+					}
+					continue
+				}
+				if errors.Is(err, ErrCircuitOpen) {
+					// Wait out the outage rather than drop this series' markets
+					// from the cycle, which would unsubscribe them
+					if err := sleepContext(ctx, c.breaker.retryIn(time.Now())); err != nil {
+						return err
+					}
+					continue
+				}
+				if errors.Is(err, ErrUnauthorized) {
+					// Retrying soon won't help until the credentials are fixed
+					// (or a revoked key is restored), but giving up would stop
+					// market discovery for the life of the process
+					fmt.Printf("Unauthorized fetching markets for series %s, retrying in %s: %v\n", seriesTicker, c.unauthorizedBackoff, err)
+					if err := sleepContext(ctx, c.unauthorizedBackoff); err != nil {
+						return err
+					}
+					continue
+				}
 				if err != nil {
+					// Keep the series' markets from its last complete fetch
+					// rather than treat them as closed
 					fmt.Printf("Error fetching markets for series %s: %v\n", seriesTicker, err)
+					for ticker := range c.seriesActive[seriesTicker] {
+						seriesActive[ticker] = true
+					}
 					break
 				}
 
@@ -111,31 +224,50 @@ func (c *RESTClient) PollMarkets(ctx context.Context) error {
 						Ticker:      m.Ticker,
 						Title:       m.Title,
 						Category:    m.Category,
-						Status:      parseMarketStatus(m.Status),
+						Status:      c.marketStatus(m.Ticker, m.Status),
 						EventTicker: m.EventTicker,
+						MarketType:  state.MarketType(m.MarketType),
 						YesSubTitle: m.YesSubTitle,
 						NoSubTitle:  m.NoSubTitle,
+						TickSize:    m.TickSize,
 					}
 
-					if m.ExpirationTime != nil {
-						if t, err := time.Parse(time.RFC3339, *m.ExpirationTime); err == nil {
-							market.ExpirationTime = &t
-						}
-					}
+					market.ExpirationTime = parseOptionalTime(m.ExpirationTime)
+					market.OpenTime = parseOptionalTime(m.OpenTime)
+					market.CloseTime = parseOptionalTime(m.CloseTime)
 
 					c.state.RegisterMarket(market)
+					if market.Status == state.StatusActive {
+						seriesActive[m.Ticker] = true
+					}
+					if m.EventTicker != "" {
+						eventMarkets[m.EventTicker]++
+					}
 				}
 
 				cursor = resp.Cursor
 				if cursor == nil || *cursor == "" {
+					c.seriesActive[seriesTicker] = seriesActive
 					break
 				}
 			}
+			for ticker := range seriesActive {
+				activeMarkets[ticker] = true
+			}
 		}
 
+		// Fetch rules/descriptions for new or changed events
+		c.enrichEvents(ctx, eventMarkets)
+
+		closed := c.syncSubscriptions(activeMarkets)
+		c.trackResolutions(ctx, closed)
+		c.pruneInactiveMarkets()
+
 		// Wait before next full poll cycle
-		fmt.Printf("Completed market poll cycle, waiting 60s...\n")
-		time.Sleep(60 * time.Second)
+		fmt.Printf("Completed market poll cycle, waiting %s...\n", c.refreshInterval)
+		if err := sleepContext(ctx, c.refreshInterval); err != nil {
+			return err
+		}
 	}
 }
 
@@ -157,15 +289,14 @@ func (c *RESTClient) fetchMarkets(ctx context.Context, seriesTicker *string, cur
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch markets: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, c.statusError("markets", resp)
 	}
 
 	var marketsResp GetMarketsResponse
@@ -177,7 +308,7 @@ func (c *RESTClient) fetchMarkets(ctx context.Context, seriesTicker *string, cur
 }
 
 func (c *RESTClient) GetOrderbook(ctx context.Context, ticker string) (*state.KalshiOrderbookResponse, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
+	if err := c.waitRateLimit(ctx); err != nil {
 		return nil, err
 	}
 
@@ -200,15 +331,14 @@ func (c *RESTClient) GetOrderbook(ctx context.Context, ticker string) (*state.Ka
 		req.Header.Set("KALSHI-ACCESS-TIMESTAMP", headers.AccessTimestamp)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch orderbook: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, c.statusError("orderbook", resp)
 	}
 
 	var orderbookResp state.KalshiOrderbookResponse
@@ -219,27 +349,202 @@ func (c *RESTClient) GetOrderbook(ctx context.Context, ticker string) (*state.Ka
 	return &orderbookResp, nil
 }
 
-func parseMarketStatus(s string) state.MarketStatus {
+func parseOptionalTime(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// parseMarketStatus maps a Kalshi status string to a MarketStatus, reporting
+// false for strings it doesn't recognise
+func parseMarketStatus(s string) (state.MarketStatus, bool) {
 	switch s {
-	case "initialized":
-		return state.StatusInitialized
-	case "inactive":
-		return state.StatusInactive
-	case "active":
-		return state.StatusActive
+	case "initialized", "unopened":
+		return state.StatusInitialized, true
+	case "inactive", "paused":
+		return state.StatusInactive, true
+	case "active", "open":
+		return state.StatusActive, true
 	case "closed":
-		return state.StatusClosed
-	case "determined":
-		return state.StatusDetermined
+		return state.StatusClosed, true
+	case "determined", "settlement":
+		return state.StatusDetermined, true
 	case "disputed":
-		return state.StatusDisputed
+		return state.StatusDisputed, true
 	case "amended":
-		return state.StatusAmended
-	case "finalized":
-		return state.StatusFinalized
+		return state.StatusAmended, true
+	case "finalized", "settled":
+		return state.StatusFinalized, true
 	default:
-		return state.StatusInactive
+		return state.StatusInactive, false
+	}
+}
+
+// marketStatus resolves a polled status string. An unrecognised value keeps the
+// market's previous status (inactive if it's new) instead of silently
+// deactivating it, and is logged once per distinct string.
+func (c *RESTClient) marketStatus(ticker, raw string) state.MarketStatus {
+	if status, ok := parseMarketStatus(raw); ok {
+		return status
+	}
+
+	if !c.unknownStatuses[raw] {
+		c.unknownStatuses[raw] = true
+		fmt.Printf("Unknown market status %q (first seen on %s); keeping previous status\n", raw, ticker)
+	}
+
+	if existing, ok := c.state.GetMarket(ticker); ok {
+		return existing.Status
+	}
+	return state.StatusInactive
+}
+
+// doRequest sends req through the circuit breaker. Transport errors and 5xx
+// responses count as failures; other statuses show Kalshi is up.
+func (c *RESTClient) doRequest(req *http.Request) (*http.Response, error) {
+	if !c.breaker.allow(time.Now()) {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := c.client.Do(req)
+	failed := (err != nil && !errors.Is(err, context.Canceled)) || (err == nil && resp.StatusCode >= 500)
+	c.breaker.record(failed, time.Now())
+	return resp, err
+}
+
+// BreakerStatus reports the state of the REST circuit breaker
+func (c *RESTClient) BreakerStatus() BreakerStatus {
+	return c.breaker.status()
+}
+
+// statusError builds the error for a non-200 response to a request for what,
+// classified as one of the sentinel errors where it matches.
+func (c *RESTClient) statusError(what string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return newStatusError(what, resp.StatusCode, resp.Header, string(body), c.auth != nil)
+}
+
+// syncSubscriptions diffs this cycle's active markets against the previous
+// cycle's and sends only the incremental subscribe/unsubscribe. It returns the
+// markets that are no longer active.
+func (c *RESTClient) syncSubscriptions(active map[string]bool) []string {
+	var added, removed []string
+	for ticker := range active {
+		if !c.liveMarkets[ticker] {
+			added = append(added, ticker)
+		}
+	}
+	for ticker := range c.liveMarkets {
+		if !active[ticker] {
+			removed = append(removed, ticker)
+		}
+	}
+	c.liveMarkets = active
+
+	if c.subscriber == nil {
+		return removed
+	}
+	if len(added) > 0 {
+		fmt.Printf("Subscribing to %d newly active markets\n", len(added))
+		c.subscriber.Subscribe(added)
+	}
+	if len(removed) > 0 {
+		fmt.Printf("Unsubscribing from %d inactive markets\n", len(removed))
+		c.subscriber.Unsubscribe(removed)
+	}
+	return removed
+}
+
+// trackResolutions looks up markets that left the open listing until Kalshi
+// reports a result, then records it for calibration. Markets that settle
+// without a yes/no result (e.g. voided) are dropped.
+func (c *RESTClient) trackResolutions(ctx context.Context, closed []string) {
+	for _, ticker := range closed {
+		c.pendingResolutions[ticker] = true
+	}
+
+	for ticker := range c.pendingResolutions {
+		m, err := c.fetchMarket(ctx, ticker)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, ErrRateLimited), errors.Is(err, ErrUnauthorized), errors.Is(err, ErrCircuitOpen):
+				// Try the rest next cycle rather than failing each one
+				fmt.Printf("Deferring %d resolution lookups: %v\n", len(c.pendingResolutions), err)
+				return
+			case errors.Is(err, ErrNotFound):
+				// Delisted; it will never report a result
+				delete(c.pendingResolutions, ticker)
+				continue
+			}
+			fmt.Printf("Error fetching market %s for resolution: %v\n", ticker, err)
+			continue
+		}
+
+		status := c.marketStatus(ticker, m.Status)
+		if existing, ok := c.state.GetMarket(ticker); ok && existing.Status != status {
+			existing.Status = status
+			c.state.RegisterMarket(existing)
+		}
+
+		switch m.Result {
+		case "yes", "no":
+			if c.state.RecordResolution(ticker, m.Result == "yes", time.Now().UTC()) {
+				fmt.Printf("Market %s resolved %s\n", ticker, m.Result)
+			}
+			delete(c.pendingResolutions, ticker)
+		default:
+			// Reopened markets come back through the listing; finalized ones
+			// without a result will never get one
+			if status == state.StatusActive || status == state.StatusFinalized {
+				delete(c.pendingResolutions, ticker)
+			}
+		}
 	}
 }
 
+// pruneInactiveMarkets drops markets that have been inactive for longer than
+// the retention period; pinned markets are kept
+func (c *RESTClient) pruneInactiveMarkets() {
+	if c.marketRetention <= 0 {
+		return
+	}
+	if pruned := c.state.PruneInactiveMarkets(time.Now().Add(-c.marketRetention)); pruned > 0 {
+		fmt.Printf("Pruned %d markets inactive for over %s\n", pruned, c.marketRetention)
+	}
+}
+
+func (c *RESTClient) fetchMarket(ctx context.Context, ticker string) (*KalshiMarket, error) {
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := c.baseURL + "/markets/" + ticker
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.statusError("market", resp)
+	}
+
+	var marketResp GetMarketResponse
+	if err := json.NewDecoder(resp.Body).Decode(&marketResp); err != nil {
+		return nil, err
+	}
+	return &marketResp.Market, nil
+}
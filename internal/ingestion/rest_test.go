@@ -0,0 +1,360 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// newTestRESTClient points a client without auth at a stub Kalshi API
+func newTestRESTClient(t *testing.T, handler http.Handler) (*RESTClient, *state.Engine) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	engine := state.NewEngine()
+	client, err := NewRESTClient(
+		config.KalshiConfig{APIBaseURL: server.URL},
+		config.IngestionConfig{RateLimitPerSecond: 1000, RateLimitBurst: 100},
+		engine,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, engine
+}
+
+func TestEnrichEventsFromStubEndpoint(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events/EV-1", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{
+			"event": {"event_ticker": "EV-1", "title": "Who wins?", "sub_title": "2026 race"},
+			"markets": [
+				{"ticker": "EV-1-A", "subtitle": "Candidate A", "rules_primary": "Resolves YES if A wins."},
+				{"ticker": "EV-1-B", "subtitle": "Candidate B", "rules_primary": "Resolves YES if B wins."}
+			]
+		}`))
+	})
+	client, engine := newTestRESTClient(t, mux)
+
+	client.enrichEvents(context.Background(), map[string]int{"EV-1": 2})
+
+	metadata, ok := engine.GetMarketMetadata("EV-1-B")
+	if !ok {
+		t.Fatal("no metadata stored for EV-1-B")
+	}
+	if metadata.EventTitle != "Who wins?" || metadata.EventSubtitle != "2026 race" ||
+		metadata.Subtitle != "Candidate B" || metadata.RulesPrimary != "Resolves YES if B wins." {
+		t.Fatalf("metadata = %+v", metadata)
+	}
+
+	// Same market count: not refetched. A new market: refetched.
+	client.enrichEvents(context.Background(), map[string]int{"EV-1": 2})
+	if requests != 1 {
+		t.Fatalf("unchanged event fetched %d times, want 1", requests)
+	}
+	client.enrichEvents(context.Background(), map[string]int{"EV-1": 3})
+	if requests != 2 {
+		t.Fatalf("changed event fetched %d times, want 2", requests)
+	}
+}
+
+// marketsStub serves the series list and per-series market pages PollMarkets
+// walks. A series with a status in fail gets that status instead of markets.
+type marketsStub struct {
+	mu       sync.Mutex
+	series   []string
+	markets  map[string][]KalshiMarket
+	fail     map[string]int
+	requests int // /markets requests served
+}
+
+func (s *marketsStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/series":
+		resp := GetSeriesResponse{}
+		for _, ticker := range s.series {
+			resp.Series = append(resp.Series, Series{Ticker: ticker, Category: "Politics"})
+		}
+		json.NewEncoder(w).Encode(resp)
+	case "/markets":
+		s.requests++
+		series := r.URL.Query().Get("series_ticker")
+		if status := s.fail[series]; status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(GetMarketsResponse{Markets: s.markets[series]})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *marketsStub) marketRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func TestPollMarketsUsesRefreshIntervalAndStopsOnCancel(t *testing.T) {
+	configured, err := NewRESTClient(config.KalshiConfig{}, config.IngestionConfig{MarketRefreshIntervalSecs: 90, RateLimitPerSecond: 1}, state.NewEngine())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configured.refreshInterval != 90*time.Second {
+		t.Errorf("refresh interval = %v, want the configured 90s", configured.refreshInterval)
+	}
+
+	// poll runs PollMarkets at the given refresh interval for d, then cancels
+	// it and reports how many cycles ran and how long it took to return
+	poll := func(interval, d time.Duration) (cycles int, stopped time.Duration) {
+		stub := &marketsStub{
+			series:  []string{"SER"},
+			markets: map[string][]KalshiMarket{"SER": {{Ticker: "SER-A", Status: "active"}}},
+		}
+		client, engine := newTestRESTClient(t, stub)
+		client.refreshInterval = interval
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- client.PollMarkets(ctx) }()
+
+		time.Sleep(d)
+		cancel()
+		start := time.Now()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("PollMarkets returned %v, want context.Canceled", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("PollMarkets kept sleeping after cancellation")
+		}
+		if _, ok := engine.GetMarket("SER-A"); !ok {
+			t.Error("polled market not registered")
+		}
+		return stub.marketRequests(), time.Since(start)
+	}
+
+	if cycles, _ := poll(50*time.Millisecond, 275*time.Millisecond); cycles < 3 || cycles > 7 {
+		t.Errorf("%d refresh cycles in 275ms at a 50ms interval, want about 6", cycles)
+	}
+
+	// Cancelled an hour before the next cycle: returns at once
+	cycles, stopped := poll(time.Hour, 100*time.Millisecond)
+	if cycles != 1 {
+		t.Errorf("%d refresh cycles at a 1h interval, want 1", cycles)
+	}
+	if stopped > 100*time.Millisecond {
+		t.Errorf("took %v to stop after cancellation", stopped)
+	}
+}
+
+func TestUnknownMarketStatusKeepsPreviousStatus(t *testing.T) {
+	client, engine := newTestRESTClient(t, http.NotFoundHandler())
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+
+	if got := client.marketStatus("MKT", "halted_for_review"); got != state.StatusActive {
+		t.Errorf("known market with unknown status = %s, want its previous active", got)
+	}
+	if got := client.marketStatus("NEW", "halted_for_review"); got != state.StatusInactive {
+		t.Errorf("new market with unknown status = %s, want inactive", got)
+	}
+	if !client.unknownStatuses["halted_for_review"] {
+		t.Error("unknown status not recorded")
+	}
+
+	for raw, want := range map[string]state.MarketStatus{
+		"open":       state.StatusActive,
+		"settlement": state.StatusDetermined,
+		"settled":    state.StatusFinalized,
+		"closed":     state.StatusClosed,
+	} {
+		if got := client.marketStatus("MKT", raw); got != want {
+			t.Errorf("status %q = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+// recordingSubscriber reports each Subscribe/Unsubscribe call as "sub [...]"
+// or "unsub [...]" with the tickers sorted
+type recordingSubscriber chan string
+
+func (r recordingSubscriber) Subscribe(tickers []string)   { r.record("sub", tickers) }
+func (r recordingSubscriber) Unsubscribe(tickers []string) { r.record("unsub", tickers) }
+
+func (r recordingSubscriber) record(kind string, tickers []string) {
+	sorted := append([]string(nil), tickers...)
+	sort.Strings(sorted)
+	r <- fmt.Sprint(kind, " ", sorted)
+}
+
+func TestNewMarketTriggersSubscribe(t *testing.T) {
+	stub := &marketsStub{
+		series: []string{"SER", "OTH"},
+		markets: map[string][]KalshiMarket{
+			"SER": {{Ticker: "SER-A", Status: "active"}},
+			"OTH": {{Ticker: "OTH-X", Status: "active"}},
+		},
+		fail: map[string]int{},
+	}
+	client, _ := newTestRESTClient(t, stub)
+	client.refreshInterval = 10 * time.Millisecond
+	calls := make(recordingSubscriber, 10)
+	client.SetSubscriber(calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.PollMarkets(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case call := <-calls:
+			return call
+		case <-time.After(2 * time.Second):
+			t.Fatal("no subscription change")
+			return ""
+		}
+	}
+
+	if call := next(); call != "sub [OTH-X SER-A]" {
+		t.Fatalf("first cycle: %s, want sub [OTH-X SER-A]", call)
+	}
+
+	// A market opens in SER while OTH's listing fails: only the new market is
+	// subscribed, and OTH's markets stay on the feed
+	stub.mu.Lock()
+	stub.markets["SER"] = append(stub.markets["SER"], KalshiMarket{Ticker: "SER-B", Status: "active"})
+	stub.fail["OTH"] = http.StatusInternalServerError
+	stub.mu.Unlock()
+	if call := next(); call != "sub [SER-B]" {
+		t.Fatalf("after SER-B opened: %s, want sub [SER-B]", call)
+	}
+	for start := stub.marketRequests(); stub.marketRequests() < start+6; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case call := <-calls:
+		t.Fatalf("while OTH fails: %s, want no change", call)
+	default:
+	}
+
+	// OTH recovers with its market closed: now it's unsubscribed
+	stub.mu.Lock()
+	delete(stub.fail, "OTH")
+	stub.markets["OTH"] = []KalshiMarket{{Ticker: "OTH-X", Status: "closed"}}
+	stub.mu.Unlock()
+	if call := next(); call != "unsub [OTH-X]" {
+		t.Fatalf("after OTH-X closed: %s, want unsub [OTH-X]", call)
+	}
+}
+
+func TestPollMarketsRecordsMarketType(t *testing.T) {
+	// Kalshi's payload for an event mixing binary and scalar markets; the
+	// unlabelled one predates the field and counts as binary
+	payload := `{"markets": [
+		{"ticker": "EV-BIN", "event_ticker": "EV", "status": "active", "market_type": "binary"},
+		{"ticker": "EV-SCALAR", "event_ticker": "EV", "status": "active", "market_type": "scalar"},
+		{"ticker": "EV-OLD", "event_ticker": "EV", "status": "active"}
+	], "cursor": ""}`
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/series":
+			json.NewEncoder(w).Encode(GetSeriesResponse{Series: []Series{{Ticker: "SER", Category: "Economics"}}})
+		case "/markets":
+			w.Write([]byte(payload))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	client, engine := newTestRESTClient(t, handler)
+	client.refreshInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.PollMarkets(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(engine.GetAllMarkets()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for ticker, want := range map[string]struct {
+		marketType state.MarketType
+		binary     bool
+	}{
+		"EV-BIN":    {state.MarketTypeBinary, true},
+		"EV-SCALAR": {state.MarketTypeScalar, false},
+		"EV-OLD":    {"", true},
+	} {
+		market, ok := engine.GetMarket(ticker)
+		if !ok {
+			t.Fatalf("%s not registered", ticker)
+		}
+		if market.MarketType != want.marketType || market.IsBinary() != want.binary {
+			t.Errorf("%s: type %q binary %v, want %q binary %v", ticker, market.MarketType, market.IsBinary(), want.marketType, want.binary)
+		}
+	}
+}
+
+func TestRESTRequestTimesOutOnHungServer(t *testing.T) {
+	for secs, want := range map[int]time.Duration{0: defaultRequestTimeout, 12: 12 * time.Second} {
+		client, err := NewRESTClient(config.KalshiConfig{}, config.IngestionConfig{RequestTimeoutSecs: secs, RateLimitPerSecond: 1}, state.NewEngine())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.client.Timeout != want {
+			t.Errorf("request_timeout_secs %d: timeout = %v, want %v", secs, client.client.Timeout, want)
+		}
+	}
+
+	release := make(chan struct{})
+	client, _ := newTestRESTClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer close(release)
+	client.client.Timeout = 100 * time.Millisecond
+
+	req, _ := http.NewRequest("GET", client.baseURL+"/markets", nil)
+	done := make(chan error, 1)
+	go func() {
+		resp, err := client.doRequest(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "Client.Timeout") {
+			t.Errorf("request returned %v, want a client timeout", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request still blocked on a server that never responds")
+	}
+}
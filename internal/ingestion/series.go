@@ -3,8 +3,6 @@ package ingestion
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 )
 
@@ -41,15 +39,14 @@ func (c *RESTClient) fetchPoliticsSeries(ctx context.Context) ([]string, error)
 		}
 		req.URL.RawQuery = q.Encode()
 
-		resp, err := c.client.Do(req)
+		resp, err := c.doRequest(req)
 		if err != nil {
 			return nil, err
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("failed to fetch series: status %d, body: %s", resp.StatusCode, string(body))
+			return nil, c.statusError("series", resp)
 		}
 
 		var seriesResp GetSeriesResponse
@@ -0,0 +1,21 @@
+package ingestion
+
+import (
+	"context"
+	"time"
+)
+
+// sleepContext waits for d or until ctx is cancelled, whichever comes first,
+// returning ctx.Err() in the latter case. Ingestion loops use it in place of
+// time.Sleep so shutdown isn't held up by a pending wait.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
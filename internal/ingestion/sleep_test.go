@@ -0,0 +1,31 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSleepContextReturnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := sleepContext(ctx, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if late := time.Since(start) - 10*time.Millisecond; late > 20*time.Millisecond {
+		t.Errorf("returned %v after cancellation", late)
+	}
+
+	// An uncancelled sleep runs its full duration
+	start = time.Now()
+	if err := sleepContext(context.Background(), 20*time.Millisecond); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("slept %v, want at least 20ms", elapsed)
+	}
+}
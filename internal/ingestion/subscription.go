@@ -0,0 +1,173 @@
+package ingestion
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscriptionChannels are the WebSocket channels each tracked market is subscribed to
+var subscriptionChannels = []string{"ticker", "trade"}
+
+type wsCommand struct {
+	ID     int                    `json:"id"`
+	Cmd    string                 `json:"cmd"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// Subscribe adds markets to the live feed. Tickers already subscribed are
+// ignored. If connected, the rest are added to each channel's existing
+// subscription, or sent as a new subscription for channels that don't have one
+// yet; all are included in the full subscription on every (re)connect.
+func (w *WebSocketHandler) Subscribe(tickers []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	added := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		if ticker == "" || w.subscribed[ticker] {
+			continue
+		}
+		w.subscribed[ticker] = true
+		added = append(added, ticker)
+	}
+	if len(added) == 0 || w.conn == nil {
+		return
+	}
+
+	var unconfirmed []string
+	for _, channel := range subscriptionChannels {
+		sids := w.sids[channel]
+		if len(sids) == 0 {
+			unconfirmed = append(unconfirmed, channel)
+			continue
+		}
+		params := map[string]interface{}{
+			"sids":           []int{sids[0]},
+			"market_tickers": added,
+			"action":         "add_markets",
+		}
+		if err := w.sendCommandLocked("update_subscription", params); err != nil {
+			fmt.Printf("Failed to subscribe %d markets on %s: %v\n", len(added), channel, err)
+		}
+	}
+	if len(unconfirmed) == 0 {
+		return
+	}
+
+	params := map[string]interface{}{
+		"channels":       unconfirmed,
+		"market_tickers": added,
+	}
+	if err := w.sendCommandLocked("subscribe", params); err != nil {
+		fmt.Printf("Failed to subscribe %d markets: %v\n", len(added), err)
+	}
+}
+
+// Unsubscribe removes markets from the live feed, e.g. once they close
+func (w *WebSocketHandler) Unsubscribe(tickers []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	removed := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		if !w.subscribed[ticker] {
+			continue
+		}
+		delete(w.subscribed, ticker)
+		removed = append(removed, ticker)
+	}
+	if len(removed) == 0 || w.conn == nil {
+		return
+	}
+
+	// Markets are dropped from each existing subscription rather than
+	// cancelling it, since the other markets share the same sids. A channel
+	// can hold several subscriptions when markets were added before the first
+	// was confirmed, and a market may be on any of them.
+	for channel, sids := range w.sids {
+		for _, sid := range sids {
+			params := map[string]interface{}{
+				"sids":           []int{sid},
+				"market_tickers": removed,
+				"action":         "delete_markets",
+			}
+			if err := w.sendCommandLocked("update_subscription", params); err != nil {
+				fmt.Printf("Failed to unsubscribe %d markets from %s: %v\n", len(removed), channel, err)
+			}
+		}
+	}
+}
+
+// Subscribed returns the tickers currently tracked on the live feed
+func (w *WebSocketHandler) Subscribed() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	tickers := make([]string, 0, len(w.subscribed))
+	for ticker := range w.subscribed {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// attach makes conn the active connection and subscribes it to every tracked market
+func (w *WebSocketHandler) attach(conn *websocket.Conn) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.conn = conn
+	w.sids = make(map[string][]int)
+	if len(w.subscribed) == 0 {
+		return nil
+	}
+
+	tickers := make([]string, 0, len(w.subscribed))
+	for ticker := range w.subscribed {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	params := map[string]interface{}{
+		"channels":       subscriptionChannels,
+		"market_tickers": tickers,
+	}
+	return w.sendCommandLocked("subscribe", params)
+}
+
+// detach clears the active connection; subscriptions are replayed on the next attach
+func (w *WebSocketHandler) detach() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.conn = nil
+	w.sids = nil
+}
+
+// recordSubscription stores each sid Kalshi assigns to a channel, needed to
+// later add markets to or remove them from it
+func (w *WebSocketHandler) recordSubscription(msg map[string]interface{}) {
+	body, ok := msg["msg"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	channel, _ := body["channel"].(string)
+	sid, ok := body["sid"].(float64)
+	if channel == "" || !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.sids != nil {
+		w.sids[channel] = append(w.sids[channel], int(sid))
+	}
+}
+
+// sendCommandLocked writes a command on the active connection. Caller holds w.mu,
+// which also serialises writes with the keepalive ping.
+func (w *WebSocketHandler) sendCommandLocked(cmd string, params map[string]interface{}) error {
+	w.nextCommandID++
+	return w.conn.WriteJSON(wsCommand{ID: w.nextCommandID, Cmd: cmd, Params: params})
+}
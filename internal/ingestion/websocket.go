@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -15,6 +18,19 @@ type WebSocketHandler struct {
 	url            string
 	reconnectDelay time.Duration
 	state          *state.Engine
+
+	maxParseFailureRatio float64
+
+	// Active connection and the markets it should be subscribed to; guarded by
+	// mu, which also serialises writes to conn
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	subscribed    map[string]bool
+	sids          map[string][]int // channel -> subscription ids on conn
+	nextCommandID int
+
+	// Asked for a full-book refetch when a trade prints outside the book
+	resnapshotter bookResnapshotter
 }
 
 func NewWebSocketHandler(cfg config.KalshiConfig, ingestionCfg config.IngestionConfig, stateEngine *state.Engine) *WebSocketHandler {
@@ -22,12 +38,22 @@ func NewWebSocketHandler(cfg config.KalshiConfig, ingestionCfg config.IngestionC
 		url:            cfg.WebSocketURL,
 		reconnectDelay: time.Duration(ingestionCfg.WebSocketReconnectDelaySecs) * time.Second,
 		state:          stateEngine,
+
+		maxParseFailureRatio: ingestionCfg.MaxLevelParseFailureRatio,
+		subscribed:           make(map[string]bool),
 	}
 }
 
+const (
+	// maxReconnectDelay caps the exponential reconnect backoff
+	maxReconnectDelay = 60 * time.Second
+	// stableConnectionDuration is how long a connection must stay up before
+	// the backoff resets to the base delay
+	stableConnectionDuration = 30 * time.Second
+)
+
 func (w *WebSocketHandler) Run(ctx context.Context) error {
 	delay := w.reconnectDelay
-	maxDelay := 60 * time.Second
 
 	for {
 		select {
@@ -36,7 +62,8 @@ func (w *WebSocketHandler) Run(ctx context.Context) error {
 		default:
 		}
 
-		err := w.connectAndListen(ctx)
+		uptime, err := w.connectAndListen(ctx)
+		delay = w.nextReconnectDelay(delay, uptime)
 		if err != nil {
 			fmt.Printf("WebSocket error: %v. Reconnecting in %v...\n", err, delay)
 		} else {
@@ -44,21 +71,30 @@ func (w *WebSocketHandler) Run(ctx context.Context) error {
 		}
 
 		// Wait before reconnecting
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
 		}
 
-		// Exponential backoff, max 60 seconds
-		delay = delay * 2
-		if delay > maxDelay {
-			delay = maxDelay
-		}
+		delay = growReconnectDelay(delay)
 	}
 }
 
-func (w *WebSocketHandler) connectAndListen(ctx context.Context) error {
+// growReconnectDelay doubles the backoff for the next attempt, up to the cap
+func growReconnectDelay(delay time.Duration) time.Duration {
+	return min(delay*2, maxReconnectDelay)
+}
+
+// nextReconnectDelay resets the backoff to the base delay once a connection has
+// stayed up long enough, so brief reconnects don't permanently inflate it
+func (w *WebSocketHandler) nextReconnectDelay(delay, uptime time.Duration) time.Duration {
+	if uptime >= stableConnectionDuration {
+		return w.reconnectDelay
+	}
+	return delay
+}
+
+// connectAndListen runs one connection until it drops, returning how long it was up
+func (w *WebSocketHandler) connectAndListen(ctx context.Context) (time.Duration, error) {
 	fmt.Printf("Connecting to WebSocket: %s\n", w.url)
 
 	dialer := websocket.Dialer{
@@ -67,14 +103,17 @@ func (w *WebSocketHandler) connectAndListen(ctx context.Context) error {
 
 	conn, _, err := dialer.Dial(w.url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+		return 0, fmt.Errorf("failed to connect: %w", err)
 	}
 	defer conn.Close()
 
 	fmt.Println("WebSocket connected")
+	connectedAt := time.Now()
 
-	// Reset delay on successful connection
-	w.reconnectDelay = time.Duration(5) * time.Second
+	if err := w.attach(conn); err != nil {
+		return 0, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	defer w.detach()
 
 	// Handle messages
 	done := make(chan error, 1)
@@ -100,12 +139,15 @@ func (w *WebSocketHandler) connectAndListen(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return time.Since(connectedAt), ctx.Err()
 		case err := <-done:
-			return err
+			return time.Since(connectedAt), err
 		case <-ticker.C:
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return err
+			w.mu.Lock()
+			err := conn.WriteMessage(websocket.PingMessage, nil)
+			w.mu.Unlock()
+			if err != nil {
+				return time.Since(connectedAt), err
 			}
 		}
 	}
@@ -127,6 +169,11 @@ func (w *WebSocketHandler) handleMessage(message []byte) error {
 		return w.handleOrderbookUpdate(msg)
 	case "trade", "trade_update":
 		return w.handleTradeUpdate(msg)
+	case "ticker", "ticker_v2":
+		return w.handleTickerUpdate(msg)
+	case "subscribed":
+		w.recordSubscription(msg)
+		return nil
 	default:
 		// Unknown message type, ignore
 		return nil
@@ -157,7 +204,9 @@ func (w *WebSocketHandler) handleOrderbookUpdate(msg map[string]interface{}) err
 		},
 	}
 
-	orderbook.UpdateFromKalshi(orderbookResp)
+	if err := orderbook.UpdateFromKalshi(orderbookResp, w.maxParseFailureRatio); err != nil {
+		return err
+	}
 	w.state.UpdateOrderbook(ticker, orderbook)
 
 	return nil
@@ -171,41 +220,115 @@ func (w *WebSocketHandler) handleTradeUpdate(msg map[string]interface{}) error {
 
 	price, _ := msg["price"].(float64)
 	quantity, _ := msg["count"].(float64)
-	side, _ := msg["side"].(string)
+	side, ok := msg["taker_side"].(string)
+	if !ok {
+		side, _ = msg["side"].(string)
+	}
 
 	trade := &state.Trade{
 		MarketTicker: ticker,
-		Price:        int(price * 100), // Convert to cents
+		Price:        int(math.Round(price * 100)), // Convert to cents
 		Quantity:     int(quantity),
-		Timestamp:    time.Now(),
+		Timestamp:    time.Now().UTC(),
 	}
 
-	if side == "yes" {
+	// Trades the feed doesn't label are sided by their price against the
+	// current book; ones it can't call stay empty so they don't skew flow
+	switch side {
+	case "yes":
 		trade.Side = state.SideYes
-	} else {
+	case "no":
 		trade.Side = state.SideNo
+	default:
+		if ob, ok := w.state.GetOrderbook(ticker); ok {
+			trade.Side = ob.InferTakerSide(trade.Price)
+		}
 	}
 
 	w.state.AddTrade(trade)
+	w.checkTradeAgainstBook(trade)
+	return nil
+}
+
+// handleTickerUpdate updates the market's quote cache from a ticker channel
+// message. ticker messages carry the full quote; ticker_v2 only what changed.
+func (w *WebSocketHandler) handleTickerUpdate(msg map[string]interface{}) error {
+	payload := msg
+	if inner, ok := msg["msg"].(map[string]interface{}); ok {
+		payload = inner
+	}
+
+	ticker, _ := payload["market_ticker"].(string)
+	if ticker == "" {
+		ticker, _ = payload["ticker"].(string)
+	}
+	if ticker == "" {
+		return nil
+	}
+
+	update := state.QuoteUpdate{
+		LastPrice:    centsField(payload, "price"),
+		YesBid:       centsField(payload, "yes_bid"),
+		YesAsk:       centsField(payload, "yes_ask"),
+		Volume:       countField(payload, "volume"),
+		VolumeDelta:  countField(payload, "volume_delta"),
+		OpenInterest: countField(payload, "open_interest"),
+		Timestamp:    time.Now().UTC(),
+	}
+	if ts, ok := payload["ts"].(float64); ok && ts > 0 {
+		update.Timestamp = time.Unix(int64(ts), 0).UTC()
+	}
+
+	w.state.UpdateQuote(ticker, update)
 	return nil
 }
 
+// centsField reads a price in cents from key, or from its key+"_dollars"
+// string variant, returning nil when neither is present
+func centsField(payload map[string]interface{}, key string) *int {
+	if v, ok := payload[key].(float64); ok {
+		cents := int(math.Round(v))
+		return &cents
+	}
+	if v, ok := payload[key+"_dollars"].(string); ok {
+		if dollars, err := strconv.ParseFloat(v, 64); err == nil {
+			cents := int(math.Round(dollars * 100))
+			return &cents
+		}
+	}
+	return nil
+}
+
+// countField reads an integer count from key (number or numeric string)
+func countField(payload map[string]interface{}, key string) *int64 {
+	switch v := payload[key].(type) {
+	case float64:
+		n := int64(v)
+		return &n
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			n := int64(f)
+			return &n
+		}
+	}
+	return nil
+}
+
+// convertToOrderbookLevels keeps malformed levels (as short or empty entries) so
+// UpdateFromKalshi can count them as parse failures instead of them vanishing
 func convertToOrderbookLevels(data []interface{}) [][]string {
 	result := make([][]string, 0, len(data))
 	for _, item := range data {
-		if arr, ok := item.([]interface{}); ok {
-			level := make([]string, 0, len(arr))
-			for _, v := range arr {
-				if s, ok := v.(string); ok {
-					level = append(level, s)
-				} else if f, ok := v.(float64); ok {
-					level = append(level, fmt.Sprintf("%.4f", f))
-				}
-			}
-			if len(level) >= 2 {
-				result = append(result, level)
+		arr, _ := item.([]interface{})
+		level := make([]string, 0, len(arr))
+		for _, v := range arr {
+			if s, ok := v.(string); ok {
+				level = append(level, s)
+			} else if f, ok := v.(float64); ok {
+				level = append(level, fmt.Sprintf("%.4f", f))
 			}
 		}
+		result = append(result, level)
 	}
 	return result
 }
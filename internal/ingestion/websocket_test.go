@@ -0,0 +1,219 @@
+package ingestion
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestReconnectBackoffResetsAfterStableConnection(t *testing.T) {
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{WebSocketReconnectDelaySecs: 5}, state.NewEngine())
+
+	// Each entry is how long a connection stayed up before dropping
+	uptimes := []time.Duration{
+		0, 0, 0, 0, 0, // repeated failures back off to the cap
+		2 * time.Second,              // a brief reconnect keeps the backoff
+		stableConnectionDuration + 1, // stayed up: back to the base delay
+		0,
+	}
+	want := []time.Duration{5, 10, 20, 40, 60, 60, 5, 10}
+
+	delay := w.reconnectDelay
+	for i, uptime := range uptimes {
+		wait := w.nextReconnectDelay(delay, uptime)
+		if wait != want[i]*time.Second {
+			t.Errorf("attempt %d (up %v): wait %v, want %v", i, uptime, wait, want[i]*time.Second)
+		}
+		delay = growReconnectDelay(wait)
+	}
+}
+
+func TestTickerMessageUpdatesQuoteCache(t *testing.T) {
+	engine := state.NewEngine()
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{}, engine)
+
+	// The feed's ts is stored in UTC whatever the host's zone
+	saved := time.Local
+	time.Local = time.FixedZone("EST", -5*60*60)
+	defer func() { time.Local = saved }()
+	ts := time.Now().Unix()
+
+	full := fmt.Sprintf(`{"type":"ticker","sid":1,"msg":{"market_ticker":"MKT","price":46,"yes_bid":45,"yes_ask":47,"volume":1000,"open_interest":300,"ts":%d}}`, ts)
+	if err := w.handleMessage([]byte(full)); err != nil {
+		t.Fatal(err)
+	}
+	quote, ok := engine.GetQuote("MKT")
+	if !ok {
+		t.Fatal("no quote cached after a ticker message")
+	}
+	if quote.LastPrice != 46 || quote.YesBid != 45 || quote.YesAsk != 47 || quote.Volume != 1000 || quote.OpenInterest != 300 {
+		t.Errorf("quote = %+v, want 46 last, 45/47, volume 1000, open interest 300", quote)
+	}
+	if !quote.UpdatedAt.Equal(time.Unix(ts, 0)) || quote.UpdatedAt.Location() != time.UTC {
+		t.Errorf("updated at %s, want %s in UTC", quote.UpdatedAt, time.Unix(ts, 0).UTC())
+	}
+	if _, ok := engine.GetOrderbook("MKT"); ok {
+		t.Error("ticker message created an orderbook; the quote cache is separate")
+	}
+
+	// ticker_v2 carries only what changed, here in dollars
+	delta := `{"type":"ticker_v2","sid":1,"msg":{"market_ticker":"MKT","yes_ask_dollars":"0.4900","volume_delta":25}}`
+	if err := w.handleMessage([]byte(delta)); err != nil {
+		t.Fatal(err)
+	}
+	quote, _ = engine.GetQuote("MKT")
+	if quote.YesBid != 45 || quote.YesAsk != 49 || quote.Volume != 1025 {
+		t.Errorf("quote = %+v, want bid kept at 45, ask 49, volume 1025", quote)
+	}
+
+	// The traded contracts reach trade analytics
+	if contracts, samples := engine.GetTimeSeries().GetTickerVolume("MKT", time.Now().Add(-time.Minute)); contracts != 25 || samples != 1 {
+		t.Errorf("ticker volume = %d over %d samples, want 25 over 1", contracts, samples)
+	}
+}
+
+// commandRecorder is a stub Kalshi WebSocket that forwards every command it receives
+func commandRecorder(t *testing.T) (*websocket.Conn, <-chan wsCommand) {
+	t.Helper()
+	commands := make(chan wsCommand, 10)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var cmd wsCommand
+			if err := conn.ReadJSON(&cmd); err != nil {
+				return
+			}
+			commands <- cmd
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, commands
+}
+
+func TestSubscribeAddsMarketsToExistingSubscription(t *testing.T) {
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{}, state.NewEngine())
+	conn, commands := commandRecorder(t)
+	next := func() wsCommand {
+		t.Helper()
+		select {
+		case cmd := <-commands:
+			return cmd
+		case <-time.After(2 * time.Second):
+			t.Fatal("no command sent")
+			return wsCommand{}
+		}
+	}
+	ack := func(channel string, sid int) {
+		w.handleMessage([]byte(fmt.Sprintf(`{"type":"subscribed","msg":{"channel":%q,"sid":%d}}`, channel, sid)))
+	}
+
+	w.Subscribe([]string{"MKT-A"})
+	if err := w.attach(conn); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := next(); cmd.Cmd != "subscribe" || fmt.Sprint(cmd.Params["market_tickers"]) != "[MKT-A]" {
+		t.Fatalf("on attach: %+v, want subscribe [MKT-A]", cmd)
+	}
+
+	// Before Kalshi confirms, a new market needs its own subscription
+	w.Subscribe([]string{"MKT-B"})
+	if cmd := next(); cmd.Cmd != "subscribe" || fmt.Sprint(cmd.Params["market_tickers"]) != "[MKT-B]" {
+		t.Fatalf("before confirmation: %+v, want subscribe [MKT-B]", cmd)
+	}
+	ack("ticker", 1)
+	ack("trade", 2)
+	ack("ticker", 3)
+	ack("trade", 4)
+
+	// Once confirmed, new markets join the existing subscription
+	w.Subscribe([]string{"MKT-C", "MKT-A"})
+	for _, sid := range []float64{1, 2} {
+		cmd := next()
+		if cmd.Cmd != "update_subscription" || cmd.Params["action"] != "add_markets" ||
+			fmt.Sprint(cmd.Params["sids"]) != fmt.Sprint([]float64{sid}) || fmt.Sprint(cmd.Params["market_tickers"]) != "[MKT-C]" {
+			t.Fatalf("after confirmation: %+v, want add_markets [MKT-C] on sid %v", cmd, sid)
+		}
+	}
+
+	// Unsubscribing reaches every subscription the market could be on
+	w.Unsubscribe([]string{"MKT-B"})
+	var sids []int
+	for i := 0; i < 4; i++ {
+		cmd := next()
+		if cmd.Params["action"] != "delete_markets" || fmt.Sprint(cmd.Params["market_tickers"]) != "[MKT-B]" {
+			t.Fatalf("unsubscribe: %+v, want delete_markets [MKT-B]", cmd)
+		}
+		sids = append(sids, int(cmd.Params["sids"].([]interface{})[0].(float64)))
+	}
+	sort.Ints(sids)
+	if fmt.Sprint(sids) != "[1 2 3 4]" {
+		t.Errorf("delete_markets sent to sids %v, want [1 2 3 4]", sids)
+	}
+	select {
+	case cmd := <-commands:
+		t.Errorf("unexpected extra command %+v", cmd)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUnlabeledTradeSideInferredFromBook(t *testing.T) {
+	engine := state.NewEngine()
+	w := NewWebSocketHandler(config.KalshiConfig{}, config.IngestionConfig{}, engine)
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 49, Quantity: 100}}
+	engine.UpdateOrderbook("MKT", ob)
+
+	tests := []struct {
+		price float64
+		label string
+		want  state.TradeSide
+	}{
+		{0.49, "", state.SideYes},    // lifted the offer
+		{0.45, "", state.SideNo},     // hit the bid
+		{0.48, "", state.SideYes},    // inside the spread, nearer the ask
+		{0.46, "", state.SideNo},     // nearer the bid
+		{0.47, "", ""},               // at mid: can't tell
+		{0.45, "yes", state.SideYes}, // the feed's label wins
+	}
+	for _, tt := range tests {
+		msg := map[string]interface{}{"ticker": "MKT", "price": tt.price, "count": 10.0}
+		if tt.label != "" {
+			msg["taker_side"] = tt.label
+		}
+		if err := w.handleTradeUpdate(msg); err != nil {
+			t.Fatal(err)
+		}
+		trades := engine.GetRecentTrades("MKT", time.Minute)
+		if got := trades[len(trades)-1].Side; got != tt.want {
+			t.Errorf("trade at %.2f labelled %q: side = %q, want %q", tt.price, tt.label, got, tt.want)
+		}
+	}
+
+	// Without a book there's nothing to infer from
+	if err := w.handleTradeUpdate(map[string]interface{}{"ticker": "NOBOOK", "price": 0.5, "count": 1.0}); err != nil {
+		t.Fatal(err)
+	}
+	if trades := engine.GetRecentTrades("NOBOOK", time.Minute); len(trades) != 1 || trades[0].Side != "" {
+		t.Errorf("trade with no book = %+v, want one unsided trade", trades)
+	}
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import "time"
+
+// fairValueTradeWindow is how far back trades count toward the VWAP component
+const fairValueTradeWindow = 5 * time.Minute
+
+// FairValue returns a single fair-value estimate for a market in cents (0-100).
+//
+// It blends the microprice with the VWAP of recent trades:
+//
+//	fair = (1-w)*microprice + w*vwap
+//	w    = FairValueVWAPWeight * min(1, trades/FairValueMinTrades)
+//
+// With no recent trades the estimate is pure microprice; as trading activity
+// builds, VWAP contributes up to the configured weight. Returns false when the
+// book isn't two-sided.
+func (s *Scanner) FairValue(ticker string) (float64, bool) {
+	orderbook, exists := s.state.GetOrderbook(ticker)
+	if !exists {
+		return 0, false
+	}
+
+	microprice, ok := s.Microprice(orderbook)
+	if !ok {
+		return 0, false
+	}
+	microprice *= 100.0 // dollars -> cents
+
+	trades := s.state.GetTradesSince(ticker, s.now().Add(-fairValueTradeWindow))
+	var notional, volume float64
+	for _, t := range trades {
+		notional += float64(t.Price) * float64(t.Quantity)
+		volume += float64(t.Quantity)
+	}
+	if volume == 0 {
+		return microprice, true
+	}
+	vwap := notional / volume
+
+	weight := s.config.FairValueVWAPWeight
+	if s.config.FairValueMinTrades > 0 && len(trades) < s.config.FairValueMinTrades {
+		weight *= float64(len(trades)) / float64(s.config.FairValueMinTrades)
+	}
+
+	return (1-weight)*microprice + weight*vwap, true
+}
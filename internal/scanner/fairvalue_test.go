@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestFairValueBlendsByTradeCount(t *testing.T) {
+	// Equal size on both sides: the microprice is the 50¢ mid
+	book := func(engine *state.Engine) {
+		engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+		engine.UpdateOrderbook("MKT", fixtureBook("MKT", time.Second,
+			[]state.PriceLevel{{Price: 49, Quantity: 100}},
+			[]state.PriceLevel{{Price: 51, Quantity: 100}}))
+	}
+	cfg := config.ScannerConfig{FairValueVWAPWeight: 0.4, FairValueMinTrades: 10, MicropriceLevels: 1}
+
+	tests := []struct {
+		name   string
+		trades int
+		want   float64
+	}{
+		// No trades: pure microprice
+		{"no trades", 0, 50},
+		// 2 of 10 trades at 60¢: weight 0.4 * 0.2 = 0.08
+		{"low trade count", 2, 0.92*50 + 0.08*60},
+		// At or past the minimum count VWAP gets the full 0.4
+		{"high trade count", 20, 0.6*50 + 0.4*60},
+	}
+	for _, tt := range tests {
+		engine := state.NewEngine()
+		book(engine)
+		// An old trade outside the VWAP window never counts
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 5, Quantity: 1000, Timestamp: fixtureNow.Add(-time.Hour)})
+		for i := tt.trades; i > 0; i-- {
+			engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 60, Quantity: 10, Timestamp: fixtureNow.Add(-time.Duration(i) * time.Second)})
+		}
+
+		s := NewScannerWithClock(engine, cfg, func() time.Time { return fixtureNow })
+		got, ok := s.FairValue("MKT")
+		if !ok {
+			t.Fatalf("%s: no fair value", tt.name)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: fair value = %.4f, want %.4f", tt.name, got, tt.want)
+		}
+	}
+}
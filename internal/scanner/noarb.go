@@ -2,8 +2,10 @@ package scanner
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/state"
 )
 
@@ -23,37 +25,99 @@ type NoArbViolation struct {
 	EstimatedFees    float64   `json:"estimated_fees"`      // estimated fees
 	EstimatedSlippage float64   `json:"estimated_slippage"` // estimated slippage
 	Liquidity        int64     `json:"liquidity"`           // min available size
+	DollarEdge       float64   `json:"dollar_edge"`         // net_arb * liquidity: profit at the executable size, dollars
 	Timestamp        time.Time `json:"timestamp"`
 	Actionable       bool      `json:"actionable"`          // true if net_arb > threshold
+	StaleBook        bool      `json:"stale_book"`          // a leg's book is older than the max book age; never actionable
 }
 
+// ExecutionStyle is how an estimate assumes orders are filled
+type ExecutionStyle string
+
+const (
+	// ExecutionTaker crosses the spread, walking the book and paying taker fees
+	ExecutionTaker ExecutionStyle = "taker"
+	// ExecutionMaker posts at the touch and assumes passive fills at that price
+	ExecutionMaker ExecutionStyle = "maker"
+)
+
+// ExecutionEstimate describes the cost of trading a no-arb violation at a given size
+type ExecutionEstimate struct {
+	Size          int     `json:"size"`           // contracts per leg
+	Style         ExecutionStyle `json:"style"`
+	FeeRate       float64 `json:"fee_rate"`       // per-leg fee rate assumed
+	Fillable      bool    `json:"fillable"`       // every leg has enough depth
+	EdgeCents     float64 `json:"edge_cents"`     // per contract, after fees and slippage
+	SlippageCents float64 `json:"slippage_cents"` // per contract, summed across legs
+	FeesCents     float64 `json:"fees_cents"`     // per contract, summed across legs
+}
+
+// settlementPayoutCents is what a complete set of an event's outcomes is worth
+// at resolution. Kalshi charges fees on trades only, not on settlement, so
+// no-arb edges, which assume every leg is held to resolution, pay fees on the
+// entry legs and none on the payout.
+const settlementPayoutCents = 100.0
+
 // NoArbEngine detects cross-market arbitrage opportunities
 type NoArbEngine struct {
-	state *state.Engine
+	state  *state.Engine
+	config config.ScannerConfig
+
+	// Events to check; nil means every event
+	allowedEvents map[string]bool
 }
 
-func NewNoArbEngine(stateEngine *state.Engine) *NoArbEngine {
+func NewNoArbEngine(stateEngine *state.Engine, cfg config.ScannerConfig) *NoArbEngine {
+	var allowedEvents map[string]bool
+	for _, event := range cfg.NoArbEvents {
+		event = strings.TrimSpace(event)
+		if event == "" {
+			continue
+		}
+		if allowedEvents == nil {
+			allowedEvents = make(map[string]bool)
+		}
+		allowedEvents[event] = true
+	}
+
 	return &NoArbEngine{
-		state: stateEngine,
+		state:         stateEngine,
+		config:        cfg,
+		allowedEvents: allowedEvents,
 	}
 }
 
-// GroupMarketsByEvent groups markets by event_ticker
+// GroupMarketsByEvent groups markets by event_ticker, limited to the configured
+// event allowlist when one is set. Events containing any non-binary market are
+// left out entirely: their markets aren't mutually exclusive outcomes, so the
+// sum-to-$1 check would report nonsense.
 func (n *NoArbEngine) GroupMarketsByEvent() map[string][]string {
 	markets := n.state.GetAllMarkets()
 	groups := make(map[string][]string)
+	unsupported := make(map[string]bool)
 
 	for _, market := range markets {
-		if market.Status != state.StatusActive {
-			continue
-		}
 		eventTicker := market.EventTicker
 		if eventTicker == "" {
 			continue
 		}
+		if n.allowedEvents != nil && !n.allowedEvents[eventTicker] {
+			continue
+		}
+		if !market.IsBinary() {
+			unsupported[eventTicker] = true
+			continue
+		}
+		if !market.Tradeable {
+			continue
+		}
 		groups[eventTicker] = append(groups[eventTicker], market.Ticker)
 	}
 
+	for eventTicker := range unsupported {
+		delete(groups, eventTicker)
+	}
+
 	return groups
 }
 
@@ -82,6 +146,8 @@ func (n *NoArbEngine) checkEventGroup(eventTicker string, marketTickers []string
 	var minLiquidity int64 = 1000000 // Start high, find minimum
 
 	allMarketsValid := true
+	staleBook := false
+	now := time.Now()
 
 	for _, ticker := range marketTickers {
 		orderbook, exists := n.state.GetOrderbook(ticker)
@@ -89,6 +155,9 @@ func (n *NoArbEngine) checkEventGroup(eventTicker string, marketTickers []string
 			allMarketsValid = false
 			break
 		}
+		if now.Sub(orderbook.LastUpdate) > maxBookAge(n.config) {
+			staleBook = true
+		}
 
 		// Best ask = cost to buy YES
 		bestAsk := float64(orderbook.Asks[0].Price) / 100.0 // Convert cents to probability
@@ -120,30 +189,37 @@ func (n *NoArbEngine) checkEventGroup(eventTicker string, marketTickers []string
 	// Calculate net arbitrage
 	// Buy arbitrage: if sumBuyPrice < 1.0, profit = 1.0 - sumBuyPrice
 	// Sell arbitrage: if sumSellPrice > 1.0, profit = sumSellPrice - 1.0
+	payout := settlementPayoutCents / 100.0
 	var netArb float64
-	if sumBuyPrice < 1.0 {
-		netArb = 1.0 - sumBuyPrice // Buy all outcomes, guaranteed $1 payout
-	} else if sumSellPrice > 1.0 {
-		netArb = sumSellPrice - 1.0 // Sell all outcomes, guaranteed $1 cost
+	if sumBuyPrice < payout {
+		netArb = payout - sumBuyPrice // Buy all outcomes, guaranteed $1 payout
+	} else if sumSellPrice > payout {
+		netArb = sumSellPrice - payout // Sell all outcomes, guaranteed $1 cost
 	} else {
 		return nil // No arbitrage
 	}
 
-	// Estimate fees (Kalshi typically charges ~5-10% on trades)
-	// For simplicity, assume 5% on each leg
-	estimatedFees := sumBuyPrice * 0.05 * float64(len(marketTickers))
-	if sumSellPrice > 1.0 {
-		estimatedFees = sumSellPrice * 0.05 * float64(len(marketTickers))
+	// Estimate fees using the configured fee rate on each leg's price; the sum
+	// already spans every leg. These are entry fees only: the set is held to
+	// settlement, which is fee-free.
+	estimatedFees := sumBuyPrice * n.config.FeeRate
+	if sumSellPrice > payout {
+		estimatedFees = sumSellPrice * n.config.FeeRate
 	}
 
-	// Estimate slippage (walking the book)
-	estimatedSlippage := 0.01 * float64(len(marketTickers)) // 1% per market
+	// Estimate slippage using the configured per-leg buffer
+	estimatedSlippage := n.config.SlippageBufferCents / 100.0 * float64(len(marketTickers))
 
 	// Net arbitrage after fees and slippage
 	netArbAfterCosts := netArb - estimatedFees - estimatedSlippage
 
-	// Only flag if net arbitrage exceeds threshold (e.g., 2 cents)
-	actionable := netArbAfterCosts > 0.02 && minLiquidity >= 10
+	// Profit in dollars if the thinnest leg's top level is taken in full
+	dollarEdge := netArbAfterCosts * float64(minLiquidity)
+
+	// Only flag if net arbitrage exceeds threshold (e.g., 2 cents) and is worth
+	// trading at the size available
+	actionable := netArbAfterCosts > 0.02 && minLiquidity >= 10 && !staleBook &&
+		dollarEdge >= n.config.NoArbMinDollarEdge
 
 	violation := &NoArbViolation{
 		EventTicker:       eventTicker,
@@ -154,13 +230,134 @@ func (n *NoArbEngine) checkEventGroup(eventTicker string, marketTickers []string
 		EstimatedFees:     estimatedFees,
 		EstimatedSlippage: estimatedSlippage,
 		Liquidity:         minLiquidity,
-		Timestamp:         time.Now(),
+		DollarEdge:        dollarEdge,
+		Timestamp:         time.Now().UTC(),
 		Actionable:        actionable,
+		StaleBook:         staleBook,
 	}
 
 	return violation
 }
 
+// DefaultExecutionStyle returns the configured execution style, defaulting to taker
+func (n *NoArbEngine) DefaultExecutionStyle() ExecutionStyle {
+	if ExecutionStyle(n.config.ExecutionStyle) == ExecutionMaker {
+		return ExecutionMaker
+	}
+	return ExecutionTaker
+}
+
+// EstimateExecution prices the violation at size contracts per leg.
+//
+// Taker: buy arbs lift the asks of every market, sell arbs hit the bids. Each
+// leg walks the book; slippage is the fill price's distance from top of book
+// plus the configured per-leg buffer, and the taker fee rate applies.
+//
+// Maker: buy arbs rest bids at each market's best bid, sell arbs rest asks at
+// the best ask. Fills are assumed passive at the touch, so there is no
+// slippage and the maker fee rate applies.
+func (n *NoArbEngine) EstimateExecution(v NoArbViolation, size int, style ExecutionStyle) ExecutionEstimate {
+	est := ExecutionEstimate{Size: size, Style: style, FeeRate: n.config.FeeRate}
+	if style == ExecutionMaker {
+		est.FeeRate = n.config.MakerFeeRate
+	}
+	if size <= 0 {
+		return est
+	}
+
+	buying := v.SumBuyPrice < settlementPayoutCents/100.0
+	var sumPrice float64 // cents, reference price across legs
+
+	for _, ticker := range v.Markets {
+		orderbook, exists := n.state.GetOrderbook(ticker)
+		if !exists {
+			return est
+		}
+
+		if style == ExecutionMaker {
+			// Post on our own side of the book: bids to buy, asks to sell
+			levels := orderbook.Asks
+			if buying {
+				levels = orderbook.Bids
+			}
+			if len(levels) == 0 {
+				return est
+			}
+			price := float64(levels[0].Price)
+			sumPrice += price
+			est.FeesCents += price * est.FeeRate
+			continue
+		}
+
+		levels := orderbook.Bids
+		if buying {
+			levels = orderbook.Asks
+		}
+		if len(levels) == 0 {
+			return est
+		}
+
+		avgPrice, filled := walkBook(levels, size)
+		if !filled {
+			return est
+		}
+
+		top := float64(levels[0].Price)
+		sumPrice += top
+		slippage := avgPrice - top
+		if slippage < 0 {
+			slippage = -slippage
+		}
+		est.SlippageCents += slippage + n.config.SlippageBufferCents
+		est.FeesCents += avgPrice * est.FeeRate
+	}
+
+	est.Fillable = true
+	est.EdgeCents = completeSetPnLCents(sumPrice, est.FeesCents, buying, true, 0, 0) - est.SlippageCents
+	return est
+}
+
+// completeSetPnLCents is the per-contract profit of a complete set of an
+// event's outcomes bought (or sold) for entryCents summed across legs, net of
+// the entry fees. Held to resolution it settles at settlementPayoutCents and
+// the payout carries no fee. Closed out before resolution at exitCents, the
+// closing trades pay exitFeeRate on the exit price as well.
+func completeSetPnLCents(entryCents, entryFeesCents float64, buying, held bool, exitCents, exitFeeRate float64) float64 {
+	exit := settlementPayoutCents
+	exitFees := 0.0
+	if !held {
+		exit = exitCents
+		exitFees = exitCents * exitFeeRate
+	}
+
+	gross := exit - entryCents
+	if !buying {
+		gross = entryCents - exit
+	}
+	return gross - entryFeesCents - exitFees
+}
+
+// walkBook returns the average fill price for size contracts against levels
+func walkBook(levels []state.PriceLevel, size int) (float64, bool) {
+	remaining := size
+	var total int64
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		fill := remaining
+		if fill > level.Quantity {
+			fill = level.Quantity
+		}
+		total += int64(level.Price) * int64(fill)
+		remaining -= fill
+	}
+	if remaining > 0 {
+		return 0, false
+	}
+	return float64(total) / float64(size), true
+}
+
 // FormatViolation returns a human-readable description
 func (v *NoArbViolation) FormatViolation() string {
 	if v.SumBuyPrice < 1.0 {
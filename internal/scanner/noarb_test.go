@@ -0,0 +1,207 @@
+package scanner
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+// addEventBook registers an active binary market of event with a fresh book
+func addEventBook(engine *state.Engine, ticker, event string, bids, asks []state.PriceLevel) {
+	engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive, EventTicker: event})
+	ob := state.NewOrderbook(ticker)
+	ob.Bids = bids
+	ob.Asks = asks
+	ob.LastUpdate = time.Now()
+	engine.UpdateOrderbook(ticker, ob)
+}
+
+func TestEstimateExecutionMakerVsTaker(t *testing.T) {
+	engine := state.NewEngine()
+	addEventBook(engine, "EV-A", "EV",
+		[]state.PriceLevel{{Price: 38, Quantity: 100}},
+		[]state.PriceLevel{{Price: 40, Quantity: 100}})
+	addEventBook(engine, "EV-B", "EV",
+		[]state.PriceLevel{{Price: 48, Quantity: 100}},
+		[]state.PriceLevel{{Price: 50, Quantity: 100}})
+
+	n := NewNoArbEngine(engine, config.ScannerConfig{FeeRate: 0.07, MakerFeeRate: 0.0175, SlippageBufferCents: 0.5})
+	violation := NoArbViolation{EventTicker: "EV", Markets: []string{"EV-A", "EV-B"}, SumBuyPrice: 0.90}
+
+	// Taker lifts the asks (40 + 50): 10¢ gross, 7% fees on 90¢, 0.5¢ buffer per leg
+	taker := n.EstimateExecution(violation, 50, ExecutionTaker)
+	wantTaker := 10 - 0.07*90 - 2*0.5
+	if !taker.Fillable || math.Abs(taker.EdgeCents-wantTaker) > 1e-9 {
+		t.Errorf("taker edge = %.4f¢ (fillable %v), want %.4f¢", taker.EdgeCents, taker.Fillable, wantTaker)
+	}
+
+	// Maker rests at the bids (38 + 48): 14¢ gross, 1.75% fees on 86¢, no slippage
+	maker := n.EstimateExecution(violation, 50, ExecutionMaker)
+	wantMaker := 14 - 0.0175*86
+	if !maker.Fillable || math.Abs(maker.EdgeCents-wantMaker) > 1e-9 || maker.SlippageCents != 0 {
+		t.Errorf("maker edge = %.4f¢ slippage %.4f¢, want %.4f¢ and none", maker.EdgeCents, maker.SlippageCents, wantMaker)
+	}
+
+	if maker.EdgeCents <= taker.EdgeCents {
+		t.Errorf("maker edge %.4f¢ should beat taker %.4f¢", maker.EdgeCents, taker.EdgeCents)
+	}
+}
+
+func TestNoArbOnlyChecksAllowlistedEvents(t *testing.T) {
+	engine := state.NewEngine()
+	// Two events, each with asks summing to 90¢
+	for _, event := range []string{"EV1", "EV2"} {
+		addEventBook(engine, event+"-A", event,
+			[]state.PriceLevel{{Price: 38, Quantity: 100}},
+			[]state.PriceLevel{{Price: 40, Quantity: 100}})
+		addEventBook(engine, event+"-B", event,
+			[]state.PriceLevel{{Price: 48, Quantity: 100}},
+			[]state.PriceLevel{{Price: 50, Quantity: 100}})
+	}
+
+	events := func(allowlist []string) []string {
+		n := NewNoArbEngine(engine, config.ScannerConfig{FeeRate: 0.01, NoArbEvents: allowlist})
+		var got []string
+		for _, violation := range n.CheckNoArbViolations() {
+			got = append(got, violation.EventTicker)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	if got := events(nil); len(got) != 2 {
+		t.Errorf("no allowlist checked %v, want both events", got)
+	}
+	if got := events([]string{"EV2"}); len(got) != 1 || got[0] != "EV2" {
+		t.Errorf("allowlist [EV2] checked %v, want only EV2", got)
+	}
+	if got := events([]string{"OTHER"}); len(got) != 0 {
+		t.Errorf("allowlist [OTHER] checked %v, want none", got)
+	}
+}
+
+func TestNoArbFeesChargedOnceAcrossLegs(t *testing.T) {
+	engine := state.NewEngine()
+	// Three outcomes whose asks sum to 85¢
+	for _, leg := range []struct {
+		ticker string
+		bid    int
+		ask    int
+	}{{"EV-A", 18, 20}, {"EV-B", 28, 30}, {"EV-C", 33, 35}} {
+		addEventBook(engine, leg.ticker, "EV",
+			[]state.PriceLevel{{Price: leg.bid, Quantity: 100}},
+			[]state.PriceLevel{{Price: leg.ask, Quantity: 100}})
+	}
+
+	n := NewNoArbEngine(engine, config.ScannerConfig{FeeRate: 0.07, SlippageBufferCents: 0.5})
+	violations := n.CheckNoArbViolations()
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	v := violations[0]
+
+	// 7% of each leg's price is 7% of the 85¢ sum, not three times that
+	if want := 0.07 * 0.85; math.Abs(v.EstimatedFees-want) > 1e-9 {
+		t.Errorf("fees = %.4f, want %.4f", v.EstimatedFees, want)
+	}
+	if want := 0.15 - 0.07*0.85 - 3*0.005; math.Abs(v.NetArb-want) > 1e-9 {
+		t.Errorf("net arb = %.4f, want %.4f", v.NetArb, want)
+	}
+}
+
+func TestCompleteSetClosedBeforeSettlementPaysExitFees(t *testing.T) {
+	const feeRate = 0.07
+	entry := 85.0
+	entryFees := entry * feeRate
+
+	// Held to resolution: $1 payout, no fee on it
+	held := completeSetPnLCents(entry, entryFees, true, true, 0, 0)
+	if want := 100 - 85 - 0.07*85; math.Abs(held-want) > 1e-9 {
+		t.Errorf("held PnL = %.4f¢, want %.4f¢", held, want)
+	}
+
+	// Closed out at 95¢ just before resolution: the sale pays a fee as well
+	closed := completeSetPnLCents(entry, entryFees, true, false, 95, feeRate)
+	if want := 95 - 85 - 0.07*85 - 0.07*95; math.Abs(closed-want) > 1e-9 {
+		t.Errorf("closed PnL = %.4f¢, want %.4f¢", closed, want)
+	}
+
+	// Closing at the payout price costs exactly the exit fee that holding avoids
+	closedAtPar := completeSetPnLCents(entry, entryFees, true, false, 100, feeRate)
+	if diff := held - closedAtPar; math.Abs(diff-feeRate*100) > 1e-9 {
+		t.Errorf("held - closed at par = %.4f¢, want the %.4f¢ exit fee", diff, feeRate*100)
+	}
+
+	// A sold set is bought back at settlement for the payout, again fee-free
+	sold := completeSetPnLCents(110, 110*feeRate, false, true, 0, 0)
+	if want := 110 - 100 - 0.07*110; math.Abs(sold-want) > 1e-9 {
+		t.Errorf("sold set held PnL = %.4f¢, want %.4f¢", sold, want)
+	}
+}
+
+func TestNoArbMinDollarEdgeFiltersTinySize(t *testing.T) {
+	violation := func(size int) NoArbViolation {
+		engine := state.NewEngine()
+		// Asks sum to 85¢: a 15¢ (17.6%) edge per set, on size contracts
+		addEventBook(engine, "EV-A", "EV",
+			[]state.PriceLevel{{Price: 38, Quantity: 1000}},
+			[]state.PriceLevel{{Price: 40, Quantity: size}})
+		addEventBook(engine, "EV-B", "EV",
+			[]state.PriceLevel{{Price: 43, Quantity: 1000}},
+			[]state.PriceLevel{{Price: 45, Quantity: 1000}})
+
+		n := NewNoArbEngine(engine, config.ScannerConfig{NoArbMinDollarEdge: 5})
+		violations := n.CheckNoArbViolations()
+		if len(violations) != 1 {
+			t.Fatalf("size %d: %d violations, want 1", size, len(violations))
+		}
+		return violations[0]
+	}
+
+	// 12 contracts of a 15¢ edge is $1.80: flagged, but not worth trading
+	tiny := violation(12)
+	if math.Abs(tiny.DollarEdge-1.80) > 1e-9 || tiny.Liquidity != 12 {
+		t.Errorf("tiny: dollar edge $%.2f on %d contracts, want $1.80 on 12", tiny.DollarEdge, tiny.Liquidity)
+	}
+	if tiny.Actionable {
+		t.Error("a $1.80 arb passed the $5 minimum")
+	}
+
+	// The same edge on 100 contracts is $15
+	deep := violation(100)
+	if math.Abs(deep.DollarEdge-15) > 1e-9 || !deep.Actionable {
+		t.Errorf("deep: dollar edge $%.2f actionable %v, want $15 and actionable", deep.DollarEdge, deep.Actionable)
+	}
+}
+
+func TestNoArbSkipsEventsWithNonBinaryMarkets(t *testing.T) {
+	engine := state.NewEngine()
+	// Two events whose asks sum to 90¢; MIX also lists a scalar market
+	for _, event := range []string{"BIN", "MIX"} {
+		addEventBook(engine, event+"-A", event,
+			[]state.PriceLevel{{Price: 38, Quantity: 100}},
+			[]state.PriceLevel{{Price: 40, Quantity: 100}})
+		addEventBook(engine, event+"-B", event,
+			[]state.PriceLevel{{Price: 48, Quantity: 100}},
+			[]state.PriceLevel{{Price: 50, Quantity: 100}})
+	}
+	engine.RegisterMarket(&state.Market{Ticker: "MIX-RANGE", Status: state.StatusActive, EventTicker: "MIX", MarketType: state.MarketTypeScalar})
+
+	n := NewNoArbEngine(engine, config.ScannerConfig{FeeRate: 0.01})
+	groups := n.GroupMarketsByEvent()
+	if _, ok := groups["MIX"]; ok {
+		t.Errorf("event with a scalar market grouped: %v", groups["MIX"])
+	}
+	if len(groups["BIN"]) != 2 {
+		t.Errorf("binary event grouped as %v, want both markets", groups["BIN"])
+	}
+
+	violations := n.CheckNoArbViolations()
+	if len(violations) != 1 || violations[0].EventTicker != "BIN" {
+		t.Errorf("violations = %+v, want only BIN", violations)
+	}
+}
@@ -1,9 +1,11 @@
 package scanner
 
 import (
+	"math"
 	"sort"
 	"time"
 
+	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/state"
 )
 
@@ -18,6 +20,8 @@ type MarketOpportunity struct {
 	BestAsk      int     `json:"best_ask"`      // cents
 	MidPrice     float64 `json:"mid_price"`     // probability (0-100)
 	Spread       int     `json:"spread"`        // cents
+	SpreadTicks  int     `json:"spread_ticks"`  // spread in price increments
+	TickSize     int     `json:"tick_size"`     // cents per tick
 	SpreadPercent float64 `json:"spread_percent"` // percentage points
 
 	// Depth metrics
@@ -25,12 +29,14 @@ type MarketOpportunity struct {
 	AskDepth     int64   `json:"ask_depth"`     // total depth in cents
 	DepthAtTop5  int64   `json:"depth_at_top5"` // contracts at top 5 levels
 	LiquidityScore float64 `json:"liquidity_score"` // 0-1
+	TradabilityScore float64 `json:"tradability_score"` // 0-1, liquidity, freshness, activity and two-sidedness
 
 	// Activity metrics
-	RecentTrades    int       `json:"recent_trades"`     // count in last 30s
+	RecentTrades    int       `json:"recent_trades"`     // count in the recent-trade window
 	LastTradePrice  *int      `json:"last_trade_price"` // cents
 	LastTradeTime   *time.Time `json:"last_trade_time"`
 	TradeIntensity  float64   `json:"trade_intensity"`   // trades per minute
+	ActivityScore   float64   `json:"activity_score"`    // decayed trades per minute, recent trades weigh more
 
 	// Volatility
 	Volatility30s  float64 `json:"volatility_30s"`  // price change in last 30s
@@ -40,26 +46,82 @@ type MarketOpportunity struct {
 	Imbalance      float64 `json:"imbalance"`       // -1 to +1
 	Microprice     float64 `json:"microprice"`      // probability (0-100)
 	MicropriceDiff float64 `json:"microprice_diff"` // microprice - mid
+	FairValue      float64 `json:"fair_value"`      // microprice/VWAP blend (0-100)
+
+	// Expiration
+	ExpirationTime *time.Time `json:"expiration_time,omitempty"`
+	HoursToExpiry  float64    `json:"hours_to_expiry,omitempty"` // unset once expired
 
 	// Staleness
 	LastUpdate     time.Time `json:"last_update"`
 	Staleness      float64   `json:"staleness"`     // seconds since last update
-	BookStale      bool      `json:"book_stale"`    // >5s since update
+	BookStale      bool      `json:"book_stale"`    // older than the configured max book age
 
 	// Execution metrics
-	EstimatedSlippage100 int     `json:"estimated_slippage_100"` // cents for 100 contracts
+	EstimatedSlippage100 float64 `json:"estimated_slippage_100"` // sell_slippage_100, or unfillableSlippage100 when the bids can't fill 100
 	CanExecute100        bool    `json:"can_execute_100"`       // sufficient depth
+
+	// Cents per contract from mid for 100 contracts: buying walks the asks,
+	// selling the bids, and the round trip is both. Null when that side (or
+	// either, for the round trip) can't fill 100.
+	BuySlippage100   *float64 `json:"buy_slippage_100"`
+	SellSlippage100  *float64 `json:"sell_slippage_100"`
+	RoundTripCost100 *float64 `json:"round_trip_cost_100"`
 }
 
+// Clock returns the current time. Scanners use time.Now unless a fixed clock
+// is injected, which makes ScanMarkets output reproducible for a given state.
+type Clock func() time.Time
+
 // Scanner analyzes markets and identifies opportunities
 type Scanner struct {
-	state *state.Engine
+	state  *state.Engine
+	config config.ScannerConfig
+	now    Clock
+}
+
+func NewScanner(stateEngine *state.Engine, cfg config.ScannerConfig) *Scanner {
+	return NewScannerWithClock(stateEngine, cfg, time.Now)
 }
 
-func NewScanner(stateEngine *state.Engine) *Scanner {
+// NewScannerWithClock creates a scanner over a pre-populated state engine that
+// reads all staleness and trade windows relative to clock
+func NewScannerWithClock(stateEngine *state.Engine, cfg config.ScannerConfig, clock Clock) *Scanner {
 	return &Scanner{
-		state: stateEngine,
+		state:  stateEngine,
+		config: cfg,
+		now:    clock,
+	}
+}
+
+// Microprice is the orderbook's microprice over the configured number of levels
+func (s *Scanner) Microprice(orderbook *state.Orderbook) (float64, bool) {
+	return orderbook.MicropriceDepth(s.config.MicropriceLevels)
+}
+
+// maxBookAge is the configured freshness requirement. config.Load always sets
+// it; the 150s fallback matches what Load derives from the default poll cadence.
+func maxBookAge(cfg config.ScannerConfig) time.Duration {
+	if cfg.MaxBookAgeSecs <= 0 {
+		return 150 * time.Second
 	}
+	return time.Duration(cfg.MaxBookAgeSecs) * time.Second
+}
+
+// bookPollInterval is how often books are refreshed, defaulting to 60s
+func bookPollInterval(cfg config.ScannerConfig) time.Duration {
+	if cfg.BookPollIntervalSecs <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(cfg.BookPollIntervalSecs) * time.Second
+}
+
+// recentTradeWindow is the configured recent-trade window, defaulting to 30s
+func (s *Scanner) recentTradeWindow() time.Duration {
+	if s.config.RecentTradeWindowSecs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(s.config.RecentTradeWindowSecs) * time.Second
 }
 
 // ScanMarkets analyzes all active markets and returns opportunities
@@ -68,37 +130,61 @@ func (s *Scanner) ScanMarkets() []MarketOpportunity {
 	var opportunities []MarketOpportunity
 
 	for _, market := range markets {
-		if market.Status != state.StatusActive {
+		if !market.IsTradeable(s.now()) {
 			continue
 		}
 
-		opp := s.analyzeMarket(market.Ticker, market.Title, string(market.Status))
+		opp := s.analyzeMarket(market)
 		if opp != nil {
 			opportunities = append(opportunities, *opp)
 		}
 	}
 
-	// Sort by liquidity score (best first)
+	// Sort by liquidity score (best first), ticker breaks ties so output is stable
 	sort.Slice(opportunities, func(i, j int) bool {
-		return opportunities[i].LiquidityScore > opportunities[j].LiquidityScore
+		if opportunities[i].LiquidityScore != opportunities[j].LiquidityScore {
+			return opportunities[i].LiquidityScore > opportunities[j].LiquidityScore
+		}
+		return opportunities[i].MarketTicker < opportunities[j].MarketTicker
 	})
 
 	return opportunities
 }
 
-func (s *Scanner) analyzeMarket(ticker, title, status string) *MarketOpportunity {
+// ScanMarket analyzes a single market regardless of status
+func (s *Scanner) ScanMarket(ticker string) (*MarketOpportunity, bool) {
+	market, exists := s.state.GetMarket(ticker)
+	if !exists {
+		return nil, false
+	}
+
+	opp := s.analyzeMarket(market)
+	return opp, opp != nil
+}
+
+func (s *Scanner) analyzeMarket(market *state.Market) *MarketOpportunity {
+	ticker := market.Ticker
+
 	orderbook, exists := s.state.GetOrderbook(ticker)
 	if !exists || len(orderbook.Bids) == 0 || len(orderbook.Asks) == 0 {
 		return nil
 	}
 
+	now := s.now()
 	opp := &MarketOpportunity{
 		MarketTicker: ticker,
-		Title:        title,
-		Status:       status,
+		Title:        market.Title,
+		Status:       string(market.Status),
 		LastUpdate:   orderbook.LastUpdate,
-		Staleness:    time.Since(orderbook.LastUpdate).Seconds(),
-		BookStale:    time.Since(orderbook.LastUpdate) > 5*time.Second,
+		Staleness:    now.Sub(orderbook.LastUpdate).Seconds(),
+		BookStale:    now.Sub(orderbook.LastUpdate) > maxBookAge(s.config),
+	}
+	if market.ExpirationTime != nil {
+		expiration := *market.ExpirationTime
+		opp.ExpirationTime = &expiration
+		if remaining := expiration.Sub(now); remaining > 0 {
+			opp.HoursToExpiry = remaining.Hours()
+		}
 	}
 
 	// Top-of-book
@@ -107,6 +193,8 @@ func (s *Scanner) analyzeMarket(ticker, title, status string) *MarketOpportunity
 	opp.MidPrice = float64(opp.BestBid+opp.BestAsk) / 200.0
 	opp.Spread = opp.BestAsk - opp.BestBid
 	opp.SpreadPercent = float64(opp.Spread) / 100.0
+	opp.TickSize = market.Tick()
+	opp.SpreadTicks = market.SpreadInTicks(opp.Spread)
 
 	// Depth
 	opp.BidDepth = orderbook.BidDepth()
@@ -115,7 +203,12 @@ func (s *Scanner) analyzeMarket(ticker, title, status string) *MarketOpportunity
 	opp.DepthAtTop5 = bidDepth5 + askDepth5
 
 	// Liquidity score (0-1): based on tight spread and good depth
-	spreadScore := 1.0 - (float64(opp.Spread) / 100.0) // tighter is better
+	// Spread score (tighter is better). A one-tick spread is the tightest possible,
+	// so only spread beyond one tick counts against the market.
+	spreadScore := 1.0 - (float64(opp.Spread-opp.TickSize) / 100.0)
+	if spreadScore > 1 {
+		spreadScore = 1
+	}
 	if spreadScore < 0 {
 		spreadScore = 0
 	}
@@ -127,69 +220,63 @@ func (s *Scanner) analyzeMarket(ticker, title, status string) *MarketOpportunity
 
 	// Microstructure
 	opp.Imbalance = orderbook.ImbalanceRatio()
-	if microprice, ok := orderbook.Microprice(); ok {
+	if microprice, ok := s.Microprice(orderbook); ok {
 		opp.Microprice = microprice * 100.0
-		opp.MicropriceDiff = opp.Microprice - opp.MidPrice
+		opp.MicropriceDiff = opp.Microprice - opp.MidPrice*100.0 // both in cents
+	}
+	if fairValue, ok := s.FairValue(ticker); ok {
+		opp.FairValue = fairValue
 	}
 
 	// Recent trades
-	recentTrades := s.state.GetRecentTrades(ticker, 30*time.Second)
+	tradeWindow := s.recentTradeWindow()
+	recentTrades := s.state.GetTradesSince(ticker, now.Add(-tradeWindow))
 	opp.RecentTrades = len(recentTrades)
 	if len(recentTrades) > 0 {
 		lastTrade := recentTrades[len(recentTrades)-1]
 		opp.LastTradePrice = &lastTrade.Price
 		opp.LastTradeTime = &lastTrade.Timestamp
-		opp.TradeIntensity = float64(len(recentTrades)) / 0.5 // trades per minute
+		opp.TradeIntensity = float64(len(recentTrades)) / tradeWindow.Minutes() // trades per minute
 	}
+	opp.ActivityScore = s.state.ActivityScore(ticker, now)
 
 	// Volatility (price change in last 30s)
 	ts := s.state.GetTimeSeries()
-	if priceChange, ok := ts.GetPriceChange(ticker, 30*time.Second); ok {
+	if priceChange, ok := ts.GetPriceChangeAt(ticker, 30*time.Second, now); ok {
 		opp.PriceChange30s = priceChange
 		opp.Volatility30s = priceChange // Simplified
 	}
 
 	// Execution metrics
-	opp.EstimatedSlippage100 = s.estimateSlippage(orderbook, 100)
-	opp.CanExecute100 = opp.DepthAtTop5 >= 100 && opp.Spread < 50 // reasonable spread
-
-	return opp
-}
-
-// estimateSlippage estimates slippage for executing Q contracts
-func (s *Scanner) estimateSlippage(orderbook *state.Orderbook, quantity int) int {
-	// Simulate walking the book
-	remaining := quantity
-	totalCost := 0
-	avgPrice := 0.0
-
-	// Walk bids (if selling)
-	for _, level := range orderbook.Bids {
-		if remaining <= 0 {
-			break
-		}
-		fillQty := remaining
-		if fillQty > level.Quantity {
-			fillQty = level.Quantity
-		}
-		totalCost += level.Price * fillQty
-		remaining -= fillQty
+	mid := float64(orderbook.Bids[0].Price+orderbook.Asks[0].Price) / 2.0
+	opp.BuySlippage100 = sideSlippage(orderbook.Asks, 100, mid)
+	opp.SellSlippage100 = sideSlippage(orderbook.Bids, 100, mid)
+	opp.EstimatedSlippage100 = unfillableSlippage100
+	if opp.SellSlippage100 != nil {
+		opp.EstimatedSlippage100 = *opp.SellSlippage100
 	}
-
-	if remaining > 0 {
-		// Not enough depth, estimate worst case
-		return 10000 // 100% slippage (can't fill)
+	if opp.BuySlippage100 != nil && opp.SellSlippage100 != nil {
+		roundTrip := *opp.BuySlippage100 + *opp.SellSlippage100
+		opp.RoundTripCost100 = &roundTrip
 	}
+	opp.CanExecute100 = opp.DepthAtTop5 >= 100 && opp.Spread < 50 && !opp.BookStale // reasonable spread, fresh book
 
-	avgPrice = float64(totalCost) / float64(quantity)
-	midPrice := float64(orderbook.Bids[0].Price+orderbook.Asks[0].Price) / 2.0
-	slippage := int(avgPrice - midPrice)
-
-	if slippage < 0 {
-		slippage = -slippage
-	}
+	opp.TradabilityScore = s.tradabilityScore(opp, bidDepth5, askDepth5)
 
-	return slippage
+	return opp
 }
 
+// unfillableSlippage100 is EstimatedSlippage100 for a book whose bids can't
+// fill 100 contracts, kept so existing consumers still read it as prohibitive
+const unfillableSlippage100 = 10000
 
+// sideSlippage is how far the average fill for quantity contracts walking
+// levels lies from mid, in cents per contract, or nil if the side is too thin
+func sideSlippage(levels []state.PriceLevel, quantity int, mid float64) *float64 {
+	avg, ok := walkBook(levels, quantity)
+	if !ok {
+		return nil
+	}
+	slippage := math.Abs(avg - mid)
+	return &slippage
+}
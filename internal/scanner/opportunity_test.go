@@ -0,0 +1,319 @@
+package scanner
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// fixtureNow is the fixed clock every fixture scan runs at
+var fixtureNow = time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+
+func fixtureConfig() config.ScannerConfig {
+	return config.ScannerConfig{
+		FairValueVWAPWeight:        0.4,
+		FairValueMinTrades:         10,
+		RecentTradeWindowSecs:      30,
+		MaxBookAgeSecs:             5,
+		MicropriceLevels:           1,
+		TradabilityLiquidityWeight: 0.4,
+		TradabilityFreshnessWeight: 0.2,
+		TradabilityActivityWeight:  0.2,
+		TradabilityTwoSidedWeight:  0.2,
+	}
+}
+
+func fixtureBook(ticker string, age time.Duration, bids, asks []state.PriceLevel) *state.Orderbook {
+	ob := state.NewOrderbook(ticker)
+	ob.Bids = bids
+	ob.Asks = asks
+	ob.LastUpdate = fixtureNow.Add(-age)
+	return ob
+}
+
+// fixtureEngine is a state engine holding a spread of books: deep and tight,
+// wide and stale, non-unit tick, one-sided, and markets that aren't tradeable
+func fixtureEngine() *state.Engine {
+	engine := state.NewEngine()
+	at := func(d time.Duration) *time.Time {
+		t := fixtureNow.Add(d)
+		return &t
+	}
+
+	engine.RegisterMarket(&state.Market{Ticker: "DEEP-TIGHT", Title: "Deep, tight book", Status: state.StatusActive, EventTicker: "EV-A"})
+	engine.UpdateOrderbook("DEEP-TIGHT", fixtureBook("DEEP-TIGHT", 2*time.Second,
+		[]state.PriceLevel{{Price: 45, Quantity: 300}, {Price: 44, Quantity: 200}, {Price: 43, Quantity: 500}},
+		[]state.PriceLevel{{Price: 47, Quantity: 250}, {Price: 48, Quantity: 400}}))
+	for _, trade := range []struct {
+		ago   time.Duration
+		price int
+		side  state.TradeSide
+	}{{90 * time.Second, 46, state.SideNo}, {20 * time.Second, 47, state.SideYes}, {5 * time.Second, 47, state.SideYes}} {
+		engine.AddTrade(&state.Trade{MarketTicker: "DEEP-TIGHT", Side: trade.side, Price: trade.price, Quantity: 25, Timestamp: fixtureNow.Add(-trade.ago)})
+	}
+
+	engine.RegisterMarket(&state.Market{Ticker: "WIDE-STALE", Title: "Wide, stale book", Status: state.StatusActive, EventTicker: "EV-A"})
+	engine.UpdateOrderbook("WIDE-STALE", fixtureBook("WIDE-STALE", 5*time.Minute,
+		[]state.PriceLevel{{Price: 20, Quantity: 50}},
+		[]state.PriceLevel{{Price: 60, Quantity: 40}}))
+
+	engine.RegisterMarket(&state.Market{Ticker: "TICK-FIVE", Title: "Five-cent tick", Status: state.StatusActive, EventTicker: "EV-B", TickSize: 5, ExpirationTime: at(3 * time.Hour)})
+	engine.UpdateOrderbook("TICK-FIVE", fixtureBook("TICK-FIVE", time.Second,
+		[]state.PriceLevel{{Price: 50, Quantity: 100}, {Price: 45, Quantity: 100}},
+		[]state.PriceLevel{{Price: 55, Quantity: 60}, {Price: 60, Quantity: 300}}))
+
+	engine.RegisterMarket(&state.Market{Ticker: "BID-ONLY", Title: "Bids only", Status: state.StatusActive, EventTicker: "EV-B"})
+	engine.UpdateOrderbook("BID-ONLY", fixtureBook("BID-ONLY", time.Second,
+		[]state.PriceLevel{{Price: 90, Quantity: 100}}, nil))
+
+	engine.RegisterMarket(&state.Market{Ticker: "CLOSED", Title: "Closed market", Status: state.StatusClosed, EventTicker: "EV-C"})
+	engine.UpdateOrderbook("CLOSED", fixtureBook("CLOSED", time.Second,
+		[]state.PriceLevel{{Price: 40, Quantity: 100}},
+		[]state.PriceLevel{{Price: 42, Quantity: 100}}))
+
+	engine.RegisterMarket(&state.Market{Ticker: "PRE-OPEN", Title: "Not open yet", Status: state.StatusActive, EventTicker: "EV-C", OpenTime: at(time.Hour)})
+	engine.UpdateOrderbook("PRE-OPEN", fixtureBook("PRE-OPEN", time.Second,
+		[]state.PriceLevel{{Price: 40, Quantity: 100}},
+		[]state.PriceLevel{{Price: 42, Quantity: 100}}))
+
+	return engine
+}
+
+// checkGolden compares got, as indented JSON, with testdata/name, rewriting
+// the file instead when run with -update
+func checkGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if string(data) != string(want) {
+		t.Errorf("%s changed; run with -update if intended\ngot:\n%s", path, data)
+	}
+}
+
+func TestScanMarketsGolden(t *testing.T) {
+	scanner := NewScannerWithClock(fixtureEngine(), fixtureConfig(), func() time.Time { return fixtureNow })
+	checkGolden(t, "scan_markets.golden.json", scanner.ScanMarkets())
+}
+
+func TestScanMarketsIsRepeatable(t *testing.T) {
+	engine := fixtureEngine()
+	scanner := NewScannerWithClock(engine, fixtureConfig(), func() time.Time { return fixtureNow })
+
+	first, err := json.Marshal(scanner.ScanMarkets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := json.Marshal(scanner.ScanMarkets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Fatal("two scans of the same state differ")
+	}
+}
+
+func TestOneTickSpreadScoresAsTightest(t *testing.T) {
+	engine := state.NewEngine()
+	add := func(ticker string, tick, bid, ask int) {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive, TickSize: tick})
+		engine.UpdateOrderbook(ticker, fixtureBook(ticker, time.Second,
+			[]state.PriceLevel{{Price: bid, Quantity: 200}},
+			[]state.PriceLevel{{Price: ask, Quantity: 200}}))
+	}
+	// A 2¢ spread is one tick on a 2¢-tick market, the same as 1¢ on a 1¢ tick,
+	// and both beat a 2¢ spread on a 1¢ tick
+	add("TICK-TWO", 2, 48, 50)
+	add("TICK-ONE", 1, 49, 50)
+	add("TICK-ONE-WIDE", 1, 48, 50)
+
+	s := NewScannerWithClock(engine, fixtureConfig(), func() time.Time { return fixtureNow })
+	scores := make(map[string]MarketOpportunity)
+	for _, opp := range s.ScanMarkets() {
+		scores[opp.MarketTicker] = opp
+	}
+
+	if got := scores["TICK-TWO"].SpreadTicks; got != 1 {
+		t.Errorf("2¢ spread on a 2¢ tick = %d ticks, want 1", got)
+	}
+	if scores["TICK-TWO"].LiquidityScore != scores["TICK-ONE"].LiquidityScore {
+		t.Errorf("one-tick spreads score %.4f (2¢ tick) and %.4f (1¢ tick), want equal",
+			scores["TICK-TWO"].LiquidityScore, scores["TICK-ONE"].LiquidityScore)
+	}
+	if scores["TICK-ONE-WIDE"].LiquidityScore >= scores["TICK-ONE"].LiquidityScore {
+		t.Errorf("two-tick spread scores %.4f, want below one-tick %.4f",
+			scores["TICK-ONE-WIDE"].LiquidityScore, scores["TICK-ONE"].LiquidityScore)
+	}
+}
+
+// nonFinite lists the float fields of v (a struct) that are NaN or ±Inf
+func nonFinite(v interface{}) []string {
+	var bad []string
+	rv := reflect.ValueOf(v)
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Float64 {
+			if f := field.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+				bad = append(bad, rv.Type().Field(i).Name)
+			}
+		}
+	}
+	return bad
+}
+
+func TestExtremePriceBooksStayFinite(t *testing.T) {
+	engine := state.NewEngine()
+	books := map[string][2][]state.PriceLevel{
+		// Pinned at the floor, with one side a thousand times deeper
+		"FLOOR": {{{Price: 1, Quantity: 5000}}, {{Price: 2, Quantity: 5}}},
+		// Pinned at the ceiling
+		"CEILING": {{{Price: 98, Quantity: 1}}, {{Price: 99, Quantity: 10000}}},
+		// Widest possible spread
+		"WIDEST": {{{Price: 1, Quantity: 1}}, {{Price: 99, Quantity: 1}}},
+	}
+	for ticker, sides := range books {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive})
+		engine.UpdateOrderbook(ticker, fixtureBook(ticker, time.Second, sides[0], sides[1]))
+		for i := 0; i < 3; i++ {
+			// Identical trade prices: zero variance for anything normalized by it
+			engine.AddTrade(&state.Trade{MarketTicker: ticker, Side: state.SideYes, Price: sides[1][0].Price, Quantity: 10, Timestamp: fixtureNow.Add(-time.Duration(i+1) * time.Second)})
+		}
+	}
+
+	s := NewScannerWithClock(engine, fixtureConfig(), func() time.Time { return fixtureNow })
+	opportunities := s.ScanMarkets()
+	if len(opportunities) != len(books) {
+		t.Fatalf("got %d opportunities, want %d", len(opportunities), len(books))
+	}
+	if _, err := json.Marshal(opportunities); err != nil {
+		t.Fatalf("opportunities don't encode: %v", err)
+	}
+
+	for _, opp := range opportunities {
+		if bad := nonFinite(opp); len(bad) > 0 {
+			t.Errorf("%s: non-finite %v", opp.MarketTicker, bad)
+		}
+		if opp.Imbalance < -1 || opp.Imbalance > 1 {
+			t.Errorf("%s: imbalance %.3f outside [-1, 1]", opp.MarketTicker, opp.Imbalance)
+		}
+		// Microprice and mid are both in cents, so their gap fits in the spread
+		if math.Abs(opp.MicropriceDiff) > float64(opp.Spread) {
+			t.Errorf("%s: microprice diff %.2f¢ exceeds the %d¢ spread", opp.MarketTicker, opp.MicropriceDiff, opp.Spread)
+		}
+	}
+}
+
+func TestTradeIntensityAtNonDefaultWindow(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	engine.UpdateOrderbook("MKT", fixtureBook("MKT", time.Second,
+		[]state.PriceLevel{{Price: 49, Quantity: 100}},
+		[]state.PriceLevel{{Price: 51, Quantity: 100}}))
+	// Six trades inside two minutes and one just outside it
+	for _, ago := range []time.Duration{5, 20, 45, 70, 90, 115, 130} {
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Side: state.SideYes, Price: 50, Quantity: 10, Timestamp: fixtureNow.Add(-ago * time.Second)})
+	}
+
+	tests := []struct {
+		windowSecs int
+		trades     int
+		intensity  float64
+	}{
+		{120, 6, 3},       // 6 trades over 2 minutes
+		{10, 1, 6},        // 1 trade over 1/6 minute
+		{60, 3, 3},        // 3 trades over 1 minute
+		{180, 7, 7.0 / 3}, // 7 trades over 3 minutes
+	}
+	for _, tt := range tests {
+		cfg := fixtureConfig()
+		cfg.RecentTradeWindowSecs = tt.windowSecs
+		opps := NewScannerWithClock(engine, cfg, func() time.Time { return fixtureNow }).ScanMarkets()
+		if len(opps) != 1 {
+			t.Fatalf("window %ds: %d opportunities, want 1", tt.windowSecs, len(opps))
+		}
+		if opps[0].RecentTrades != tt.trades {
+			t.Errorf("window %ds: recent trades = %d, want %d", tt.windowSecs, opps[0].RecentTrades, tt.trades)
+		}
+		if math.Abs(opps[0].TradeIntensity-tt.intensity) > 1e-9 {
+			t.Errorf("window %ds: intensity = %.4f/min, want %.4f", tt.windowSecs, opps[0].TradeIntensity, tt.intensity)
+		}
+	}
+}
+
+func TestSlippageDiffersOnAsymmetricBook(t *testing.T) {
+	engine := state.NewEngine()
+	add := func(ticker string, bids, asks []state.PriceLevel) {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive})
+		engine.UpdateOrderbook(ticker, fixtureBook(ticker, time.Second, bids, asks))
+	}
+	// Mid 46¢: 100 bids sit at 45¢, but only 20 asks at 47¢ before 50¢
+	add("ASYM",
+		[]state.PriceLevel{{Price: 45, Quantity: 100}},
+		[]state.PriceLevel{{Price: 47, Quantity: 20}, {Price: 50, Quantity: 80}})
+	// The same asks over bids too thin to sell 100
+	add("THIN-BIDS",
+		[]state.PriceLevel{{Price: 45, Quantity: 50}},
+		[]state.PriceLevel{{Price: 47, Quantity: 20}, {Price: 50, Quantity: 80}})
+
+	s := NewScannerWithClock(engine, fixtureConfig(), func() time.Time { return fixtureNow })
+	opps := make(map[string]MarketOpportunity)
+	for _, opp := range s.ScanMarkets() {
+		opps[opp.MarketTicker] = opp
+	}
+
+	asym := opps["ASYM"]
+	if asym.BuySlippage100 == nil || asym.SellSlippage100 == nil || asym.RoundTripCost100 == nil {
+		t.Fatalf("ASYM slippage missing: buy %v sell %v round trip %v", asym.BuySlippage100, asym.SellSlippage100, asym.RoundTripCost100)
+	}
+	// Buying averages (20×47 + 80×50)/100 = 49.4¢, selling 45¢
+	for name, tt := range map[string]struct{ got, want float64 }{
+		"buy":        {*asym.BuySlippage100, 3.4},
+		"sell":       {*asym.SellSlippage100, 1},
+		"round trip": {*asym.RoundTripCost100, 4.4},
+		"estimated":  {asym.EstimatedSlippage100, 1},
+	} {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s slippage = %.4f¢, want %.4f¢", name, tt.got, tt.want)
+		}
+	}
+
+	thin := opps["THIN-BIDS"]
+	if thin.BuySlippage100 == nil || math.Abs(*thin.BuySlippage100-3.4) > 1e-9 {
+		t.Errorf("THIN-BIDS buy slippage = %v, want 3.4", thin.BuySlippage100)
+	}
+	if thin.SellSlippage100 != nil || thin.RoundTripCost100 != nil {
+		t.Errorf("THIN-BIDS sell %v round trip %v, want both null", thin.SellSlippage100, thin.RoundTripCost100)
+	}
+	if thin.EstimatedSlippage100 != unfillableSlippage100 {
+		t.Errorf("THIN-BIDS estimated slippage = %v, want %v", thin.EstimatedSlippage100, unfillableSlippage100)
+	}
+}
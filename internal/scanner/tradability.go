@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"math"
+	"time"
+)
+
+// activeTradesPerMinute is the trade rate at which a market counts as fully active
+const activeTradesPerMinute = 2.0
+
+// tradabilityScore blends an opportunity's liquidity score with book freshness,
+// trade activity and two-sidedness into one 0-1 ranking number, weighted by
+// the configured tradability weights. bidDepth and askDepth are the contracts
+// within 5 cents of mid on each side.
+func (s *Scanner) tradabilityScore(opp *MarketOpportunity, bidDepth, askDepth int64) float64 {
+	// Full marks up to the max book age, then halving for every poll the
+	// book has missed beyond that
+	maxAge := maxBookAge(s.config)
+	freshness := 1.0
+	if age := time.Duration(opp.Staleness * float64(time.Second)); age > maxAge {
+		freshness = math.Pow(0.5, float64(age-maxAge)/float64(bookPollInterval(s.config)))
+	}
+
+	activity := math.Min(opp.ActivityScore/activeTradesPerMinute, 1)
+
+	twoSided := 0.0
+	if larger := max(bidDepth, askDepth); larger > 0 {
+		twoSided = float64(min(bidDepth, askDepth)) / float64(larger)
+	}
+
+	components := []struct{ score, weight float64 }{
+		{opp.LiquidityScore, s.config.TradabilityLiquidityWeight},
+		{freshness, s.config.TradabilityFreshnessWeight},
+		{activity, s.config.TradabilityActivityWeight},
+		{twoSided, s.config.TradabilityTwoSidedWeight},
+	}
+
+	var total, weights float64
+	for _, c := range components {
+		if c.weight <= 0 {
+			continue
+		}
+		total += c.score * c.weight
+		weights += c.weight
+	}
+	if weights == 0 {
+		return opp.LiquidityScore
+	}
+	return total / weights
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestTradabilityFreshnessHalvesPerPollInterval(t *testing.T) {
+	cfg := config.ScannerConfig{MaxBookAgeSecs: 150, BookPollIntervalSecs: 60, TradabilityFreshnessWeight: 1}
+
+	tests := []struct {
+		age  time.Duration
+		want float64
+	}{
+		{10 * time.Second, 1},
+		{150 * time.Second, 1},
+		{210 * time.Second, 0.5},  // one poll missed beyond the max age
+		{270 * time.Second, 0.25}, // two
+	}
+	for _, tt := range tests {
+		engine := state.NewEngine()
+		engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+		engine.UpdateOrderbook("MKT", fixtureBook("MKT", tt.age,
+			[]state.PriceLevel{{Price: 49, Quantity: 100}},
+			[]state.PriceLevel{{Price: 51, Quantity: 100}}))
+
+		opps := NewScannerWithClock(engine, cfg, func() time.Time { return fixtureNow }).ScanMarkets()
+		if len(opps) != 1 {
+			t.Fatalf("age %v: %d opportunities, want 1", tt.age, len(opps))
+		}
+		if got := opps[0].TradabilityScore; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("age %v: freshness = %.4f, want %.4f", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestTradabilityRanksHealthyAboveStaleThinLopsided(t *testing.T) {
+	engine := state.NewEngine()
+
+	engine.RegisterMarket(&state.Market{Ticker: "GOOD", Status: state.StatusActive})
+	engine.UpdateOrderbook("GOOD", fixtureBook("GOOD", time.Second,
+		[]state.PriceLevel{{Price: 49, Quantity: 500}, {Price: 48, Quantity: 500}},
+		[]state.PriceLevel{{Price: 50, Quantity: 500}, {Price: 51, Quantity: 500}}))
+	for i := 1; i <= 10; i++ {
+		engine.AddTrade(&state.Trade{MarketTicker: "GOOD", Side: state.SideYes, Price: 50, Quantity: 10, Timestamp: fixtureNow.Add(-time.Duration(i) * 2 * time.Second)})
+	}
+
+	// Stale, wide, a handful of contracts, nearly all on one side, no trades
+	engine.RegisterMarket(&state.Market{Ticker: "BAD", Status: state.StatusActive})
+	engine.UpdateOrderbook("BAD", fixtureBook("BAD", 10*time.Minute,
+		[]state.PriceLevel{{Price: 30, Quantity: 20}},
+		[]state.PriceLevel{{Price: 34, Quantity: 1}}))
+
+	opps := NewScannerWithClock(engine, fixtureConfig(), func() time.Time { return fixtureNow }).ScanMarkets()
+	scores := map[string]float64{}
+	for _, opp := range opps {
+		scores[opp.MarketTicker] = opp.TradabilityScore
+	}
+	good, okGood := scores["GOOD"]
+	bad, okBad := scores["BAD"]
+	if !okGood || !okBad {
+		t.Fatalf("scanned %v, want both markets", scores)
+	}
+
+	if good <= bad {
+		t.Errorf("healthy market scored %.4f, not above the stale, thin one's %.4f", good, bad)
+	}
+	for name, score := range scores {
+		if score < 0 || score > 1 {
+			t.Errorf("%s: score %.4f outside 0-1", name, score)
+		}
+	}
+	if good < 0.8 || bad > 0.3 {
+		t.Errorf("scores %.4f / %.4f aren't far apart enough to rank on", good, bad)
+	}
+}
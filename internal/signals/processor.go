@@ -2,8 +2,10 @@ package signals
 
 import (
 	"context"
+	"hash/fnv"
 	"time"
 
+	"github.com/kalshi-signal-feed/internal/audit"
 	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/state"
 )
@@ -12,91 +14,256 @@ type Processor struct {
 	state      *state.Engine
 	signalChan chan<- Signal
 	config     config.SignalConfig
+	auditLog   *audit.Log
+
+	// Last observed book state per market, for detecting liquidity withdrawal
+	bookStates map[string]bookSides
+
+	// Last evaluation time per market, for debouncing update-driven evaluation
+	lastEvaluated map[string]time.Time
+
+	// Book levels per side weighted into quant microprices
+	micropriceLevels int
 }
 
-func NewProcessor(state *state.Engine, signalChan chan<- Signal, cfg config.SignalConfig) *Processor {
+func NewProcessor(stateEngine *state.Engine, signalChan chan<- Signal, cfg config.SignalConfig) *Processor {
 	return &Processor{
-		state:      state,
+		state:      stateEngine,
 		signalChan: signalChan,
 		config:     cfg,
+		bookStates: make(map[string]bookSides),
+		lastEvaluated: make(map[string]time.Time),
+		micropriceLevels: 1,
 	}
 }
 
+// SetAuditLog attaches an audit log that receives every emitted signal
+func (p *Processor) SetAuditLog(auditLog *audit.Log) {
+	p.auditLog = auditLog
+}
+
+// SetMicropriceLevels sets how many book levels per side quant microprices
+// weigh, so they match the scanner's
+func (p *Processor) SetMicropriceLevels(levels int) {
+	p.micropriceLevels = levels
+}
+
+// Run evaluates every active market once per computation interval. The interval
+// is divided into StaggerSlots sub-ticks and each market is assigned a fixed slot
+// by hashing its ticker, so load and signal output are spread evenly instead of
+// bursting on the interval boundary. Markets are listed and bucketed by slot
+// once per interval, at its first sub-tick. Between sweeps, book and trade
+// updates from the state engine trigger an immediate (debounced) evaluation of
+// that one market.
+// Quant metrics run on their own, slower schedule.
 func (p *Processor) Run(ctx context.Context) error {
-	ticker := time.NewTicker(time.Duration(p.config.ComputationIntervalSecs) * time.Second)
+	slots := p.config.StaggerSlots
+	if slots < 1 {
+		slots = 1
+	}
+
+	interval := time.Duration(p.config.ComputationIntervalSecs) * time.Second
+	ticker := time.NewTicker(interval / time.Duration(slots))
 	defer ticker.Stop()
 
+	quantTicker := time.NewTicker(p.quantInterval())
+	defer quantTicker.Stop()
+
+	updates := p.state.Updates()
+
+	var due [][]*state.Market
+	slot := 0
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case marketTicker := <-updates:
+			p.handleUpdate(marketTicker)
 		case <-ticker.C:
-			p.computeSignals()
+			if slot == 0 {
+				due = p.marketsBySlot(slots)
+			}
+			p.computeSignals(due[slot])
+			slot = (slot + 1) % slots
+		case <-quantTicker.C:
+			p.computeQuantSignals()
 		}
 	}
 }
 
-func (p *Processor) computeSignals() {
-	markets := p.state.GetAllMarkets()
+// marketSlot assigns a market to a stable slot in [0, slots)
+func marketSlot(ticker string, slots int) int {
+	h := fnv.New32a()
+	h.Write([]byte(ticker))
+	return int(h.Sum32() % uint32(slots))
+}
 
-	for _, market := range markets {
-		if market.Status != state.StatusActive {
+// marketsBySlot lists the tradeable markets once and buckets them by slot
+func (p *Processor) marketsBySlot(slots int) [][]*state.Market {
+	due := make([][]*state.Market, slots)
+	for _, market := range p.state.GetAllMarkets() {
+		if !market.Tradeable {
 			continue
 		}
+		slot := marketSlot(market.Ticker, slots)
+		due[slot] = append(due[slot], market)
+	}
+	return due
+}
+
+// computeSignals evaluates one slot's markets
+func (p *Processor) computeSignals(markets []*state.Market) {
+	for _, market := range markets {
+		p.evaluateMarket(market)
+	}
+}
+
+// handleUpdate evaluates a single market right after its book or trades change,
+// at most once per debounce period; the periodic sweep covers anything skipped
+func (p *Processor) handleUpdate(ticker string) {
+	debounce := time.Duration(p.config.EventDebounceMs) * time.Millisecond
+	if last, ok := p.lastEvaluated[ticker]; ok && time.Since(last) < debounce {
+		return
+	}
+
+	market, exists := p.state.GetMarket(ticker)
+	if !exists || !market.Tradeable {
+		return
+	}
+
+	p.evaluateMarket(market)
+}
+
+// evaluateMarket computes and emits every signal type for one market
+func (p *Processor) evaluateMarket(market *state.Market) {
+	p.lastEvaluated[market.Ticker] = time.Now()
+
+	orderbook, exists := p.state.GetOrderbook(market.Ticker)
+	if !exists {
+		return
+	}
+
+	// Book state is tracked from the start so a withdrawal right after warm-up
+	// is still detected, but nothing is emitted until the market has warmed up
+	withdrawal := p.detectLiquidityWithdrawal(market.Ticker, orderbook)
+	if !p.state.GetWarmupStatus(market.Ticker).WarmedUp {
+		return
+	}
+
+	// Detect a two-sided book going one-sided or empty
+	if withdrawal != nil {
+		p.emit(*withdrawal)
+	}
+
+	// Compute orderbook imbalance
+	if signal := p.computeOrderbookImbalance(market.Ticker, orderbook); signal != nil {
+		p.emit(*signal)
+	}
 
-		orderbook, exists := p.state.GetOrderbook(market.Ticker)
-		if !exists {
+	// Compute implied probability drift
+	if signal := p.computeImpliedProbabilityDrift(market.Ticker, orderbook); signal != nil {
+		p.emit(*signal)
+	}
+
+	// Detect volume surge
+	if signal := p.detectVolumeSurge(market.Ticker); signal != nil {
+		p.emit(*signal)
+	}
+
+	// Detect rapid top-of-book flicker
+	if signal := p.detectQuoteFlicker(market.Ticker); signal != nil {
+		p.emit(*signal)
+	}
+}
+
+// defaultVolumeBaselineMultiplier applies when no baseline multiplier is set
+const defaultVolumeBaselineMultiplier = 5
+
+// defaultQuantInterval applies when no positive quant interval is configured
+const defaultQuantInterval = 10 * time.Second
+
+// quantInterval is how often quant metrics are computed
+func (p *Processor) quantInterval() time.Duration {
+	if p.config.QuantIntervalSecs <= 0 {
+		return defaultQuantInterval
+	}
+	return time.Duration(p.config.QuantIntervalSecs) * time.Second
+}
+
+// computeQuantSignals records quant metrics for every warmed-up tradeable
+// market whose decayed trade rate meets the configured minimum, and emits
+// each as a signal (always, even if no threshold is crossed)
+func (p *Processor) computeQuantSignals() {
+	now := time.Now()
+	for _, market := range p.state.GetAllMarkets() {
+		if !market.Tradeable || !p.state.GetWarmupStatus(market.Ticker).WarmedUp {
 			continue
 		}
-
-		// Compute orderbook imbalance
-		if signal := p.computeOrderbookImbalance(market.Ticker, orderbook); signal != nil {
-			select {
-			case p.signalChan <- *signal:
-			default:
-				// Channel full, skip
-			}
+		if p.state.ActivityScore(market.Ticker, now) < p.config.QuantMinActivity {
+			continue
 		}
+		p.computeQuant(market)
+	}
+}
 
-		// Compute implied probability drift
-		if signal := p.computeImpliedProbabilityDrift(market.Ticker, orderbook); signal != nil {
-			select {
-			case p.signalChan <- *signal:
-			default:
-				// Channel full, skip
-			}
-		}
+func (p *Processor) computeQuant(market *state.Market) {
+	orderbook, exists := p.state.GetOrderbook(market.Ticker)
+	if !exists {
+		return
+	}
 
-		// Detect volume surge
-		if signal := p.detectVolumeSurge(market.Ticker); signal != nil {
-			select {
-			case p.signalChan <- *signal:
-			default:
-				// Channel full, skip
-			}
-		}
+	trades := p.state.GetRecentTrades(market.Ticker, 5*time.Minute)
+	if quantSig := ComputeQuantitativeSignals(market.Ticker, orderbook, trades, market.ExpirationTime, p.micropriceLevels); quantSig != nil {
+		p.state.GetTimeSeries().RecordQuant(market.Ticker, quantSig.ToPoint())
 
-		// Compute quantitative signals (always compute, even if not threshold-crossed)
-		trades := p.state.GetRecentTrades(market.Ticker, 5*time.Minute)
-		if quantSig := ComputeQuantitativeSignals(market.Ticker, orderbook, trades, market.ExpirationTime); quantSig != nil {
-			// Convert to regular signal for output
-			signal := &Signal{
-				MarketTicker: market.Ticker,
-				Type:         SignalTypeOrderbookImbalance, // Use as base type
-				Value:        quantSig.LiquidityScore,
-				Timestamp:    quantSig.Timestamp,
-				Metadata: SignalMetadata{
-					Confidence: quantSig.EfficiencyScore,
-				},
-			}
-			select {
-			case p.signalChan <- *signal:
-			default:
-			}
+		// Convert to regular signal for output
+		signal := &Signal{
+			MarketTicker: market.Ticker,
+			Type:         SignalTypeOrderbookImbalance, // Use as base type
+			Value:        quantSig.LiquidityScore,
+			Timestamp:    quantSig.Timestamp,
+			Metadata: SignalMetadata{
+				Confidence: quantSig.EfficiencyScore,
+			},
 		}
+		p.emit(*signal)
 	}
 }
 
+// emit sends a signal without blocking and records it in the audit log,
+// including signals dropped because the channel was full
+func (p *Processor) emit(signal Signal) {
+	if signal.Metadata.Severity == "" {
+		signal.Metadata.Severity = SeverityFromConfidence(signal.Metadata.Confidence)
+	}
+	signal.Score = p.score(signal)
+	signal.ID = generateSignalID(signal)
+
+	// Keep a per-market history of triggered signals so alerts can reference
+	// the signals preceding them
+	if signal.Metadata.ThresholdCrossed {
+		p.state.GetTimeSeries().RecordSignal(signal.MarketTicker, string(signal.Type), signal.Value, map[string]interface{}{
+			"id":         signal.ID,
+			"confidence": signal.Metadata.Confidence,
+			"severity":   string(signal.Metadata.Severity),
+		})
+	}
+
+	select {
+	case p.signalChan <- signal:
+		p.auditLog.Write("signal", signal)
+	default:
+		// Channel full, skip but keep the record
+		p.auditLog.Write("signal_dropped", signal)
+	}
+}
+
+// generateSignalID stamps IDs in UTC at microsecond resolution, so signals of
+// one type for one market emitted in the same sweep stay distinct
+func generateSignalID(signal Signal) string {
+	return signal.MarketTicker + "_" + string(signal.Type) + "_" + signal.Timestamp.UTC().Format("20060102150405.000000")
+}
+
 func (p *Processor) computeOrderbookImbalance(ticker string, orderbook *state.Orderbook) *Signal {
 	imbalanceRatio := orderbook.ImbalanceRatio()
 	spread, hasSpread := orderbook.Spread()
@@ -112,7 +279,7 @@ func (p *Processor) computeOrderbookImbalance(ticker string, orderbook *state.Or
 			MarketTicker: ticker,
 			Type: SignalTypeOrderbookImbalance,
 			Value: imbalanceRatio,
-			Timestamp: time.Now(),
+			Timestamp: time.Now().UTC(),
 			Metadata: SignalMetadata{
 				ThresholdCrossed: true,
 				Confidence:       min(abs(imbalanceRatio)/p.config.ImbalanceThreshold, 1.0),
@@ -132,35 +299,20 @@ func (p *Processor) computeImpliedProbabilityDrift(ticker string, orderbook *sta
 		return nil
 	}
 
-	bestBid := float64(orderbook.Bids[0].Price) / 100.0
-	bestAsk := float64(orderbook.Asks[0].Price) / 100.0
-	currentProb := (bestBid + bestAsk) / 2.0
-
-	// Get recent trades
-	window := time.Duration(p.config.DriftWindowSecs) * time.Second
-	trades := p.state.GetRecentTrades(ticker, window)
-
-	if len(trades) == 0 {
+	// Measured with the same reference price policy as the baseline samples
+	currentProb, ok := p.state.ReferencePrice(ticker, orderbook)
+	if !ok {
 		return nil
 	}
 
-	// Compute average probability from trades
-	var sumProb float64
-	for _, trade := range trades {
-		sumProb += float64(trade.Price) / 100.0
-	}
-	avgProb := sumProb / float64(len(trades))
-
-	// Compute standard deviation
-	var variance float64
-	for _, trade := range trades {
-		prob := float64(trade.Price) / 100.0
-		variance += (prob - avgProb) * (prob - avgProb)
+	baseline, ok := p.driftBaseline(ticker)
+	if !ok {
+		return nil
 	}
-	variance /= float64(len(trades))
-	stdDev := sqrt(variance)
+	avgProb, stdDev := baseline.Mean, baseline.StdDev
 
-	if stdDev == 0 {
+	// A flat history (e.g. a market pinned at 0 or 100) has no meaningful z-score
+	if stdDev < state.MinStdDev {
 		return nil
 	}
 
@@ -172,11 +324,12 @@ func (p *Processor) computeImpliedProbabilityDrift(ticker string, orderbook *sta
 			MarketTicker: ticker,
 			Type: SignalTypeImpliedProbabilityDrift,
 			Value: drift,
-			Timestamp: time.Now(),
+			Timestamp: time.Now().UTC(),
 			Metadata: SignalMetadata{
 				PreviousValue:    &avgProb,
 				ThresholdCrossed: true,
 				Confidence:       min(abs(drift)/p.config.DriftThreshold, 1.0),
+				SampleSize:       baseline.Samples,
 			},
 			ImpliedProbabilityDrift: &ImpliedProbabilityDriftData{
 				Delta:      currentProb - avgProb,
@@ -188,33 +341,92 @@ func (p *Processor) computeImpliedProbabilityDrift(ticker string, orderbook *sta
 	return nil
 }
 
-func (p *Processor) detectVolumeSurge(ticker string) *Signal {
-	window := time.Duration(p.config.VolumeWindowSecs) * time.Second
-	recentTrades := p.state.GetRecentTrades(ticker, window)
+// minDriftSnapshots is the fewest snapshot mids a drift baseline is built from
+const minDriftSnapshots = 5
+
+// driftBaseline returns the distribution the current reference price is
+// compared against: the trade prices over the drift window, or with
+// DriftBaseline "snapshots" the rolling window of earlier snapshot reference
+// prices
+func (p *Processor) driftBaseline(ticker string) (state.MidBaseline, bool) {
+	if p.config.DriftBaseline == "snapshots" {
+		baseline, ok := p.state.GetTimeSeries().GetMidBaseline(ticker, time.Now())
+		if !ok || baseline.Samples < minDriftSnapshots {
+			return state.MidBaseline{}, false
+		}
+		return baseline, true
+	}
 
-	if len(recentTrades) == 0 {
-		return nil
+	window := time.Duration(p.config.DriftWindowSecs) * time.Second
+	trades := p.state.GetRecentTrades(ticker, window)
+	if len(trades) == 0 || len(trades) < p.config.MinTradeSamples {
+		return state.MidBaseline{}, false
 	}
 
-	var recentVolume int
-	for _, trade := range recentTrades {
-		recentVolume += trade.Quantity
+	// Compute average probability from trades
+	var sumProb float64
+	for _, trade := range trades {
+		sumProb += float64(trade.Price) / 100.0
 	}
+	avgProb := sumProb / float64(len(trades))
 
-	// Get baseline volume from longer window (5x the recent window)
-	baselineWindow := time.Duration(p.config.VolumeWindowSecs*5) * time.Second
-	baselineTrades := p.state.GetRecentTrades(ticker, baselineWindow)
+	// Compute standard deviation
+	var variance float64
+	for _, trade := range trades {
+		prob := float64(trade.Price) / 100.0
+		variance += (prob - avgProb) * (prob - avgProb)
+	}
+	variance /= float64(len(trades))
 
-	if len(baselineTrades) < 2 {
-		return nil
+	return state.MidBaseline{Mean: avgProb, StdDev: sqrt(variance), Samples: len(trades)}, true
+}
+
+func (p *Processor) detectVolumeSurge(ticker string) *Signal {
+	window := time.Duration(p.config.VolumeWindowSecs) * time.Second
+
+	// Get baseline volume from a longer window spanning several recent windows.
+	// Config load rejects multipliers below 2; unset uses the default.
+	multiplier := p.config.VolumeBaselineMultiplier
+	if multiplier == 0 {
+		multiplier = defaultVolumeBaselineMultiplier
+	}
+	baselineWindow := window * time.Duration(multiplier)
+
+	var recentVolume, buyVolume, sellVolume, baselineVolume int
+	var samples int
+	if baselineTrades := p.state.GetRecentTrades(ticker, baselineWindow); len(baselineTrades) > 0 {
+		recentSince := time.Now().Add(-window)
+		for _, trade := range baselineTrades {
+			baselineVolume += trade.Quantity
+			if trade.Timestamp.Before(recentSince) {
+				continue
+			}
+			recentVolume += trade.Quantity
+			switch trade.Side {
+			case state.SideYes:
+				buyVolume += trade.Quantity
+			case state.SideNo:
+				sellVolume += trade.Quantity
+			}
+		}
+		samples = len(baselineTrades)
+	} else {
+		// No trade prints: fall back to the volume the ticker channel reports,
+		// which carries no side
+		ts := p.state.GetTimeSeries()
+		now := time.Now()
+		recent, _ := ts.GetTickerVolume(ticker, now.Add(-window))
+		baseline, n := ts.GetTickerVolume(ticker, now.Add(-baselineWindow))
+		recentVolume, baselineVolume, samples = int(recent), int(baseline), n
 	}
 
-	var baselineVolume int
-	for _, trade := range baselineTrades {
-		baselineVolume += trade.Quantity
+	if recentVolume == 0 || samples < 2 || samples < p.config.MinTradeSamples {
+		return nil
 	}
 
-	baselineAvg := float64(baselineVolume) / 5.0
+	// Average volume per recent-window-sized bucket of the baseline
+	buckets := float64(baselineWindow) / float64(window)
+	baselineAvg := float64(baselineVolume) / buckets
 	if baselineAvg == 0 {
 		return nil
 	}
@@ -227,15 +439,19 @@ func (p *Processor) detectVolumeSurge(ticker string) *Signal {
 			MarketTicker: ticker,
 			Type: SignalTypeVolumeSurge,
 			Value: surgeRatio,
-			Timestamp: time.Now(),
+			Timestamp: time.Now().UTC(),
 			Metadata: SignalMetadata{
 				PreviousValue:    &baselineAvg,
 				ThresholdCrossed: true,
 				Confidence:       min(surgeRatio/p.config.VolumeSurgeThreshold, 1.0),
+				SampleSize:       samples,
 			},
 			VolumeSurge: &VolumeSurgeData{
 				VolumeMultiplier: surgeRatio,
 				WindowSecs:       p.config.VolumeWindowSecs,
+				BuyVolume:        buyVolume,
+				SellVolume:       sellVolume,
+				Direction:        flowDirection(buyVolume, sellVolume),
 			},
 		}
 	}
@@ -243,6 +459,81 @@ func (p *Processor) detectVolumeSurge(ticker string) *Signal {
 	return nil
 }
 
+// bookSides is a market's book state and per-side depth when last evaluated
+type bookSides struct {
+	state    state.BookState
+	bidDepth int64
+	askDepth int64
+}
+
+func (p *Processor) detectLiquidityWithdrawal(ticker string, orderbook *state.Orderbook) *Signal {
+	current := orderbook.State()
+	previous, seen := p.bookStates[ticker]
+	p.bookStates[ticker] = bookSides{state: current, bidDepth: orderbook.BidDepth(), askDepth: orderbook.AskDepth()}
+
+	if !seen || previous.state != state.BookTwoSided || current == state.BookTwoSided {
+		return nil
+	}
+
+	// The value is the depth that vanished with the emptied side(s)
+	var withdrawn int64
+	if current == state.BookAskOnly || current == state.BookEmpty {
+		withdrawn += previous.bidDepth
+	}
+	if current == state.BookBidOnly || current == state.BookEmpty {
+		withdrawn += previous.askDepth
+	}
+
+	return &Signal{
+		MarketTicker: ticker,
+		Type:         SignalTypeLiquidityWithdrawal,
+		Value:        float64(withdrawn),
+		Timestamp:    time.Now().UTC(),
+		Metadata: SignalMetadata{
+			ThresholdCrossed: true,
+			Confidence:       1.0,
+			Severity:         SeverityHigh,
+		},
+		LiquidityWithdrawal: &LiquidityWithdrawalData{
+			PreviousState:      string(previous.state),
+			CurrentState:       string(current),
+			WithdrawnContracts: withdrawn,
+		},
+	}
+}
+
+// detectQuoteFlicker flags a market whose best bid or ask changed faster than
+// the configured rate over the flicker window
+func (p *Processor) detectQuoteFlicker(ticker string) *Signal {
+	if p.config.FlickerThreshold <= 0 || p.config.FlickerWindowSecs <= 0 {
+		return nil
+	}
+
+	window := time.Duration(p.config.FlickerWindowSecs) * time.Second
+	now := time.Now().UTC()
+	changes := p.state.TopOfBookChanges(ticker, now.Add(-window))
+	rate := float64(changes) / window.Seconds()
+	if rate <= p.config.FlickerThreshold {
+		return nil
+	}
+
+	return &Signal{
+		MarketTicker: ticker,
+		Type:         SignalTypeQuoteFlicker,
+		Value:        rate,
+		Timestamp:    now,
+		Metadata: SignalMetadata{
+			ThresholdCrossed: true,
+			Confidence:       min(rate/p.config.FlickerThreshold, 1.0),
+			Severity:         SeverityHigh,
+		},
+		QuoteFlicker: &QuoteFlickerData{
+			Changes:    changes,
+			WindowSecs: p.config.FlickerWindowSecs,
+		},
+	}
+}
+
 // Helper functions
 func abs(x float64) float64 {
 	if x < 0 {
@@ -0,0 +1,691 @@
+package signals
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kalshi-signal-feed/internal/audit"
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestEmitWritesAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.NewLog(config.AuditConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Room for two signals; the third is dropped but still audited
+	signalChan := make(chan Signal, 2)
+	p := NewProcessor(state.NewEngine(), signalChan, config.SignalConfig{})
+	p.SetAuditLog(auditLog)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- auditLog.Run(ctx) }()
+
+	tickers := []string{"MKT-A", "MKT-B", "MKT-C"}
+	for i, ticker := range tickers {
+		p.emit(Signal{
+			MarketTicker: ticker,
+			Type:         SignalTypeOrderbookImbalance,
+			Value:        float64(i) / 10,
+			Timestamp:    time.Now(),
+		})
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	type auditLine struct {
+		Kind string `json:"kind"`
+		Data Signal `json:"data"`
+	}
+	var entries []auditLine
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditLine
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != len(tickers) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(tickers))
+	}
+	wantKinds := []string{"signal", "signal", "signal_dropped"}
+	for i, entry := range entries {
+		if entry.Kind != wantKinds[i] {
+			t.Errorf("line %d kind = %q, want %q", i, entry.Kind, wantKinds[i])
+		}
+		if entry.Data.MarketTicker != tickers[i] || entry.Data.Type != SignalTypeOrderbookImbalance {
+			t.Errorf("line %d = %s %s, want %s %s", i, entry.Data.MarketTicker, entry.Data.Type, tickers[i], SignalTypeOrderbookImbalance)
+		}
+	}
+}
+
+func TestDetectLiquidityWithdrawal(t *testing.T) {
+	level := []state.PriceLevel{{Price: 50, Quantity: 10}}
+	book := func(bids, asks []state.PriceLevel) *state.Orderbook {
+		ob := state.NewOrderbook("MKT")
+		ob.Bids = bids
+		ob.Asks = asks
+		return ob
+	}
+
+	tests := []struct {
+		name     string
+		from, to *state.Orderbook
+		fires    bool
+	}{
+		{"two-sided to bid only", book(level, level), book(level, nil), true},
+		{"two-sided to ask only", book(level, level), book(nil, level), true},
+		{"two-sided to empty", book(level, level), book(nil, nil), true},
+		{"two-sided stays two-sided", book(level, level), book(level, level), false},
+		{"one-sided to empty", book(level, nil), book(nil, nil), false},
+		{"empty to two-sided", book(nil, nil), book(level, level), false},
+	}
+	for _, tt := range tests {
+		p := NewProcessor(state.NewEngine(), make(chan Signal, 1), config.SignalConfig{})
+		if p.detectLiquidityWithdrawal("MKT", tt.from) != nil {
+			t.Fatalf("%s: fired on the first book seen", tt.name)
+		}
+		signal := p.detectLiquidityWithdrawal("MKT", tt.to)
+		if (signal != nil) != tt.fires {
+			t.Errorf("%s: fired = %v, want %v", tt.name, signal != nil, tt.fires)
+			continue
+		}
+		if signal != nil && signal.LiquidityWithdrawal.CurrentState != string(tt.to.State()) {
+			t.Errorf("%s: current state = %s, want %s", tt.name, signal.LiquidityWithdrawal.CurrentState, tt.to.State())
+		}
+	}
+}
+
+func TestStaggeredSlotsCoverEveryActiveMarket(t *testing.T) {
+	engine := state.NewEngine()
+	for i := 0; i < 50; i++ {
+		engine.RegisterMarket(&state.Market{Ticker: fmt.Sprintf("MKT-%02d", i), Status: state.StatusActive})
+	}
+	engine.RegisterMarket(&state.Market{Ticker: "CLOSED", Status: state.StatusClosed})
+
+	const slots = 7
+	p := NewProcessor(engine, make(chan Signal, 100), config.SignalConfig{StaggerSlots: slots})
+
+	// One interval: the markets are listed at the first sub-tick, then each
+	// sub-tick evaluates its slot
+	due := p.marketsBySlot(slots)
+	for slot := 0; slot < slots; slot++ {
+		p.computeSignals(due[slot])
+	}
+
+	for i := 0; i < 50; i++ {
+		ticker := fmt.Sprintf("MKT-%02d", i)
+		if _, evaluated := p.lastEvaluated[ticker]; !evaluated {
+			t.Errorf("%s was not evaluated within one interval", ticker)
+		}
+	}
+	if _, evaluated := p.lastEvaluated["CLOSED"]; evaluated {
+		t.Error("closed market was evaluated")
+	}
+
+	// Each market sits in exactly one slot
+	seen := make(map[string]int)
+	for slot, markets := range due {
+		for _, market := range markets {
+			if previous, dup := seen[market.Ticker]; dup {
+				t.Errorf("%s in slots %d and %d", market.Ticker, previous, slot)
+			}
+			seen[market.Ticker] = slot
+		}
+	}
+}
+
+func TestPreOpenMarketIsNotEvaluated(t *testing.T) {
+	engine := state.NewEngine()
+	open := time.Now().Add(time.Hour)
+	engine.RegisterMarket(&state.Market{Ticker: "PREOPEN", Status: state.StatusActive, OpenTime: &open})
+	engine.RegisterMarket(&state.Market{Ticker: "OPEN", Status: state.StatusActive})
+
+	p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{})
+	p.handleUpdate("PREOPEN")
+	p.handleUpdate("OPEN")
+	for _, markets := range p.marketsBySlot(1) {
+		p.computeSignals(markets)
+	}
+
+	if _, evaluated := p.lastEvaluated["PREOPEN"]; evaluated {
+		t.Error("pre-open market was evaluated")
+	}
+	if _, evaluated := p.lastEvaluated["OPEN"]; !evaluated {
+		t.Error("open market was not evaluated")
+	}
+}
+
+func TestQuantHistoryMatchesComputedValues(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{})
+
+	setBook := func(bid, ask int) {
+		ob := state.NewOrderbook("MKT")
+		ob.Bids = []state.PriceLevel{{Price: bid, Quantity: 300}, {Price: bid - 1, Quantity: 100}}
+		ob.Asks = []state.PriceLevel{{Price: ask, Quantity: 100}, {Price: ask + 1, Quantity: 200}}
+		ob.LastUpdate = time.Now()
+		engine.UpdateOrderbook("MKT", ob)
+	}
+
+	var want []state.QuantPoint
+	for _, book := range [][2]int{{40, 44}, {40, 44}, {45, 47}} {
+		setBook(book[0], book[1])
+		market, _ := engine.GetMarket("MKT")
+		p.computeQuant(market)
+
+		ob, _ := engine.GetOrderbook("MKT")
+		computed := ComputeQuantitativeSignals("MKT", ob, nil, nil, p.micropriceLevels).ToPoint()
+		if len(want) == 0 || !sameQuantValues(want[len(want)-1], computed) {
+			want = append(want, computed)
+		}
+	}
+
+	history := engine.GetTimeSeries().GetQuantHistory("MKT", time.Time{})
+	if len(want) != 2 || len(history) != len(want) {
+		t.Fatalf("recorded %d points, want 2 (the unchanged tick is skipped)", len(history))
+	}
+	for i := range want {
+		if !sameQuantValues(history[i], want[i]) {
+			t.Errorf("point %d = %+v, want %+v", i, history[i], want[i])
+		}
+	}
+}
+
+func sameQuantValues(a, b state.QuantPoint) bool {
+	a.Timestamp = b.Timestamp
+	return a == b
+}
+
+func TestBookUpdateTriggersImmediateEvaluation(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	output := make(chan Signal, 10)
+	// The periodic sweep is a minute away, so only the update path can evaluate
+	p := NewProcessor(engine, output, config.SignalConfig{
+		ComputationIntervalSecs: 60,
+		ImbalanceThreshold:      0.5,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 900}}
+	ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+	ob.LastUpdate = time.Now()
+	start := time.Now()
+	engine.UpdateOrderbook("MKT", ob)
+
+	select {
+	case signal := <-output:
+		if signal.Type != SignalTypeOrderbookImbalance || signal.MarketTicker != "MKT" {
+			t.Fatalf("got %s for %s, want an imbalance signal for MKT", signal.Type, signal.MarketTicker)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("evaluated after %v", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("book update was not evaluated before the next sweep")
+	}
+}
+
+func TestVolumeSurgeNonDefaultBaselineMultiplier(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	now := time.Now()
+	// 50 contracts in the recent 30s window, 50 more 100s ago
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 50, Side: state.SideYes, Timestamp: now.Add(-100 * time.Second)})
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 30, Side: state.SideYes, Timestamp: now.Add(-10 * time.Second)})
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 20, Side: state.SideNo, Timestamp: now.Add(-5 * time.Second)})
+
+	tests := []struct {
+		multiplier int
+		wantRatio  float64
+	}{
+		// 300s baseline: 100 contracts over 10 windows = 10 per window
+		{10, 5},
+		// 60s baseline misses the older trade: 50 contracts over 2 windows
+		{2, 2},
+	}
+	for _, tt := range tests {
+		p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+			VolumeWindowSecs:         30,
+			VolumeBaselineMultiplier: tt.multiplier,
+			VolumeSurgeThreshold:     1.5,
+		})
+		signal := p.detectVolumeSurge("MKT")
+		if signal == nil {
+			t.Fatalf("multiplier %d: no surge signal", tt.multiplier)
+		}
+		if math.Abs(signal.VolumeSurge.VolumeMultiplier-tt.wantRatio) > 1e-9 {
+			t.Errorf("multiplier %d: surge ratio = %.3f, want %.3f", tt.multiplier, signal.VolumeSurge.VolumeMultiplier, tt.wantRatio)
+		}
+	}
+}
+
+func TestMinTradeSamplesGatesDriftAndVolume(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 59, Quantity: 100}}
+	ob.Asks = []state.PriceLevel{{Price: 61, Quantity: 100}}
+	ob.LastUpdate = time.Now()
+	engine.UpdateOrderbook("MKT", ob)
+
+	// Five trades around 40¢, which the 60¢ mid drifts far from, the last a
+	// burst in the recent volume window
+	now := time.Now()
+	for i, price := range []int{40, 42, 38, 41} {
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: price, Quantity: 10, Side: state.SideYes, Timestamp: now.Add(-time.Duration(100-20*i) * time.Second)})
+	}
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 39, Quantity: 50, Side: state.SideYes, Timestamp: now.Add(-time.Second)})
+
+	for _, minSamples := range []int{5, 6} {
+		p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+			DriftBaseline:            "trades",
+			DriftWindowSecs:          300,
+			DriftThreshold:           2,
+			VolumeWindowSecs:         30,
+			VolumeBaselineMultiplier: 5,
+			VolumeSurgeThreshold:     1.5,
+			MinTradeSamples:          minSamples,
+		})
+		drift := p.computeImpliedProbabilityDrift("MKT", ob)
+		surge := p.detectVolumeSurge("MKT")
+
+		if minSamples > 5 {
+			if drift != nil || surge != nil {
+				t.Errorf("minimum %d with 5 trades: drift=%v surge=%v, want both suppressed", minSamples, drift != nil, surge != nil)
+			}
+			continue
+		}
+		if drift == nil || surge == nil {
+			t.Fatalf("minimum %d with 5 trades: drift=%v surge=%v, want both to fire", minSamples, drift != nil, surge != nil)
+		}
+		if drift.Metadata.SampleSize != 5 || surge.Metadata.SampleSize != 5 {
+			t.Errorf("sample sizes = %d, %d, want 5", drift.Metadata.SampleSize, surge.Metadata.SampleSize)
+		}
+	}
+}
+
+func TestSignalsSuppressedDuringWarmup(t *testing.T) {
+	engine := state.NewEngine()
+	engine.SetWarmup(3, 0)
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	output := make(chan Signal, 10)
+	p := NewProcessor(engine, output, config.SignalConfig{ImbalanceThreshold: 0.5})
+
+	// A lopsided book that raises an imbalance signal once warmed up
+	for poll := 1; poll <= 3; poll++ {
+		ob := state.NewOrderbook("MKT")
+		ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 900}}
+		ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+		ob.LastUpdate = time.Now()
+		engine.UpdateOrderbook("MKT", ob)
+
+		market, _ := engine.GetMarket("MKT")
+		p.evaluateMarket(market)
+
+		emitted := len(output)
+		if poll < 3 && emitted != 0 {
+			t.Fatalf("poll %d: %d signals emitted during warm-up", poll, emitted)
+		}
+		if poll == 3 && emitted == 0 {
+			t.Fatal("no signal once warmed up")
+		}
+	}
+}
+
+func TestEmittedSignalIDIsRecorded(t *testing.T) {
+	engine := state.NewEngine()
+	signalChan := make(chan Signal, 2)
+	p := NewProcessor(engine, signalChan, config.SignalConfig{})
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		p.emit(Signal{
+			MarketTicker: "MKT",
+			Type:         SignalTypeVolumeSurge,
+			Timestamp:    now.Add(time.Duration(i) * time.Microsecond),
+			Metadata:     SignalMetadata{ThresholdCrossed: true},
+		})
+	}
+	first, second := <-signalChan, <-signalChan
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("signal IDs %q and %q, want distinct non-empty IDs", first.ID, second.ID)
+	}
+
+	points := engine.GetTimeSeries().GetSignals("MKT", now.Add(-time.Minute))
+	if len(points) != 2 || points[0].Metadata["id"] != first.ID || points[1].Metadata["id"] != second.ID {
+		t.Errorf("recorded signals %+v, want IDs %s and %s", points, first.ID, second.ID)
+	}
+}
+
+func TestVolumeSurgeFromTickerChannelVolume(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	// No trade prints, only ticker-channel volume: 20 contracts 100s ago and
+	// 80 in the last 30s
+	now := time.Now()
+	ts := engine.GetTimeSeries()
+	ts.RecordVolume("MKT", now.Add(-100*time.Second), 20)
+	ts.RecordVolume("MKT", now.Add(-10*time.Second), 80)
+
+	p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+		VolumeWindowSecs:         30,
+		VolumeBaselineMultiplier: 10,
+		VolumeSurgeThreshold:     1.5,
+	})
+	signal := p.detectVolumeSurge("MKT")
+	if signal == nil {
+		t.Fatal("no surge signal from ticker-channel volume")
+	}
+	// 100 contracts over ten 30s windows = 10 per window; 80 recent
+	if math.Abs(signal.VolumeSurge.VolumeMultiplier-8) > 1e-9 {
+		t.Errorf("surge ratio = %.3f, want 8", signal.VolumeSurge.VolumeMultiplier)
+	}
+	if signal.VolumeSurge.BuyVolume != 0 || signal.VolumeSurge.SellVolume != 0 {
+		t.Errorf("buy/sell = %d/%d, want no side for ticker volume", signal.VolumeSurge.BuyVolume, signal.VolumeSurge.SellVolume)
+	}
+
+	// Trade prints take precedence over ticker volume
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 10, Side: state.SideYes, Timestamp: now.Add(-100 * time.Second)})
+	engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 10, Side: state.SideYes, Timestamp: now.Add(-5 * time.Second)})
+	signal = p.detectVolumeSurge("MKT")
+	if signal == nil || math.Abs(signal.VolumeSurge.VolumeMultiplier-5) > 1e-9 {
+		t.Errorf("with trades: got %+v, want a surge ratio of 5 from the trades alone", signal)
+	}
+}
+
+func TestVolumeSurgeDirectionFromOneSidedSpike(t *testing.T) {
+	tests := []struct {
+		name string
+		side state.TradeSide
+		want FlowDirection
+	}{
+		{"buy spike", state.SideYes, FlowBuy},
+		{"sell spike", state.SideNo, FlowSell},
+	}
+	for _, tt := range tests {
+		engine := state.NewEngine()
+		engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+		now := time.Now()
+		// A quiet, two-sided baseline, then a burst of one side in the last 30s
+		for i := 0; i < 4; i++ {
+			side := state.SideYes
+			if i%2 == 1 {
+				side = state.SideNo
+			}
+			engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 5, Side: side, Timestamp: now.Add(-time.Duration(60+i*40) * time.Second)})
+		}
+		for i := 0; i < 5; i++ {
+			engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 40, Side: tt.side, Timestamp: now.Add(-time.Duration(i+1) * time.Second)})
+		}
+		// One unsided print counts toward neither side
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 50, Quantity: 10, Timestamp: now.Add(-20 * time.Second)})
+
+		p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+			VolumeWindowSecs:         30,
+			VolumeBaselineMultiplier: 10,
+			VolumeSurgeThreshold:     1.5,
+		})
+		signal := p.detectVolumeSurge("MKT")
+		if signal == nil {
+			t.Fatalf("%s: no surge signal", tt.name)
+		}
+		data := signal.VolumeSurge
+		if data.Direction != tt.want {
+			t.Errorf("%s: direction = %q, want %q", tt.name, data.Direction, tt.want)
+		}
+		buy, sell := data.BuyVolume, data.SellVolume
+		if tt.side == state.SideNo {
+			buy, sell = sell, buy
+		}
+		if buy != 200 || sell != 0 {
+			t.Errorf("%s: buy/sell volume = %d/%d, want all 200 contracts on the spiking side", tt.name, data.BuyVolume, data.SellVolume)
+		}
+	}
+}
+
+func TestDriftTradeVsSnapshotBaseline(t *testing.T) {
+	engine := state.NewEngine()
+	engine.SetDriftWindow(10 * time.Minute)
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	book := func(bid, ask int) *state.Orderbook {
+		ob := state.NewOrderbook("MKT")
+		ob.Bids = []state.PriceLevel{{Price: bid, Quantity: 100}}
+		ob.Asks = []state.PriceLevel{{Price: ask, Quantity: 100}}
+		ob.LastUpdate = time.Now()
+		return ob
+	}
+
+	// Six earlier books whose mids alternate 49/51 (mean 50, sd 1), then the
+	// current book at 60
+	for i := 0; i < 6; i++ {
+		if i%2 == 0 {
+			engine.UpdateOrderbook("MKT", book(48, 50))
+		} else {
+			engine.UpdateOrderbook("MKT", book(50, 52))
+		}
+	}
+	current := book(59, 61)
+	engine.UpdateOrderbook("MKT", current)
+
+	newProcessor := func(baseline string) *Processor {
+		return NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+			DriftBaseline:   baseline,
+			DriftWindowSecs: 600,
+			DriftThreshold:  2,
+			MinTradeSamples: 10,
+		})
+	}
+
+	// No trades yet: only the snapshot baseline has anything to measure against
+	if signal := newProcessor("trades").computeImpliedProbabilityDrift("MKT", current); signal != nil {
+		t.Errorf("trade baseline with no trades fired: %+v", signal)
+	}
+	snapshot := newProcessor("snapshots").computeImpliedProbabilityDrift("MKT", current)
+	if snapshot == nil {
+		t.Fatal("snapshot baseline didn't fire")
+	}
+	// The current mid isn't part of its own baseline: (0.60 - 0.50) / 0.01
+	if math.Abs(snapshot.Value-10) > 1e-6 || snapshot.Metadata.SampleSize != 6 {
+		t.Errorf("snapshot drift = %.4f over %d samples, want 10 over the 6 earlier books", snapshot.Value, snapshot.Metadata.SampleSize)
+	}
+
+	// Ten trades alternating 55/57 (mean 56, sd 1)
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		price := 55 + 2*(i%2)
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: price, Quantity: 5, Timestamp: now.Add(-time.Duration(10-i) * time.Second)})
+	}
+
+	// Unset picks the trade baseline, same as "trades"
+	for _, baseline := range []string{"trades", ""} {
+		trade := newProcessor(baseline).computeImpliedProbabilityDrift("MKT", current)
+		if trade == nil {
+			t.Fatalf("baseline %q: trade drift didn't fire", baseline)
+		}
+		if math.Abs(trade.Value-4) > 1e-6 || trade.Metadata.SampleSize != 10 {
+			t.Errorf("baseline %q: drift = %.4f over %d samples, want 4 over 10 trades", baseline, trade.Value, trade.Metadata.SampleSize)
+		}
+	}
+}
+
+func TestReferencePricePolicyChangesDrift(t *testing.T) {
+	engine := state.NewEngine()
+	engine.RegisterMarket(&state.Market{Ticker: "MKT", Status: state.StatusActive})
+	// Mid 50¢, but 900 contracts bid against 100 offered: microprice 51.6¢
+	ob := state.NewOrderbook("MKT")
+	ob.Bids = []state.PriceLevel{{Price: 48, Quantity: 900}}
+	ob.Asks = []state.PriceLevel{{Price: 52, Quantity: 100}}
+	ob.LastUpdate = time.Now()
+	engine.UpdateOrderbook("MKT", ob)
+
+	// Trades alternating 49/51 (mean 50, sd 1), the last at 51
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		engine.AddTrade(&state.Trade{MarketTicker: "MKT", Price: 49 + 2*(i%2), Quantity: 5, Timestamp: now.Add(-time.Duration(10-i) * time.Second)})
+	}
+
+	p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{
+		DriftBaseline:   "trades",
+		DriftWindowSecs: 60,
+		DriftThreshold:  0.5,
+		MinTradeSamples: 10,
+	})
+
+	tests := []struct {
+		policy state.ReferencePrice
+		want   float64 // z-score; 0 means no signal
+	}{
+		{state.ReferenceMid, 0},
+		{state.ReferenceMicroprice, 1.6},
+		{state.ReferenceLastTrade, 1},
+	}
+	for _, tt := range tests {
+		engine.SetReferencePrice(tt.policy, 1)
+		signal := p.computeImpliedProbabilityDrift("MKT", ob)
+		if tt.want == 0 {
+			if signal != nil {
+				t.Errorf("%s: drift %.4f, want none at the baseline mean", tt.policy, signal.Value)
+			}
+			continue
+		}
+		if signal == nil {
+			t.Errorf("%s: no drift signal, want %.2f", tt.policy, tt.want)
+			continue
+		}
+		if math.Abs(signal.Value-tt.want) > 1e-6 {
+			t.Errorf("%s: drift = %.4f, want %.4f", tt.policy, signal.Value, tt.want)
+		}
+	}
+}
+
+func TestQuoteFlickerFiresOnRapidBookChanges(t *testing.T) {
+	engine := state.NewEngine()
+	for _, ticker := range []string{"FLICKER", "STEADY"} {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Title: ticker, Status: state.StatusActive})
+	}
+	update := func(ticker string, bid int) {
+		ob := state.NewOrderbook(ticker)
+		ob.Bids = []state.PriceLevel{{Price: bid, Quantity: 100}}
+		ob.Asks = []state.PriceLevel{{Price: 50, Quantity: 100}}
+		engine.UpdateOrderbook(ticker, ob)
+	}
+
+	// The best bid flips 45/46 thirty times: 30 changes in a 10s window is
+	// 3 per second, over the threshold of 2
+	for i := 0; i <= 30; i++ {
+		update("FLICKER", 45+i%2)
+	}
+	// Resending the same book and a single real move are not flicker
+	for i := 0; i < 30; i++ {
+		update("STEADY", 45)
+	}
+	update("STEADY", 46)
+
+	p := NewProcessor(engine, make(chan Signal, 10), config.SignalConfig{FlickerThreshold: 2, FlickerWindowSecs: 10})
+
+	signal := p.detectQuoteFlicker("FLICKER")
+	if signal == nil {
+		t.Fatal("no flicker signal for a book flipping 3 times a second")
+	}
+	if signal.Type != SignalTypeQuoteFlicker || signal.QuoteFlicker == nil || signal.QuoteFlicker.Changes != 30 {
+		t.Errorf("got %+v / %+v, want quote_flicker with 30 changes", signal, signal.QuoteFlicker)
+	}
+	if math.Abs(signal.Value-3) > 1e-9 {
+		t.Errorf("rate = %.2f/s, want 3", signal.Value)
+	}
+	if signal.Timestamp.Location() != time.UTC {
+		t.Errorf("flicker timestamp zone = %s, want UTC", signal.Timestamp.Location())
+	}
+
+	if signal := p.detectQuoteFlicker("STEADY"); signal != nil {
+		t.Errorf("steady book flagged: %+v", signal)
+	}
+}
+
+func TestQuantRunsOnItsOwnScheduleForActiveMarkets(t *testing.T) {
+	engine := state.NewEngine()
+	for _, ticker := range []string{"BUSY", "QUIET"} {
+		engine.RegisterMarket(&state.Market{Ticker: ticker, Status: state.StatusActive})
+		ob := state.NewOrderbook(ticker)
+		ob.Bids = []state.PriceLevel{{Price: 45, Quantity: 100}}
+		ob.Asks = []state.PriceLevel{{Price: 47, Quantity: 100}}
+		engine.UpdateOrderbook(ticker, ob)
+	}
+	// Only BUSY trades enough to clear the activity minimum
+	for i := 0; i < 5; i++ {
+		engine.AddTrade(&state.Trade{MarketTicker: "BUSY", Price: 46, Quantity: 10, Timestamp: time.Now()})
+	}
+
+	output := make(chan Signal, 100)
+	p := NewProcessor(engine, output, config.SignalConfig{
+		ComputationIntervalSecs: 1,
+		QuantIntervalSecs:       10,
+		QuantMinActivity:        0.1,
+		ImbalanceThreshold:      0.3,
+	})
+	if got := p.quantInterval(); got != 10*time.Second {
+		t.Errorf("quant interval = %s, want 10s", got)
+	}
+	if got := NewProcessor(engine, output, config.SignalConfig{}).quantInterval(); got != defaultQuantInterval {
+		t.Errorf("unset quant interval = %s, want %s", got, defaultQuantInterval)
+	}
+
+	// Quant output is the imbalance type without imbalance data
+	quantRuns := func() map[string]int {
+		runs := make(map[string]int)
+		for {
+			select {
+			case signal := <-output:
+				if signal.Type == SignalTypeOrderbookImbalance && signal.OrderbookImbalance == nil {
+					runs[signal.MarketTicker]++
+				}
+			default:
+				return runs
+			}
+		}
+	}
+
+	// The per-second signal pass no longer computes quant
+	markets := engine.GetAllMarkets()
+	for i := 0; i < 3; i++ {
+		p.computeSignals(markets)
+	}
+	if runs := quantRuns(); len(runs) != 0 {
+		t.Fatalf("signal pass ran quant %v, want none", runs)
+	}
+
+	// The quant pass covers only markets over the activity minimum
+	p.computeQuantSignals()
+	if runs := quantRuns(); runs["BUSY"] != 1 || runs["QUIET"] != 0 {
+		t.Errorf("quant pass ran %v, want once for BUSY only", runs)
+	}
+}
@@ -20,6 +20,7 @@ type QuantitativeSignal struct {
 	// Probability Calibration
 	CalibrationError   float64 `json:"calibration_error"`     // How well-calibrated the market is
 	ExpectedValue      float64 `json:"expected_value"`        // Current implied probability
+	Microprice         float64 `json:"microprice"`            // Size-weighted fair price, probability
 	HistoricalMean     float64 `json:"historical_mean"`       // Mean probability over window
 	
 	// Liquidity Metrics
@@ -38,15 +39,16 @@ type QuantitativeSignal struct {
 	TrendStrength      float64 `json:"trend_strength"`        // 0-1, strength of trend
 }
 
-// ComputeQuantitativeSignals computes advanced quantitative metrics
-func ComputeQuantitativeSignals(ticker string, orderbook *state.Orderbook, trades []*state.Trade, expirationTime *time.Time) *QuantitativeSignal {
+// ComputeQuantitativeSignals computes advanced quantitative metrics. The
+// microprice weighs micropriceLevels levels per side.
+func ComputeQuantitativeSignals(ticker string, orderbook *state.Orderbook, trades []*state.Trade, expirationTime *time.Time, micropriceLevels int) *QuantitativeSignal {
 	if orderbook == nil {
 		return nil
 	}
 
 	sig := &QuantitativeSignal{
 		MarketTicker: ticker,
-		Timestamp:    time.Now(),
+		Timestamp:    time.Now().UTC(),
 	}
 
 	// Compute basic price metrics
@@ -62,6 +64,7 @@ func ComputeQuantitativeSignals(ticker string, orderbook *state.Orderbook, trade
 	
 	sig.BidAskSpread = spread
 	sig.ExpectedValue = midPrice / 100.0 // Convert cents to probability
+	sig.Microprice, _ = orderbook.MicropriceDepth(micropriceLevels)
 	
 	// Market Depth
 	sig.MarketDepth = orderbook.BidDepth() + orderbook.AskDepth()
@@ -78,8 +81,8 @@ func ComputeQuantitativeSignals(ticker string, orderbook *state.Orderbook, trade
 	}
 	
 	// Market Efficiency (how tight is spread relative to volatility)
-	if sig.PriceVolatility > 0 {
-		sig.EfficiencyScore = math.Min(1.0, (spread/100.0) / sig.PriceVolatility)
+	if sig.PriceVolatility > state.MinStdDev {
+		sig.EfficiencyScore = state.Clamp((spread/100.0)/sig.PriceVolatility, 0, 1)
 	} else {
 		sig.EfficiencyScore = 1.0 // Perfect efficiency if no volatility
 	}
@@ -111,14 +114,50 @@ func ComputeQuantitativeSignals(ticker string, orderbook *state.Orderbook, trade
 	}
 	
 	// Sharpe-like ratio (return per unit of volatility)
-	if sig.PriceVolatility > 0 {
+	if sig.PriceVolatility > state.MinStdDev {
 		returnSignal := sig.ExpectedValue - sig.HistoricalMean
 		sig.SharpeRatio = returnSignal / sig.PriceVolatility
 	}
-	
+
+	sig.sanitize()
 	return sig
 }
 
+// sanitize clamps bounded metrics to their ranges and zeroes any NaN/Inf left
+// by a degenerate book or trade history (e.g. a market pinned at 0 or 100)
+func (q *QuantitativeSignal) sanitize() {
+	q.EfficiencyScore = state.Clamp(q.EfficiencyScore, 0, 1)
+	q.LiquidityScore = state.Clamp(q.LiquidityScore, 0, 1)
+	q.TrendStrength = state.Clamp(q.TrendStrength, 0, 1)
+	q.ExpectedValue = state.Clamp(q.ExpectedValue, 0, 1)
+	q.Microprice = state.Clamp(q.Microprice, 0, 1)
+	q.HistoricalMean = state.Clamp(q.HistoricalMean, 0, 1)
+	q.CalibrationError = state.Clamp(q.CalibrationError, 0, 1)
+
+	q.PriceVolatility = state.Finite(q.PriceVolatility)
+	q.InformationFlow = state.Finite(q.InformationFlow)
+	q.BidAskSpread = state.Finite(q.BidAskSpread)
+	q.TimeToEvent = state.Finite(q.TimeToEvent)
+	q.EventVolatility = state.Finite(q.EventVolatility)
+	q.SharpeRatio = state.Finite(q.SharpeRatio)
+	q.ZScore = state.Finite(q.ZScore)
+}
+
+// ToPoint extracts the chartable metrics for time-series storage
+func (q *QuantitativeSignal) ToPoint() state.QuantPoint {
+	return state.QuantPoint{
+		Timestamp:        q.Timestamp,
+		EfficiencyScore:  q.EfficiencyScore,
+		LiquidityScore:   q.LiquidityScore,
+		PriceVolatility:  q.PriceVolatility,
+		ExpectedValue:    q.ExpectedValue,
+		CalibrationError: q.CalibrationError,
+		SharpeRatio:      q.SharpeRatio,
+		ZScore:           q.ZScore,
+		TrendStrength:    q.TrendStrength,
+	}
+}
+
 // Helper functions
 func getBestBid(ob *state.Orderbook) (float64, bool) {
 	if len(ob.Bids) == 0 {
@@ -182,7 +221,7 @@ func computeMean(trades []*state.Trade) float64 {
 }
 
 func computeZScore(current, mean, stdDev float64) float64 {
-	if stdDev == 0 {
+	if stdDev < state.MinStdDev {
 		return 0
 	}
 	return (current - mean) / stdDev
@@ -206,7 +245,11 @@ func computeTrendStrength(trades []*state.Trade) float64 {
 		sumX2 += x * x
 	}
 	
-	slope := (n*sumXY - sumX*sumY) / (n*sumX2 - sumX*sumX)
+	denom := n*sumX2 - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
 	
 	// Normalize to 0-1 (assume max slope of 0.1 per trade is strong trend)
 	return math.Min(1.0, math.Abs(slope)*10.0)
@@ -0,0 +1,40 @@
+package signals
+
+import "math"
+
+// score normalizes a signal's value to 0-1 so signals of different types can be
+// ranked together. Each type's value is taken as a multiple of its threshold;
+// crossing the threshold scores 1/ScoreSaturationRatio and the score reaches 1
+// at ScoreSaturationRatio times the threshold. Liquidity withdrawal, which has
+// no threshold to cross, is scored by the contracts withdrawn against
+// WithdrawalDepthThreshold. Signals that didn't cross a threshold score 0.
+func (p *Processor) score(signal Signal) float64 {
+	if !signal.Metadata.ThresholdCrossed {
+		return 0
+	}
+
+	var threshold float64
+	switch signal.Type {
+	case SignalTypeImpliedProbabilityDrift:
+		threshold = p.config.DriftThreshold
+	case SignalTypeOrderbookImbalance:
+		threshold = p.config.ImbalanceThreshold
+	case SignalTypeVolumeSurge:
+		threshold = p.config.VolumeSurgeThreshold
+	case SignalTypeQuoteFlicker:
+		threshold = p.config.FlickerThreshold
+	case SignalTypeLiquidityWithdrawal:
+		threshold = float64(p.config.WithdrawalDepthThreshold)
+	default:
+		return 0
+	}
+	if threshold <= 0 {
+		return 1
+	}
+
+	saturation := p.config.ScoreSaturationRatio
+	if saturation < 1 {
+		saturation = 1
+	}
+	return math.Min(math.Abs(signal.Value)/threshold/saturation, 1)
+}
@@ -0,0 +1,78 @@
+package signals
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kalshi-signal-feed/internal/config"
+	"github.com/kalshi-signal-feed/internal/state"
+)
+
+func TestScoreComparableAcrossTypes(t *testing.T) {
+	cfg := config.SignalConfig{
+		DriftThreshold:           2,
+		ImbalanceThreshold:       0.3,
+		VolumeSurgeThreshold:     3,
+		FlickerThreshold:         2,
+		WithdrawalDepthThreshold: 500,
+		ScoreSaturationRatio:     3,
+	}
+	p := NewProcessor(state.NewEngine(), make(chan Signal, 1), cfg)
+
+	// Each type at its threshold scores 1/3, at twice it 2/3, and saturates at 1
+	thresholds := map[SignalType]float64{
+		SignalTypeImpliedProbabilityDrift: cfg.DriftThreshold,
+		SignalTypeOrderbookImbalance:      cfg.ImbalanceThreshold,
+		SignalTypeVolumeSurge:             cfg.VolumeSurgeThreshold,
+		SignalTypeQuoteFlicker:            cfg.FlickerThreshold,
+		SignalTypeLiquidityWithdrawal:     float64(cfg.WithdrawalDepthThreshold),
+	}
+	for signalType, threshold := range thresholds {
+		for _, tt := range []struct {
+			multiple float64
+			want     float64
+		}{{1, 1.0 / 3}, {2, 2.0 / 3}, {3, 1}, {10, 1}} {
+			signal := Signal{Type: signalType, Value: threshold * tt.multiple, Metadata: SignalMetadata{ThresholdCrossed: true}}
+			if got := p.score(signal); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%s at %gx threshold: score = %.3f, want %.3f", signalType, tt.multiple, got, tt.want)
+			}
+		}
+		if got := p.score(Signal{Type: signalType, Value: threshold * 5}); got != 0 {
+			t.Errorf("%s not crossed: score = %.3f, want 0", signalType, got)
+		}
+	}
+
+	// Negative drift and sell-side imbalance score by magnitude
+	if got := p.score(Signal{Type: SignalTypeImpliedProbabilityDrift, Value: -4, Metadata: SignalMetadata{ThresholdCrossed: true}}); math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("drift -4: score = %.3f, want 0.667", got)
+	}
+
+	// Withdrawals are sized by the depth that vanished: a thin book's last
+	// 10 contracts rank below a 3x volume surge, a 1500-contract pull above it
+	book := func(bidQty, askQty int) *state.Orderbook {
+		ob := state.NewOrderbook("MKT")
+		if bidQty > 0 {
+			ob.Bids = []state.PriceLevel{{Price: 45, Quantity: bidQty}}
+		}
+		if askQty > 0 {
+			ob.Asks = []state.PriceLevel{{Price: 47, Quantity: askQty}}
+		}
+		return ob
+	}
+	withdrawalScore := func(bidQty, askQty int, to *state.Orderbook) float64 {
+		p.detectLiquidityWithdrawal("MKT", book(bidQty, askQty))
+		signal := p.detectLiquidityWithdrawal("MKT", to)
+		if signal == nil {
+			t.Fatal("no withdrawal signal")
+		}
+		return p.score(*signal)
+	}
+	surge := p.score(Signal{Type: SignalTypeVolumeSurge, Value: 3, Metadata: SignalMetadata{ThresholdCrossed: true}})
+
+	if thin := withdrawalScore(10, 10, book(10, 0)); thin >= surge {
+		t.Errorf("10-contract withdrawal scores %.3f, want below the threshold surge's %.3f", thin, surge)
+	}
+	if deep := withdrawalScore(1000, 500, book(0, 0)); deep != 1 {
+		t.Errorf("1500-contract withdrawal scores %.3f, want 1", deep)
+	}
+}
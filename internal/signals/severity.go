@@ -0,0 +1,39 @@
+package signals
+
+// Severity ranks how urgently a signal should reach a human
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// AtLeast reports whether s is at or above min. Unknown severities rank as info.
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRank[s] >= severityRank[min]
+}
+
+// SeverityFromConfidence maps a 0-1 confidence onto a severity level
+func SeverityFromConfidence(confidence float64) Severity {
+	switch {
+	case confidence >= 0.95:
+		return SeverityHigh
+	case confidence >= 0.75:
+		return SeverityMedium
+	case confidence >= 0.5:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
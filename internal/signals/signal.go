@@ -8,12 +8,16 @@ const (
 	SignalTypeImpliedProbabilityDrift SignalType = "implied_probability_drift"
 	SignalTypeOrderbookImbalance      SignalType = "orderbook_imbalance"
 	SignalTypeVolumeSurge             SignalType = "volume_surge"
+	SignalTypeLiquidityWithdrawal     SignalType = "liquidity_withdrawal"
+	SignalTypeQuoteFlicker            SignalType = "quote_flicker"
 )
 
 type Signal struct {
+	ID           string    `json:"id"` // unique per emitted signal; alerts reference it
 	MarketTicker string    `json:"market_ticker"`
 	Type         SignalType `json:"type"`
 	Value        float64   `json:"value"`
+	Score        float64   `json:"score"` // Value normalized to 0-1 per type, comparable across types
 	Timestamp    time.Time `json:"timestamp"`
 	Metadata     SignalMetadata `json:"metadata"`
 
@@ -21,12 +25,16 @@ type Signal struct {
 	ImpliedProbabilityDrift *ImpliedProbabilityDriftData `json:"implied_probability_drift,omitempty"`
 	OrderbookImbalance      *OrderbookImbalanceData      `json:"orderbook_imbalance,omitempty"`
 	VolumeSurge             *VolumeSurgeData             `json:"volume_surge,omitempty"`
+	LiquidityWithdrawal     *LiquidityWithdrawalData     `json:"liquidity_withdrawal,omitempty"`
+	QuoteFlicker            *QuoteFlickerData            `json:"quote_flicker,omitempty"`
 }
 
 type SignalMetadata struct {
 	PreviousValue    *float64 `json:"previous_value,omitempty"`
 	ThresholdCrossed bool     `json:"threshold_crossed"`
 	Confidence       float64  `json:"confidence"` // 0.0 to 1.0
+	Severity         Severity `json:"severity"`   // derived from confidence unless set explicitly
+	SampleSize       int      `json:"sample_size,omitempty"` // trades the signal was computed from
 }
 
 type ImpliedProbabilityDriftData struct {
@@ -42,5 +50,54 @@ type OrderbookImbalanceData struct {
 type VolumeSurgeData struct {
 	VolumeMultiplier float64 `json:"volume_multiplier"`
 	WindowSecs       int     `json:"window_secs"`
+
+	// Recent volume split by taker side: buys lift YES, sells take NO.
+	// Trades of unknown side count toward neither.
+	BuyVolume  int           `json:"buy_volume"`
+	SellVolume int           `json:"sell_volume"`
+	Direction  FlowDirection `json:"direction"`
+}
+
+// FlowDirection is which side of YES the aggressive volume in a surge is on
+type FlowDirection string
+
+const (
+	FlowBuy   FlowDirection = "buy"
+	FlowSell  FlowDirection = "sell"
+	FlowMixed FlowDirection = "mixed"
+)
+
+// flowDirectionShare is the net share of sided volume, (buy - sell) / (buy +
+// sell), beyond which a surge counts as one-directional
+const flowDirectionShare = 1.0 / 3.0
+
+// flowDirection classifies buy and sell volume as a buy, sell or mixed surge
+func flowDirection(buy, sell int) FlowDirection {
+	total := buy + sell
+	if total == 0 {
+		return FlowMixed
+	}
+	net := float64(buy-sell) / float64(total)
+	switch {
+	case net >= flowDirectionShare:
+		return FlowBuy
+	case net <= -flowDirectionShare:
+		return FlowSell
+	default:
+		return FlowMixed
+	}
+}
+
+
+type LiquidityWithdrawalData struct {
+	PreviousState      string `json:"previous_state"`
+	CurrentState       string `json:"current_state"`
+	WithdrawnContracts int64  `json:"withdrawn_contracts"` // depth of the side(s) that emptied; also the signal's value
 }
 
+// QuoteFlickerData describes top-of-book churn; the signal's value is the
+// change rate per second
+type QuoteFlickerData struct {
+	Changes    int `json:"changes"` // top-of-book changes in the window
+	WindowSecs int `json:"window_secs"`
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"math"
+	"time"
+)
+
+// defaultActivityHalfLife is used when no half-life is configured
+const defaultActivityHalfLife = time.Minute
+
+// activityHalfLives is how many half-lives of trades feed the activity score;
+// anything older would contribute under 1% of a fresh trade
+const activityHalfLives = 7
+
+// ActivityScore is a market's exponentially decayed trade rate as of now, in
+// trades per minute. Each trade counts 0.5^(age/halfLife), so a burst a minute
+// ago scores below the same burst just now. The sum is scaled so a steady
+// rate of r trades per minute scores r, keeping the score comparable with the
+// flat trade intensity. A non-positive halfLife uses the default.
+func ActivityScore(trades []*Trade, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		halfLife = defaultActivityHalfLife
+	}
+
+	var weight float64
+	for _, trade := range trades {
+		age := max(now.Sub(trade.Timestamp), 0)
+		weight += math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+	return weight * math.Ln2 / halfLife.Minutes()
+}
+
+// SetActivityHalfLife sets the half-life of trades in ActivityScore
+func (e *Engine) SetActivityHalfLife(halfLife time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.activityHalfLife = halfLife
+}
+
+// ActivityScore returns the market's decayed trade rate (trades per minute)
+// as of now under the configured half-life
+func (e *Engine) ActivityScore(ticker string, now time.Time) float64 {
+	e.mu.RLock()
+	halfLife := e.activityHalfLife
+	e.mu.RUnlock()
+	if halfLife <= 0 {
+		halfLife = defaultActivityHalfLife
+	}
+
+	trades := e.GetTradesSince(ticker, now.Add(-activityHalfLives*halfLife))
+	return ActivityScore(trades, now, halfLife)
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestActivityScoreFavorsRecentTrades(t *testing.T) {
+	e := NewEngine()
+	e.SetActivityHalfLife(10 * time.Second)
+	now := time.Now()
+
+	// Five trades each inside the same 30s window: a burst 29s ago on STALE,
+	// and the same burst 1s ago on FRESH
+	for i := 0; i < 5; i++ {
+		e.AddTrade(&Trade{MarketTicker: "STALE", Price: 50, Quantity: 10, Timestamp: now.Add(-29 * time.Second)})
+	}
+	for i := 0; i < 5; i++ {
+		e.AddTrade(&Trade{MarketTicker: "FRESH", Price: 50, Quantity: 10, Timestamp: now.Add(-time.Second)})
+	}
+
+	window := now.Add(-30 * time.Second)
+	if stale, fresh := len(e.GetTradesSince("STALE", window)), len(e.GetTradesSince("FRESH", window)); stale != fresh {
+		t.Fatalf("trade counts %d / %d, want equal flat intensity", stale, fresh)
+	}
+
+	stale, fresh := e.ActivityScore("STALE", now), e.ActivityScore("FRESH", now)
+	if fresh <= stale {
+		t.Fatalf("fresh score %.3f <= stale score %.3f", fresh, stale)
+	}
+	// 28s apart is 2.8 half-lives
+	if ratio, want := fresh/stale, math.Pow(2, 2.8); math.Abs(ratio-want) > 1e-6 {
+		t.Errorf("fresh/stale = %.4f, want %.4f", ratio, want)
+	}
+}
+
+func TestActivityScoreMatchesSteadyRate(t *testing.T) {
+	now := time.Now()
+	halfLife := time.Minute
+
+	// Two trades a minute for an hour should score about 2 trades per minute.
+	// Trades sit mid-slot (15s, 45s, ... ago) so each stands for its 30s.
+	var trades []*Trade
+	for age := 15 * time.Second; age < time.Hour; age += 30 * time.Second {
+		trades = append(trades, &Trade{Timestamp: now.Add(-age)})
+	}
+	if score := ActivityScore(trades, now, halfLife); math.Abs(score-2) > 0.1 {
+		t.Errorf("steady 2/min scored %.3f, want about 2", score)
+	}
+	if score := ActivityScore(nil, now, halfLife); score != 0 {
+		t.Errorf("no trades scored %.3f, want 0", score)
+	}
+}
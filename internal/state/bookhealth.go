@@ -0,0 +1,82 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// Reasons a market appears in the book-gap report
+const (
+	BookGapNeverFetched = "never_fetched"
+	BookGapEmpty        = "empty"
+	BookGapStale        = "stale"
+)
+
+// BookGap describes an active market whose orderbook can't feed signals: no
+// book has ever been stored for it, the stored book has no levels, or it
+// hasn't been updated within the allowed age
+type BookGap struct {
+	Ticker      string     `json:"ticker"`
+	EventTicker string     `json:"event_ticker"`
+	Title       string     `json:"title"`
+	Reason      string     `json:"reason"`
+	BookState   BookState  `json:"book_state"`
+	LastUpdate  *time.Time `json:"last_update,omitempty"` // last successful book update; unset if never fetched
+	AgeSecs     *float64   `json:"age_secs,omitempty"`
+}
+
+// GetBookGaps lists active markets whose book was never fetched, is empty, or
+// is older than maxAge as of now, sorted by ticker
+func (e *Engine) GetBookGaps(now time.Time, maxAge time.Duration) []BookGap {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	gaps := make([]BookGap, 0)
+	for ticker, market := range e.markets {
+		if market.Status != StatusActive {
+			continue
+		}
+
+		gap := BookGap{
+			Ticker:      ticker,
+			EventTicker: market.EventTicker,
+			Title:       market.Title,
+			BookState:   BookEmpty,
+		}
+
+		ob, exists := e.orderbooks[ticker]
+		if !exists || !e.booksFetched[ticker] {
+			gap.Reason = BookGapNeverFetched
+			gaps = append(gaps, gap)
+			continue
+		}
+
+		lastUpdate := ob.LastUpdate
+		age := now.Sub(lastUpdate).Seconds()
+		gap.BookState = ob.State()
+		gap.LastUpdate = &lastUpdate
+		gap.AgeSecs = &age
+
+		switch {
+		case gap.BookState == BookEmpty:
+			gap.Reason = BookGapEmpty
+		case now.Sub(lastUpdate) > maxAge:
+			gap.Reason = BookGapStale
+		default:
+			continue
+		}
+		gaps = append(gaps, gap)
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i].Ticker < gaps[j].Ticker
+	})
+	return gaps
+}
+
+// BookFetched reports whether a fetched book has ever been stored for ticker
+func (e *Engine) BookFetched(ticker string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.booksFetched[ticker]
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGetBookGaps(t *testing.T) {
+	e := NewEngine()
+	now := time.Now()
+	book := func(ticker string, age time.Duration, bids, asks []PriceLevel) {
+		e.RegisterMarket(&Market{Ticker: ticker, Title: ticker, Status: StatusActive, EventTicker: "EV"})
+		ob := NewOrderbook(ticker)
+		ob.Bids = bids
+		ob.Asks = asks
+		ob.LastUpdate = now.Add(-age)
+		e.UpdateOrderbook(ticker, ob)
+	}
+	bids := []PriceLevel{{Price: 45, Quantity: 100}}
+	asks := []PriceLevel{{Price: 47, Quantity: 100}}
+
+	book("POPULATED", 10*time.Second, bids, asks)
+	book("BID-ONLY", 10*time.Second, bids, nil) // one-sided still feeds some signals
+	book("EMPTY", 0, nil, nil)
+	book("STALE", 10*time.Minute, bids, asks)
+	e.RegisterMarket(&Market{Ticker: "NEVER", Status: StatusActive})
+	e.RegisterMarket(&Market{Ticker: "CLOSED", Status: StatusClosed})
+
+	gaps := e.GetBookGaps(now, 3*time.Minute)
+	want := []struct {
+		ticker string
+		reason string
+		age    float64 // -1 when unset
+	}{
+		{"EMPTY", BookGapEmpty, 0},
+		{"NEVER", BookGapNeverFetched, -1},
+		{"STALE", BookGapStale, 600},
+	}
+	if len(gaps) != len(want) {
+		t.Fatalf("gaps = %+v, want %d", gaps, len(want))
+	}
+	for i, w := range want {
+		gap := gaps[i]
+		if gap.Ticker != w.ticker || gap.Reason != w.reason {
+			t.Errorf("gap %d = %s (%s), want %s (%s)", i, gap.Ticker, gap.Reason, w.ticker, w.reason)
+			continue
+		}
+		if w.age < 0 {
+			if gap.LastUpdate != nil || gap.AgeSecs != nil {
+				t.Errorf("%s: last update %v age %v, want both unset", gap.Ticker, gap.LastUpdate, gap.AgeSecs)
+			}
+			continue
+		}
+		if gap.AgeSecs == nil || math.Abs(*gap.AgeSecs-w.age) > 0.1 || gap.LastUpdate == nil {
+			t.Errorf("%s: age = %v, want %.0fs", gap.Ticker, derefAge(gap.AgeSecs), w.age)
+		}
+	}
+
+	// A longer allowance clears the stale book
+	if gaps := e.GetBookGaps(now, 15*time.Minute); len(gaps) != 2 {
+		t.Errorf("with a 15m allowance: %+v, want only EMPTY and NEVER", gaps)
+	}
+}
+
+func derefAge(age *float64) interface{} {
+	if age == nil {
+		return nil
+	}
+	return *age
+}
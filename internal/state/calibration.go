@@ -0,0 +1,145 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// CalibrationHorizon is how long before close a resolved market is priced for
+// calibration. By close the price has already converged on the outcome, which
+// would make every market look perfectly calibrated.
+const CalibrationHorizon = 24 * time.Hour
+
+// Resolution is a settled market's outcome together with the price it traded
+// at a calibration horizon before it closed
+type Resolution struct {
+	Ticker      string    `json:"ticker"`
+	ResolvedYes bool      `json:"resolved_yes"`
+	Price       float64   `json:"price"`     // mid at priced_at, probability (0-1)
+	PricedAt    time.Time `json:"priced_at"` // CalibrationHorizon before close
+	ResolvedAt  time.Time `json:"resolved_at"`
+}
+
+// CalibrationBucket is one price range of a calibration report
+type CalibrationBucket struct {
+	Lower       float64 `json:"lower"` // probability, inclusive
+	Upper       float64 `json:"upper"` // probability, exclusive (inclusive for the top bucket)
+	Count       int     `json:"count"`
+	MeanPrice   float64 `json:"mean_price"`
+	ObservedYes float64 `json:"observed_yes"` // fraction of markets in the bucket that resolved YES
+	Error       float64 `json:"error"`        // observed_yes - mean_price; positive means YES was underpriced
+}
+
+// CalibrationReport compares resolved markets' prices with how often they
+// actually resolved YES
+type CalibrationReport struct {
+	Markets    int                 `json:"markets"`
+	BrierScore float64             `json:"brier_score"` // mean squared error of price vs outcome
+	Buckets    []CalibrationBucket `json:"buckets"`
+}
+
+// RecordResolution stores a market's outcome, priced at its mid
+// CalibrationHorizon before it closed (its close time if known, otherwise
+// resolvedAt). It reports false when there is no recorded mid from that far
+// back, e.g. the market was listed less than a horizon before closing.
+func (e *Engine) RecordResolution(ticker string, resolvedYes bool, resolvedAt time.Time) bool {
+	closedAt := resolvedAt
+	if market, ok := e.GetMarket(ticker); ok && market.CloseTime != nil && market.CloseTime.Before(closedAt) {
+		closedAt = *market.CloseTime
+	}
+	pricedAt := closedAt.Add(-CalibrationHorizon)
+
+	var price float64
+	priced := false
+	for _, point := range e.timeSeries.GetMidHistory(ticker, time.Time{}) {
+		if point.Timestamp.After(pricedAt) {
+			break
+		}
+		price, priced = point.Mid, true
+	}
+	if !priced {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resolutions[ticker] = Resolution{
+		Ticker:      ticker,
+		ResolvedYes: resolvedYes,
+		Price:       price,
+		PricedAt:    pricedAt,
+		ResolvedAt:  resolvedAt,
+	}
+	return true
+}
+
+// GetResolutions returns every recorded resolution, oldest first
+func (e *Engine) GetResolutions() []Resolution {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	resolutions := make([]Resolution, 0, len(e.resolutions))
+	for _, r := range e.resolutions {
+		resolutions = append(resolutions, r)
+	}
+	sort.Slice(resolutions, func(i, j int) bool {
+		return resolutions[i].ResolvedAt.Before(resolutions[j].ResolvedAt)
+	})
+	return resolutions
+}
+
+// Calibrate buckets resolutions into equal-width price ranges and reports the
+// observed YES frequency in each. Empty buckets are included so the report
+// always covers 0-1.
+func Calibrate(resolutions []Resolution, buckets int) CalibrationReport {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	report := CalibrationReport{
+		Markets: len(resolutions),
+		Buckets: make([]CalibrationBucket, buckets),
+	}
+	yesCounts := make([]int, buckets)
+	priceSums := make([]float64, buckets)
+
+	for i := range report.Buckets {
+		report.Buckets[i].Lower = float64(i) / float64(buckets)
+		report.Buckets[i].Upper = float64(i+1) / float64(buckets)
+	}
+
+	var squaredError float64
+	for _, r := range resolutions {
+		i := int(r.Price * float64(buckets))
+		if i >= buckets {
+			i = buckets - 1
+		}
+		if i < 0 {
+			i = 0
+		}
+
+		outcome := 0.0
+		if r.ResolvedYes {
+			outcome = 1.0
+			yesCounts[i]++
+		}
+		report.Buckets[i].Count++
+		priceSums[i] += r.Price
+		squaredError += (r.Price - outcome) * (r.Price - outcome)
+	}
+
+	for i := range report.Buckets {
+		b := &report.Buckets[i]
+		if b.Count == 0 {
+			continue
+		}
+		b.MeanPrice = priceSums[i] / float64(b.Count)
+		b.ObservedYes = float64(yesCounts[i]) / float64(b.Count)
+		b.Error = b.ObservedYes - b.MeanPrice
+	}
+	if len(resolutions) > 0 {
+		report.BrierScore = squaredError / float64(len(resolutions))
+	}
+
+	return report
+}
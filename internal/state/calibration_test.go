@@ -0,0 +1,90 @@
+package state
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// addHourlyMids gives a market one hour bar per mid, the last ending at end
+func addHourlyMids(e *Engine, ticker string, end time.Time, mids []float64) {
+	start := end.Add(-time.Duration(len(mids)) * time.Hour)
+	for i, mid := range mids {
+		e.timeSeries.hourBars[ticker] = append(e.timeSeries.hourBars[ticker], Bar{
+			Start: start.Add(time.Duration(i) * time.Hour),
+			Open:  mid, High: mid, Low: mid, Close: mid,
+			Samples: 1,
+		})
+	}
+}
+
+func TestResolutionPricedAtHorizonBeforeClose(t *testing.T) {
+	e := NewEngine()
+	closeTime := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	e.RegisterMarket(&Market{Ticker: "MKT", Status: StatusClosed, CloseTime: &closeTime})
+
+	// Traded at 70¢ two days out, then converged on YES in the final day
+	mids := make([]float64, 48)
+	for i := range mids {
+		mids[i] = 0.7
+		if i >= 24 {
+			mids[i] = 0.7 + 0.3*float64(i-23)/24
+		}
+	}
+	addHourlyMids(e, "MKT", closeTime, mids)
+
+	if !e.RecordResolution("MKT", true, time.Now()) {
+		t.Fatal("resolution not recorded")
+	}
+	r := e.GetResolutions()[0]
+	if math.Abs(r.Price-0.7) > 1e-9 {
+		t.Errorf("priced at %.3f, want 0.70 from a day before close, not the converged %.3f", r.Price, mids[len(mids)-1])
+	}
+	if !r.PricedAt.Equal(closeTime.Add(-CalibrationHorizon)) {
+		t.Errorf("priced at %s, want %s", r.PricedAt, closeTime.Add(-CalibrationHorizon))
+	}
+
+	// Listed only in the final day: nothing to price it from
+	e.RegisterMarket(&Market{Ticker: "NEW", Status: StatusClosed, CloseTime: &closeTime})
+	addHourlyMids(e, "NEW", closeTime, []float64{0.4, 0.6, 0.9})
+	if e.RecordResolution("NEW", true, time.Now()) {
+		t.Error("recorded a market listed less than a horizon before close")
+	}
+}
+
+func TestCalibrateSyntheticResolvedMarkets(t *testing.T) {
+	// Ten markets at 70¢ of which seven resolved YES, ten at 20¢ of which
+	// five did: the first range is calibrated, the second underprices YES
+	var resolutions []Resolution
+	for i := 0; i < 10; i++ {
+		resolutions = append(resolutions,
+			Resolution{Ticker: fmt.Sprint("HI-", i), Price: 0.7, ResolvedYes: i < 7},
+			Resolution{Ticker: fmt.Sprint("LO-", i), Price: 0.2, ResolvedYes: i < 5},
+		)
+	}
+
+	report := Calibrate(resolutions, 10)
+	if report.Markets != 20 || len(report.Buckets) != 10 {
+		t.Fatalf("report covers %d markets in %d buckets, want 20 in 10", report.Markets, len(report.Buckets))
+	}
+
+	hi, lo := report.Buckets[7], report.Buckets[2]
+	if hi.Count != 10 || math.Abs(hi.ObservedYes-0.7) > 1e-9 || math.Abs(hi.Error) > 1e-9 {
+		t.Errorf("70¢ bucket = %+v, want 10 markets observed 0.7 with no error", hi)
+	}
+	if lo.Count != 10 || math.Abs(lo.ObservedYes-0.5) > 1e-9 || math.Abs(lo.Error-0.3) > 1e-9 {
+		t.Errorf("20¢ bucket = %+v, want 10 markets observed 0.5, error +0.3", lo)
+	}
+	for i, b := range report.Buckets {
+		if i != 2 && i != 7 && b.Count != 0 {
+			t.Errorf("bucket %d has %d markets, want 0", i, b.Count)
+		}
+	}
+
+	// Brier: 0.7 priced = 7×0.09 + 3×0.49; 0.2 priced = 5×0.64 + 5×0.04
+	want := (7*0.09 + 3*0.49 + 5*0.64 + 5*0.04) / 20
+	if math.Abs(report.BrierScore-want) > 1e-9 {
+		t.Errorf("brier = %.4f, want %.4f", report.BrierScore, want)
+	}
+}
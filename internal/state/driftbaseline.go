@@ -0,0 +1,79 @@
+package state
+
+import "time"
+
+// MidBaseline is the distribution of a market's recent snapshot reference
+// prices (the mids, under the default policy)
+type MidBaseline struct {
+	Mean    float64 // probability (0-1)
+	StdDev  float64
+	Samples int
+}
+
+type midObservation struct {
+	at  time.Time
+	mid float64
+}
+
+// midWindow keeps the mids observed within a rolling window, with their mean
+// and variance maintained incrementally as observations enter and leave
+type midWindow struct {
+	observations []midObservation
+	stats        runningStats
+}
+
+func (w *midWindow) add(at time.Time, mid float64) {
+	w.observations = append(w.observations, midObservation{at: at, mid: mid})
+	w.stats.add(mid)
+}
+
+// evict drops observations from before cutoff
+func (w *midWindow) evict(cutoff time.Time) {
+	i := 0
+	for i < len(w.observations) && w.observations[i].at.Before(cutoff) {
+		w.stats.remove(w.observations[i].mid)
+		i++
+	}
+	w.observations = w.observations[i:]
+}
+
+// SetDriftWindow sets how far back the drift baseline of snapshot mids reaches
+func (e *Engine) SetDriftWindow(window time.Duration) {
+	ts := e.GetTimeSeries()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.driftWindow = window
+}
+
+// recordDriftMid adds a snapshot mid to the market's drift baseline. Caller holds ts.mu.
+func (ts *TimeSeriesStore) recordDriftMid(ticker string, at time.Time, mid float64) {
+	w, exists := ts.driftBaselines[ticker]
+	if !exists {
+		w = &midWindow{}
+		ts.driftBaselines[ticker] = w
+	}
+	w.add(at, mid)
+	w.evict(at.Add(-ts.driftWindow))
+}
+
+// GetMidBaseline returns the distribution of the market's snapshot reference
+// prices over the drift window ending at now, or false if there are none. The
+// newest observation is left out: it is the current book's own reference
+// price, which drift measures against the baseline rather than within it.
+func (ts *TimeSeriesStore) GetMidBaseline(ticker string, now time.Time) (MidBaseline, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	w, exists := ts.driftBaselines[ticker]
+	if !exists {
+		return MidBaseline{}, false
+	}
+	w.evict(now.Add(-ts.driftWindow))
+	if len(w.observations) < 2 {
+		return MidBaseline{}, false
+	}
+
+	stats := w.stats
+	stats.remove(w.observations[len(w.observations)-1].mid)
+	return MidBaseline{Mean: stats.mean, StdDev: stats.stdDev(), Samples: stats.n}, true
+}
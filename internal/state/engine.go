@@ -9,15 +9,60 @@ type Engine struct {
 	mu         sync.RWMutex
 	markets    map[string]*Market
 	orderbooks map[string]*Orderbook
+	// Markets that have had a fetched book stored, as opposed to the empty
+	// placeholder RegisterMarket creates
+	booksFetched map[string]bool
 	tradeLogs  map[string]*TradeLog
+	metadata   map[string]*MarketMetadata
 	timeSeries *TimeSeriesStore
+
+	pinned  map[string]bool
+	pinPath string
+
+	// Ticker-channel price cache, separate from the full books
+	quotes map[string]*Quote
+
+	// Outcomes of markets that settled while tracked, for calibration
+	resolutions map[string]Resolution
+
+	// Tickers whose book or trades changed, for event-driven consumers
+	updates chan string
+
+	// When each market was first registered, and the warm-up it must complete
+	// before signals are emitted for it
+	firstSeen          map[string]time.Time
+	// Books received per market, unchanged ones included, for warm-up
+	bookUpdates        map[string]int
+	// When each market was last registered as active (or first registered),
+	// for pruning markets that closed long ago
+	lastActive         map[string]time.Time
+	warmupMinSnapshots int
+	warmupDuration     time.Duration
+
+	// Half-life of trades in the decayed activity score
+	activityHalfLife time.Duration
+
+	// Last fetched book's best prices, and when the top of book changed
+	bookTops   map[string]topOfBook
+	topChanges map[string][]time.Time
 }
 
 func NewEngine() *Engine {
 	return &Engine{
 		markets:    make(map[string]*Market),
 		orderbooks: make(map[string]*Orderbook),
+		booksFetched: make(map[string]bool),
 		tradeLogs:  make(map[string]*TradeLog),
+		metadata:   make(map[string]*MarketMetadata),
+		pinned:     make(map[string]bool),
+		updates:    make(chan string, 1000),
+		firstSeen:  make(map[string]time.Time),
+		bookUpdates: make(map[string]int),
+		lastActive: make(map[string]time.Time),
+		quotes:     make(map[string]*Quote),
+		resolutions: make(map[string]Resolution),
+		bookTops:    make(map[string]topOfBook),
+		topChanges:  make(map[string][]time.Time),
 		timeSeries: NewTimeSeriesStore(),
 	}
 }
@@ -27,19 +72,46 @@ func (e *Engine) RegisterMarket(market *Market) {
 	defer e.mu.Unlock()
 
 	e.markets[market.Ticker] = market
+	now := time.Now()
+	if _, exists := e.firstSeen[market.Ticker]; !exists {
+		e.firstSeen[market.Ticker] = now
+		e.lastActive[market.Ticker] = now
+	}
+	if market.Status == StatusActive {
+		e.lastActive[market.Ticker] = now
+	}
 	if _, exists := e.orderbooks[market.Ticker]; !exists {
 		e.orderbooks[market.Ticker] = NewOrderbook(market.Ticker)
 	}
 }
 
+// UpdateOrderbook stores a freshly fetched book and snapshots it. A book with
+// the same levels as the stored one only refreshes LastUpdate, so polling a
+// quiet market keeps it fresh without filling the snapshot history.
 func (e *Engine) UpdateOrderbook(ticker string, orderbook *Orderbook) {
 	e.mu.Lock()
+	e.booksFetched[ticker] = true
+	e.bookUpdates[ticker]++
+	top := bookTop(orderbook)
+	if previous, seen := e.bookTops[ticker]; seen && previous != top {
+		e.recordTopOfBookChange(ticker, time.Now())
+	}
+	e.bookTops[ticker] = top
+	if current, exists := e.orderbooks[ticker]; exists && current.SameLevels(orderbook) {
+		if orderbook.LastUpdate.After(current.LastUpdate) {
+			current.LastUpdate = orderbook.LastUpdate
+		}
+		e.mu.Unlock()
+		return
+	}
 	e.orderbooks[ticker] = orderbook
 	e.mu.Unlock()
 
 	// Record snapshot for time-series (call GetRecentTrades after releasing lock to avoid deadlock)
 	trades := e.GetRecentTrades(ticker, 5*time.Minute)
 	e.timeSeries.RecordSnapshot(ticker, orderbook, trades)
+
+	e.notifyUpdate(ticker)
 }
 
 func (e *Engine) AddTrade(trade *Trade) {
@@ -55,6 +127,23 @@ func (e *Engine) AddTrade(trade *Trade) {
 
 	// Record trade in time-series
 	e.timeSeries.RecordTrade(trade.MarketTicker, trade)
+
+	e.notifyUpdate(trade.MarketTicker)
+}
+
+// Updates returns a channel receiving the ticker of each market whose orderbook
+// or trades change. It is intended for a single consumer (the signal processor).
+func (e *Engine) Updates() <-chan string {
+	return e.updates
+}
+
+// notifyUpdate publishes a change without blocking; if the consumer is behind
+// the notification is dropped and the periodic sweep picks the market up
+func (e *Engine) notifyUpdate(ticker string) {
+	select {
+	case e.updates <- ticker:
+	default:
+	}
 }
 
 func (e *Engine) GetOrderbook(ticker string) (*Orderbook, bool) {
@@ -76,7 +165,7 @@ func (e *Engine) GetMarket(ticker string) (*Market, bool) {
 	if !exists {
 		return nil, false
 	}
-	return m.Clone(), true
+	return e.cloneWithBookState(m), true
 }
 
 func (e *Engine) GetAllMarkets() []*Market {
@@ -85,12 +174,33 @@ func (e *Engine) GetAllMarkets() []*Market {
 
 	markets := make([]*Market, 0, len(e.markets))
 	for _, m := range e.markets {
-		markets = append(markets, m.Clone())
+		markets = append(markets, e.cloneWithBookState(m))
 	}
 	return markets
 }
 
+// cloneWithBookState copies a market and stamps its current book state,
+// tradeability and ticker quote. Caller holds e.mu.
+func (e *Engine) cloneWithBookState(m *Market) *Market {
+	clone := m.Clone()
+	clone.Tradeable = m.IsTradeable(time.Now())
+	clone.BookState = BookEmpty
+	if ob, exists := e.orderbooks[m.Ticker]; exists {
+		clone.BookState = ob.State()
+	}
+	if quote, exists := e.quotes[m.Ticker]; exists {
+		q := *quote
+		clone.Quote = &q
+	}
+	return clone
+}
+
 func (e *Engine) GetRecentTrades(ticker string, window time.Duration) []*Trade {
+	return e.GetTradesSince(ticker, time.Now().Add(-window))
+}
+
+// GetTradesSince returns trades at or after cutoff, for callers that supply their own clock
+func (e *Engine) GetTradesSince(ticker string, cutoff time.Time) []*Trade {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -99,7 +209,6 @@ func (e *Engine) GetRecentTrades(ticker string, window time.Duration) []*Trade {
 		return nil
 	}
 
-	cutoff := time.Now().Add(-window)
 	return log.GetSince(cutoff)
 }
 
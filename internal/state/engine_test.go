@@ -0,0 +1,53 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateOrderbookSkipsIdenticalBooks(t *testing.T) {
+	e := NewEngine()
+	book := func(bidQty int, at time.Time) *Orderbook {
+		ob := NewOrderbook("MKT")
+		ob.Bids = []PriceLevel{{Price: 45, Quantity: bidQty}}
+		ob.Asks = []PriceLevel{{Price: 47, Quantity: 100}}
+		ob.LastUpdate = at
+		return ob
+	}
+	snapshots := func() int {
+		return len(e.GetTimeSeries().GetSnapshots("MKT", time.Time{}))
+	}
+
+	first := time.Now().Add(-2 * time.Minute)
+	e.UpdateOrderbook("MKT", book(100, first))
+	if got := snapshots(); got != 1 {
+		t.Fatalf("%d snapshots after the first update, want 1", got)
+	}
+
+	// The same levels polled a minute later: no new snapshot, but the poll
+	// still counts for freshness
+	polled := first.Add(time.Minute)
+	e.UpdateOrderbook("MKT", book(100, polled))
+	if got := snapshots(); got != 1 {
+		t.Errorf("%d snapshots after an identical update, want 1", got)
+	}
+	ob, _ := e.GetOrderbook("MKT")
+	if !ob.LastUpdate.Equal(polled) {
+		t.Errorf("LastUpdate = %v, want the identical poll's %v", ob.LastUpdate, polled)
+	}
+
+	// An out-of-order identical book doesn't move LastUpdate backwards
+	e.UpdateOrderbook("MKT", book(100, first))
+	if ob, _ := e.GetOrderbook("MKT"); !ob.LastUpdate.Equal(polled) {
+		t.Errorf("LastUpdate moved back to %v", ob.LastUpdate)
+	}
+
+	// A changed level is stored and snapshotted
+	e.UpdateOrderbook("MKT", book(120, polled.Add(time.Minute)))
+	if got := snapshots(); got != 2 {
+		t.Errorf("%d snapshots after a changed book, want 2", got)
+	}
+	if ob, _ := e.GetOrderbook("MKT"); ob.Bids[0].Quantity != 120 {
+		t.Errorf("stored bid quantity = %d, want 120", ob.Bids[0].Quantity)
+	}
+}
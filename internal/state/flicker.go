@@ -0,0 +1,48 @@
+package state
+
+import "time"
+
+// maxTopOfBookChanges bounds the per-market history of top-of-book changes
+const maxTopOfBookChanges = 1000
+
+// topOfBook is a market's best bid and ask price in cents, 0 for an empty side
+type topOfBook struct {
+	bid, ask int
+}
+
+func bookTop(ob *Orderbook) topOfBook {
+	var top topOfBook
+	if len(ob.Bids) > 0 {
+		top.bid = ob.Bids[0].Price
+	}
+	if len(ob.Asks) > 0 {
+		top.ask = ob.Asks[0].Price
+	}
+	return top
+}
+
+// recordTopOfBookChange notes that the best bid or ask moved, appeared or
+// vanished at the given time. Caller holds e.mu.
+func (e *Engine) recordTopOfBookChange(ticker string, at time.Time) {
+	changes := append(e.topChanges[ticker], at)
+	if len(changes) > maxTopOfBookChanges {
+		changes = changes[len(changes)-maxTopOfBookChanges:]
+	}
+	e.topChanges[ticker] = changes
+}
+
+// TopOfBookChanges counts how often the market's best bid or ask price changed,
+// or a side appeared or vanished, since the given time. Books and ticker
+// quotes are each compared against their own previous top, so the two feeds
+// disagreeing doesn't count as a change.
+func (e *Engine) TopOfBookChanges(ticker string, since time.Time) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	changes := e.topChanges[ticker]
+	count := 0
+	for i := len(changes) - 1; i >= 0 && !changes[i].Before(since); i-- {
+		count++
+	}
+	return count
+}
@@ -15,32 +15,104 @@ const (
 	StatusFinalized   MarketStatus = "finalized"
 )
 
+// MarketType is Kalshi's contract structure. Binary markets pay $1 to YES or
+// NO; scalar markets pay YES a fraction of $1 set by a measured value and NO
+// the rest. Empty means the feed didn't say, which is treated as binary.
+type MarketType string
+
+const (
+	MarketTypeBinary MarketType = "binary"
+	MarketTypeScalar MarketType = "scalar"
+)
+
 type Market struct {
 	Ticker         string       `json:"ticker"`
 	Title          string       `json:"title"`
 	Category       string       `json:"category"`
 	Status         MarketStatus `json:"status"`
 	ExpirationTime *time.Time   `json:"expiration_time,omitempty"`
+	OpenTime       *time.Time   `json:"open_time,omitempty"`
+	CloseTime      *time.Time   `json:"close_time,omitempty"`
 	EventTicker    string       `json:"event_ticker"`
+	MarketType     MarketType   `json:"market_type,omitempty"`
 	YesSubTitle    string       `json:"yes_sub_title,omitempty"`
 	NoSubTitle     string       `json:"no_sub_title,omitempty"`
+	TickSize       int          `json:"tick_size"`            // minimum price increment in cents
+	BookState      BookState    `json:"book_state,omitempty"` // filled from the orderbook on read
+	Tradeable      bool         `json:"tradeable"`            // filled from status and schedule on read
+	Quote          *Quote       `json:"quote,omitempty"`      // filled from the ticker channel cache on read
 }
 
 func (m *Market) Clone() *Market {
-	var expTime *time.Time
-	if m.ExpirationTime != nil {
-		t := *m.ExpirationTime
-		expTime = &t
-	}
 	return &Market{
 		Ticker:         m.Ticker,
 		Title:          m.Title,
 		Category:       m.Category,
 		Status:         m.Status,
-		ExpirationTime: expTime,
+		ExpirationTime: cloneTime(m.ExpirationTime),
+		OpenTime:       cloneTime(m.OpenTime),
+		CloseTime:      cloneTime(m.CloseTime),
 		EventTicker:    m.EventTicker,
+		MarketType:     m.MarketType,
 		YesSubTitle:    m.YesSubTitle,
 		NoSubTitle:     m.NoSubTitle,
+		TickSize:       m.TickSize,
+		BookState:      m.BookState,
+		Tradeable:      m.Tradeable,
+		Quote:          cloneQuote(m.Quote),
+	}
+}
+
+func cloneQuote(q *Quote) *Quote {
+	if q == nil {
+		return nil
+	}
+	c := *q
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
+	c := *t
+	return &c
+}
+
+// IsTradeable reports whether the market is active and inside its trading
+// window. Pre-open and post-close books can be stubs, so signals and alerts
+// only consider tradeable markets. Missing open/close times don't restrict.
+func (m *Market) IsTradeable(now time.Time) bool {
+	if m.Status != StatusActive {
+		return false
+	}
+	if m.OpenTime != nil && now.Before(*m.OpenTime) {
+		return false
+	}
+	if m.CloseTime != nil && !now.Before(*m.CloseTime) {
+		return false
+	}
+	return true
+}
+
+// IsBinary reports whether the market settles all-or-nothing. Only binary
+// markets of an event can be treated as mutually exclusive outcomes whose
+// prices sum to $1; scalar and unrecognised types can't.
+func (m *Market) IsBinary() bool {
+	return m.MarketType == "" || m.MarketType == MarketTypeBinary
+}
+
+// Tick returns the market's price increment in cents, defaulting to 1
+func (m *Market) Tick() int {
+	if m.TickSize <= 0 {
+		return 1
+	}
+	return m.TickSize
+}
+
+// SpreadInTicks converts a spread in cents to whole ticks, rounding up
+func (m *Market) SpreadInTicks(spreadCents int) int {
+	tick := m.Tick()
+	return (spreadCents + tick - 1) / tick
 }
 
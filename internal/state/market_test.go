@@ -0,0 +1,67 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpreadInTicksNonUnitTick(t *testing.T) {
+	market := &Market{Ticker: "MKT", TickSize: 2}
+	tests := []struct {
+		spread, want int
+	}{
+		{2, 1},
+		{4, 2},
+		{3, 2}, // off-grid spreads round up to whole ticks
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := market.SpreadInTicks(tt.spread); got != tt.want {
+			t.Errorf("SpreadInTicks(%d) = %d, want %d", tt.spread, got, tt.want)
+		}
+	}
+
+	if got := (&Market{}).Tick(); got != 1 {
+		t.Errorf("default tick = %d, want 1", got)
+	}
+}
+
+func TestIsTradeableOutsideTradingWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hour := time.Hour
+	at := func(d time.Duration) *time.Time {
+		tm := now.Add(d)
+		return &tm
+	}
+	tests := []struct {
+		name   string
+		market Market
+		want   bool
+	}{
+		{"inside window", Market{Status: StatusActive, OpenTime: at(-hour), CloseTime: at(hour)}, true},
+		{"pre-open", Market{Status: StatusActive, OpenTime: at(hour), CloseTime: at(2 * hour)}, false},
+		{"past close", Market{Status: StatusActive, OpenTime: at(-2 * hour), CloseTime: at(-hour)}, false},
+		{"closes now", Market{Status: StatusActive, CloseTime: at(0)}, false},
+		{"no schedule", Market{Status: StatusActive}, true},
+		{"not active", Market{Status: StatusClosed, OpenTime: at(-hour), CloseTime: at(hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.market.IsTradeable(now); got != tt.want {
+			t.Errorf("%s: IsTradeable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetMarketFillsTradeable(t *testing.T) {
+	engine := NewEngine()
+	open := time.Now().Add(time.Hour)
+	engine.RegisterMarket(&Market{Ticker: "PREOPEN", Status: StatusActive, OpenTime: &open})
+
+	market, ok := engine.GetMarket("PREOPEN")
+	if !ok {
+		t.Fatal("market not registered")
+	}
+	if market.Tradeable {
+		t.Error("active market before its open time reported tradeable")
+	}
+}
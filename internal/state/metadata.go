@@ -0,0 +1,32 @@
+package state
+
+// MarketMetadata is descriptive text about what a market resolves on.
+// It is fetched once per event and kept separately from Market so the
+// periodic market re-registration doesn't clobber it.
+type MarketMetadata struct {
+	EventTitle     string `json:"event_title,omitempty"`
+	EventSubtitle  string `json:"event_subtitle,omitempty"`
+	Subtitle       string `json:"subtitle,omitempty"`
+	RulesPrimary   string `json:"rules_primary,omitempty"`
+	RulesSecondary string `json:"rules_secondary,omitempty"`
+}
+
+func (e *Engine) SetMarketMetadata(ticker string, metadata *MarketMetadata) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m := *metadata
+	e.metadata[ticker] = &m
+}
+
+func (e *Engine) GetMarketMetadata(ticker string) (*MarketMetadata, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	m, exists := e.metadata[ticker]
+	if !exists {
+		return nil, false
+	}
+	clone := *m
+	return &clone, true
+}
@@ -0,0 +1,28 @@
+package state
+
+import "math"
+
+// MinStdDev is the smallest standard deviation treated as nonzero. Markets
+// pinned at 0 or 100 have (near-)flat prices, and dividing by float noise
+// produces huge or infinite ratios.
+const MinStdDev = 1e-6
+
+// Finite returns x, or 0 if x is NaN or infinite, so degenerate metrics never
+// reach the JSON encoder
+func Finite(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return 0
+	}
+	return x
+}
+
+// Clamp limits x to [lo, hi], mapping NaN to lo
+func Clamp(x, lo, hi float64) float64 {
+	if math.IsNaN(x) || x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
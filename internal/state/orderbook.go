@@ -1,6 +1,9 @@
 package state
 
 import (
+	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
@@ -13,6 +16,16 @@ type Orderbook struct {
 	LastUpdate   time.Time    `json:"last_update"`
 }
 
+// BookState describes which sides of an orderbook have resting liquidity
+type BookState string
+
+const (
+	BookTwoSided BookState = "two_sided"
+	BookBidOnly  BookState = "bid_only"
+	BookAskOnly  BookState = "ask_only"
+	BookEmpty    BookState = "empty"
+)
+
 type PriceLevel struct {
 	Price    int `json:"price"`    // In cents
 	Quantity int `json:"quantity"`
@@ -23,7 +36,7 @@ func NewOrderbook(marketTicker string) *Orderbook {
 		MarketTicker: marketTicker,
 		Bids:         make([]PriceLevel, 0),
 		Asks:         make([]PriceLevel, 0),
-		LastUpdate:   time.Now(),
+		LastUpdate:   time.Now().UTC(),
 	}
 }
 
@@ -40,25 +53,58 @@ func (ob *Orderbook) Clone() *Orderbook {
 	}
 }
 
+// SameLevels reports whether other has exactly the same bid and ask levels
+func (ob *Orderbook) SameLevels(other *Orderbook) bool {
+	return slices.Equal(ob.Bids, other.Bids) && slices.Equal(ob.Asks, other.Asks)
+}
+
 // UpdateFromKalshi updates the orderbook from Kalshi API response
 // Kalshi returns yes_dollars and no_dollars arrays where each is [price_string, count_string]
 // These are BIDS only. We synthesize ASKS:
 // - YES asks = NO bids transformed: NO bid at X = YES ask at (100-X)
 // - NO asks = YES bids transformed: YES bid at X = NO ask at (100-X)
 // For a binary market, we track YES side and synthesize both YES and NO asks
-func (ob *Orderbook) UpdateFromKalshi(resp *KalshiOrderbookResponse) {
-	ob.Bids = ob.Bids[:0]
-	ob.Asks = ob.Asks[:0]
+// Levels with zero quantity are dropped rather than stored as empty levels.
+//
+// Levels that fail to parse are skipped and counted. If more than
+// maxFailureRatio of the levels fail, the update is rejected and the book is
+// left unchanged, so a malformed response isn't mistaken for an empty book.
+func (ob *Orderbook) UpdateFromKalshi(resp *KalshiOrderbookResponse, maxFailureRatio float64) error {
+	bids := make([]PriceLevel, 0, len(resp.OrderbookFp.YesDollars))
+	asks := make([]PriceLevel, 0, len(resp.OrderbookFp.NoDollars))
+	total := len(resp.OrderbookFp.YesDollars) + len(resp.OrderbookFp.NoDollars)
+	var failed int
+	var firstErr error
 
-	// YES bids become our YES bids
-	for _, level := range resp.OrderbookFp.YesDollars {
+	parseLevel := func(level []string) (int, int, bool) {
 		if len(level) < 2 {
-			continue
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("level has %d fields, want 2", len(level))
+			}
+			return 0, 0, false
 		}
-		priceCents, err1 := parseDollarToCents(level[0])
-		qty, err2 := parseFixedPointCount(level[1])
-		if err1 == nil && err2 == nil {
-			ob.Bids = append(ob.Bids, PriceLevel{
+		priceCents, err := parseDollarToCents(level[0])
+		if err == nil {
+			var qty int
+			qty, err = parseFixedPointCount(level[1])
+			if err == nil {
+				return priceCents, qty, true
+			}
+		}
+		failed++
+		if firstErr == nil {
+			firstErr = err
+		}
+		return 0, 0, false
+	}
+
+	// YES bids become our YES bids. Zero-quantity levels mark a price with
+	// nothing resting (some feeds send them for removed levels) and are left
+	// out, so they can't pose as the best bid or ask.
+	for _, level := range resp.OrderbookFp.YesDollars {
+		if priceCents, qty, ok := parseLevel(level); ok && qty > 0 {
+			bids = append(bids, PriceLevel{
 				Price:    priceCents,
 				Quantity: qty,
 			})
@@ -67,34 +113,95 @@ func (ob *Orderbook) UpdateFromKalshi(resp *KalshiOrderbookResponse) {
 
 	// NO bids become YES asks (synthesized): NO bid at X = YES ask at (100-X)
 	for _, level := range resp.OrderbookFp.NoDollars {
-		if len(level) < 2 {
-			continue
-		}
-		noPriceCents, err1 := parseDollarToCents(level[0])
-		qty, err2 := parseFixedPointCount(level[1])
-		if err1 == nil && err2 == nil {
+		if noPriceCents, qty, ok := parseLevel(level); ok && qty > 0 {
 			// Convert NO bid price to YES ask: NO bid at X = YES ask at (100-X)
-			yesAskPrice := 10000 - noPriceCents // 100.00 dollars = 10000 cents
-			ob.Asks = append(ob.Asks, PriceLevel{
-				Price:    yesAskPrice,
+			// Both are in cents ($1.00 = 100 cents)
+			asks = append(asks, PriceLevel{
+				Price:    100 - noPriceCents,
 				Quantity: qty,
 			})
 		}
 	}
 
+	if failed > 0 {
+		if float64(failed)/float64(total) > maxFailureRatio {
+			return fmt.Errorf("rejected orderbook for %s: %d/%d levels were invalid (first: %v)", ob.MarketTicker, failed, total, firstErr)
+		}
+		fmt.Printf("Orderbook %s: skipped %d/%d invalid levels (first: %v)\n", ob.MarketTicker, failed, total, firstErr)
+	}
+
 	// Note: We synthesize YES asks from NO bids above.
 	// For a binary market, tracking YES side is sufficient.
 	// NO side can be derived: NO price = 100 - YES price
+	// Scalar markets pay YES and NO complementary shares of $1, so the same
+	// synthesis holds for their books; what doesn't carry over is treating an
+	// event's markets as outcomes summing to $1 (see Market.IsBinary).
 
 	// Sort bids descending (best bid first), asks ascending (best ask first)
-	sort.Slice(ob.Bids, func(i, j int) bool {
-		return ob.Bids[i].Price > ob.Bids[j].Price
+	sort.Slice(bids, func(i, j int) bool {
+		return bids[i].Price > bids[j].Price
 	})
-	sort.Slice(ob.Asks, func(i, j int) bool {
-		return ob.Asks[i].Price < ob.Asks[j].Price
+	sort.Slice(asks, func(i, j int) bool {
+		return asks[i].Price < asks[j].Price
 	})
 
-	ob.LastUpdate = time.Now()
+	// A YES bid above the synthesized YES ask would have matched on the exchange
+	if len(bids) > 0 && len(asks) > 0 && bids[0].Price > asks[0].Price {
+		return fmt.Errorf("rejected orderbook for %s: crossed book (best bid %d¢ > best ask %d¢)", ob.MarketTicker, bids[0].Price, asks[0].Price)
+	}
+
+	ob.Bids = bids
+	ob.Asks = asks
+	ob.LastUpdate = time.Now().UTC()
+	return nil
+}
+
+// State classifies the book as two-sided, one-sided, or empty
+func (ob *Orderbook) State() BookState {
+	switch {
+	case len(ob.Bids) > 0 && len(ob.Asks) > 0:
+		return BookTwoSided
+	case len(ob.Bids) > 0:
+		return BookBidOnly
+	case len(ob.Asks) > 0:
+		return BookAskOnly
+	default:
+		return BookEmpty
+	}
+}
+
+// OutsideTouch reports whether a trade at price (cents) printed below the best
+// bid or above the best ask, which an up-to-date book can't produce. Sides
+// with no levels aren't checked.
+func (ob *Orderbook) OutsideTouch(price int) bool {
+	if len(ob.Bids) > 0 && price < ob.Bids[0].Price {
+		return true
+	}
+	return len(ob.Asks) > 0 && price > ob.Asks[0].Price
+}
+
+// InferTakerSide guesses the taker side of a trade the feed didn't label, from
+// where its price (cents) sits against the touch: at or above the best ask a
+// buyer lifted the offer (SideYes), at or below the best bid a seller hit it
+// (SideNo). Inside the spread the nearer side wins; at mid, or with a side of
+// the book missing, it returns "" rather than guess.
+func (ob *Orderbook) InferTakerSide(price int) TradeSide {
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return ""
+	}
+	bid, ask := ob.Bids[0].Price, ob.Asks[0].Price
+	switch {
+	case price >= ask:
+		return SideYes
+	case price <= bid:
+		return SideNo
+	case 2*price > bid+ask:
+		return SideYes
+	case 2*price < bid+ask:
+		return SideNo
+	default:
+		return ""
+	}
 }
 
 func (ob *Orderbook) Spread() (int, bool) {
@@ -122,27 +229,45 @@ func (ob *Orderbook) AskDepth() int64 {
 	return depth
 }
 
+// ImbalanceRatio returns (bid depth - ask depth) / total depth, in [-1, +1].
+// Positive means more resting notional on the bid (buying pressure), negative
+// more on the ask; 0 for a balanced or empty book.
 func (ob *Orderbook) ImbalanceRatio() float64 {
 	bidDepth := float64(ob.BidDepth())
 	askDepth := float64(ob.AskDepth())
 	total := bidDepth + askDepth
-	if total == 0 {
+	if total <= 0 {
 		return 0.0
 	}
-	return (bidDepth - askDepth) / total
+	return math.Max(-1, math.Min(1, (bidDepth-askDepth)/total))
 }
 
 // Microprice computes volume-weighted mid price (microprice)
 // This is a better estimate of fair value than simple mid
 func (ob *Orderbook) Microprice() (float64, bool) {
+	return ob.MicropriceDepth(1)
+}
+
+// MicropriceDepth is the microprice with sizes summed over the top levels of
+// each side, so a tiny top-of-book order doesn't swing it. The price still
+// lies between the best bid and ask; levels <= 1 is the top-of-book version.
+func (ob *Orderbook) MicropriceDepth(levels int) (float64, bool) {
 	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
 		return 0, false
 	}
+	if levels < 1 {
+		levels = 1
+	}
 
 	bestBid := float64(ob.Bids[0].Price) / 100.0
 	bestAsk := float64(ob.Asks[0].Price) / 100.0
-	bidQty := float64(ob.Bids[0].Quantity)
-	askQty := float64(ob.Asks[0].Quantity)
+	var bidQty, askQty float64
+	for i := 0; i < levels && i < len(ob.Bids); i++ {
+		bidQty += float64(ob.Bids[i].Quantity)
+	}
+	for i := 0; i < levels && i < len(ob.Asks); i++ {
+		askQty += float64(ob.Asks[i].Quantity)
+	}
 
 	// Volume-weighted mid: (bid*askQty + ask*bidQty) / (bidQty + askQty)
 	totalQty := bidQty + askQty
@@ -183,6 +308,31 @@ func (ob *Orderbook) DepthAtPrice(centsFromMid int) (int64, int64) {
 	return bidDepth, askDepth
 }
 
+// BandImbalance is the resting size within Cents of mid on each side, and
+// their imbalance (bid - ask) / (bid + ask) in [-1, +1]
+type BandImbalance struct {
+	Cents     int     `json:"cents"`
+	BidDepth  int64   `json:"bid_depth"`
+	AskDepth  int64   `json:"ask_depth"`
+	Imbalance float64 `json:"imbalance"`
+}
+
+// ImbalanceProfile returns the imbalance within each band of cents from mid,
+// showing whether pressure sits at the touch or deeper in the book. Empty
+// bands have imbalance 0.
+func (ob *Orderbook) ImbalanceProfile(bands []int) []BandImbalance {
+	profile := make([]BandImbalance, 0, len(bands))
+	for _, cents := range bands {
+		bidDepth, askDepth := ob.DepthAtPrice(cents)
+		band := BandImbalance{Cents: cents, BidDepth: bidDepth, AskDepth: askDepth}
+		if total := bidDepth + askDepth; total > 0 {
+			band.Imbalance = float64(bidDepth-askDepth) / float64(total)
+		}
+		profile = append(profile, band)
+	}
+	return profile
+}
+
 // KalshiOrderbookResponse represents the API response structure
 type KalshiOrderbookResponse struct {
 	OrderbookFp KalshiOrderbookFp `json:"orderbook_fp"`
@@ -193,12 +343,22 @@ type KalshiOrderbookFp struct {
 	NoDollars  [][]string `json:"no_dollars"`
 }
 
+// parseDollarToCents converts a dollar string like "0.29" to cents, rounding
+// rather than truncating (0.29*100 is 28.999... in float64). Prices outside
+// the binary contract range of $0.00-$1.00 are rejected.
 func parseDollarToCents(s string) (int, error) {
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return 0, err
 	}
-	return int(f * 100), nil
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid price %q", s)
+	}
+	cents := int(math.Round(f * 100))
+	if cents < 0 || cents > 100 {
+		return 0, fmt.Errorf("price %q out of range", s)
+	}
+	return cents, nil
 }
 
 func parseFixedPointCount(s string) (int, error) {
@@ -206,6 +366,9 @@ func parseFixedPointCount(s string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if math.IsNaN(f) || math.IsInf(f, 0) || f < 0 || f > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
 	return int(f), nil
 }
 
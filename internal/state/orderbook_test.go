@@ -0,0 +1,324 @@
+package state
+
+import "testing"
+
+func TestOrderbookState(t *testing.T) {
+	level := []PriceLevel{{Price: 50, Quantity: 10}}
+	tests := []struct {
+		name       string
+		bids, asks []PriceLevel
+		want       BookState
+	}{
+		{"two-sided", level, level, BookTwoSided},
+		{"bid only", level, nil, BookBidOnly},
+		{"ask only", nil, level, BookAskOnly},
+		{"empty", nil, nil, BookEmpty},
+	}
+	for _, tt := range tests {
+		ob := NewOrderbook("MKT")
+		ob.Bids = tt.bids
+		ob.Asks = tt.asks
+		if got := ob.State(); got != tt.want {
+			t.Errorf("%s: State() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDollarToCents(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"0.01", 1, false},
+		{"0.29", 29, false},
+		{"0.57", 57, false},
+		{"0.99", 99, false},
+		{"1.00", 100, false},
+		{"0", 0, false},
+		{"1.01", 0, true},
+		{"-0.01", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDollarToCents(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDollarToCents(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDollarToCents(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSynthesizedAskFromNoBid(t *testing.T) {
+	ob := NewOrderbook("MKT")
+	resp := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"0.29", "10.00"}},
+		NoDollars:  [][]string{{"0.71", "5.00"}, {"0.01", "3.00"}},
+	}}
+	if err := ob.UpdateFromKalshi(resp, 0); err != nil {
+		t.Fatal(err)
+	}
+	if ob.Bids[0].Price != 29 {
+		t.Errorf("best bid = %d¢, want 29¢", ob.Bids[0].Price)
+	}
+	// A NO bid at X is a YES ask at 100-X: 71¢ -> 29¢, 1¢ -> 99¢
+	if len(ob.Asks) != 2 || ob.Asks[0].Price != 29 || ob.Asks[1].Price != 99 {
+		t.Errorf("asks = %+v, want 29¢ and 99¢", ob.Asks)
+	}
+}
+
+func TestOutOfRangeLevelRejected(t *testing.T) {
+	ob := NewOrderbook("MKT")
+	resp := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"1.50", "10.00"}},
+	}}
+	if err := ob.UpdateFromKalshi(resp, 0); err == nil {
+		t.Fatal("book with a $1.50 level was accepted")
+	}
+}
+
+func TestUpdateFromKalshiMixedValidAndInvalidLevels(t *testing.T) {
+	valid := [][]string{{"0.45", "100.00"}, {"0.44", "50.00"}, {"0.43", "25.00"}}
+	mixed := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: append(valid, []string{"abc", "10.00"}),
+		NoDollars:  [][]string{{"0.53", "100.00"}, {"0.52", "oops"}, {"0.51"}, {"0.50", "20.00"}},
+	}}
+	// 3 of 8 levels are invalid
+
+	ob := NewOrderbook("MKT")
+	if err := ob.UpdateFromKalshi(mixed, 0.5); err != nil {
+		t.Fatalf("3/8 invalid under a 0.5 limit: %v", err)
+	}
+	if len(ob.Bids) != 3 || len(ob.Asks) != 2 {
+		t.Fatalf("kept %d bids and %d asks, want the 3 and 2 valid levels", len(ob.Bids), len(ob.Asks))
+	}
+	if ob.Bids[0].Price != 45 || ob.Asks[0].Price != 47 {
+		t.Errorf("touch = %d/%d, want 45/47", ob.Bids[0].Price, ob.Asks[0].Price)
+	}
+
+	// Over the limit the update is rejected and the previous book is kept
+	if err := ob.UpdateFromKalshi(mixed, 0.25); err == nil {
+		t.Fatal("3/8 invalid under a 0.25 limit was accepted")
+	}
+	if len(ob.Bids) != 3 || len(ob.Asks) != 2 {
+		t.Errorf("rejected update changed the book to %d bids and %d asks", len(ob.Bids), len(ob.Asks))
+	}
+
+	// A fully malformed response isn't mistaken for an empty book
+	garbage := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"x", "y"}},
+		NoDollars:  [][]string{{"1.50", "10.00"}},
+	}}
+	if err := NewOrderbook("MKT").UpdateFromKalshi(garbage, 0.5); err == nil {
+		t.Error("fully malformed response was accepted")
+	}
+}
+
+func TestMicropriceTopOfBookVsThreeLevels(t *testing.T) {
+	// A small bid on top of a deep bid stack, against a large ask that thins out
+	ob := NewOrderbook("MKT")
+	ob.Bids = []PriceLevel{{Price: 48, Quantity: 10}, {Price: 47, Quantity: 400}, {Price: 46, Quantity: 400}}
+	ob.Asks = []PriceLevel{{Price: 52, Quantity: 200}, {Price: 53, Quantity: 50}, {Price: 54, Quantity: 50}}
+
+	top, ok := ob.MicropriceDepth(1)
+	if !ok {
+		t.Fatal("no microprice")
+	}
+	// (0.48*200 + 0.52*10) / 210: the thin top bid pulls it toward the bid
+	if want := (0.48*200 + 0.52*10) / 210; !closeTo(top, want) {
+		t.Errorf("N=1 microprice = %.5f, want %.5f", top, want)
+	}
+	if legacy, _ := ob.Microprice(); legacy != top {
+		t.Errorf("Microprice() = %.5f, want the N=1 value %.5f", legacy, top)
+	}
+
+	deep, _ := ob.MicropriceDepth(3)
+	// 810 bid contracts vs 300 ask: the stack behind the touch pulls it up
+	if want := (0.48*300 + 0.52*810) / 1110; !closeTo(deep, want) {
+		t.Errorf("N=3 microprice = %.5f, want %.5f", deep, want)
+	}
+	if top >= 0.50 || deep <= 0.50 {
+		t.Errorf("N=1 %.4f should sit below mid and N=3 %.4f above it", top, deep)
+	}
+
+	// Levels past the end of a side, and N < 1, are harmless
+	if all, _ := ob.MicropriceDepth(10); all != deep {
+		t.Errorf("N=10 microprice = %.5f, want the N=3 value %.5f on a 3-level book", all, deep)
+	}
+	if zero, _ := ob.MicropriceDepth(0); zero != top {
+		t.Errorf("N=0 microprice = %.5f, want top of book %.5f", zero, top)
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+func TestUpdateFromKalshiRejectsOutOfRangeLevels(t *testing.T) {
+	good := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"0.45", "100.00"}},
+		NoDollars:  [][]string{{"0.53", "100.00"}},
+	}}
+	ob := NewOrderbook("MKT")
+	if err := ob.UpdateFromKalshi(good, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		yes  [][]string
+		no   [][]string
+	}{
+		{"YES bid above $1", [][]string{{"1.50", "10.00"}}, nil},
+		{"NO bid above $1 (negative YES ask)", nil, [][]string{{"1.20", "10.00"}}},
+		{"negative price", [][]string{{"-0.10", "10.00"}}, nil},
+		{"negative quantity", [][]string{{"0.40", "-5.00"}}, nil},
+		{"non-finite quantity", nil, [][]string{{"0.50", "NaN"}}},
+		{"crossed book", [][]string{{"0.60", "10.00"}}, [][]string{{"0.45", "10.00"}}},
+	}
+	for _, tt := range tests {
+		resp := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{YesDollars: tt.yes, NoDollars: tt.no}}
+		if err := ob.UpdateFromKalshi(resp, 0); err == nil {
+			t.Errorf("%s: update accepted", tt.name)
+		}
+		if len(ob.Bids) != 1 || ob.Bids[0].Price != 45 || len(ob.Asks) != 1 || ob.Asks[0].Price != 47 {
+			t.Fatalf("%s: rejected update changed the book to %+v / %+v", tt.name, ob.Bids, ob.Asks)
+		}
+	}
+
+	// Under the failure ratio a bad level is skipped and the rest of the book
+	// is kept, still within 0-100¢
+	mixed := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"0.44", "100.00"}, {"1.50", "10.00"}},
+		NoDollars:  [][]string{{"0.54", "100.00"}, {"1.20", "10.00"}},
+	}}
+	if err := ob.UpdateFromKalshi(mixed, 0.5); err != nil {
+		t.Fatalf("2/4 invalid under a 0.5 limit: %v", err)
+	}
+	for _, level := range append(append([]PriceLevel{}, ob.Bids...), ob.Asks...) {
+		if level.Price < 0 || level.Price > 100 {
+			t.Errorf("level %+v outside 0-100¢ survived", level)
+		}
+	}
+	if len(ob.Bids) != 1 || ob.Bids[0].Price != 44 || len(ob.Asks) != 1 || ob.Asks[0].Price != 46 {
+		t.Errorf("book = %+v / %+v, want 44 / 46", ob.Bids, ob.Asks)
+	}
+}
+
+func TestImbalanceProfileFrontVsBackHeavy(t *testing.T) {
+	// Both books have the same bid and ask totals within 10¢ of a 50¢ mid; the
+	// front-heavy one has its extra bids at the touch, the back-heavy one deep
+	front := NewOrderbook("FRONT")
+	front.Bids = []PriceLevel{{Price: 49, Quantity: 400}, {Price: 42, Quantity: 100}}
+	front.Asks = []PriceLevel{{Price: 51, Quantity: 100}, {Price: 58, Quantity: 100}}
+	back := NewOrderbook("BACK")
+	back.Bids = []PriceLevel{{Price: 49, Quantity: 100}, {Price: 42, Quantity: 400}}
+	back.Asks = []PriceLevel{{Price: 51, Quantity: 100}, {Price: 58, Quantity: 100}}
+
+	bands := []int{1, 2, 5, 10}
+	wantFront := []float64{0.6, 0.6, 0.6, 3.0 / 7}
+	wantBack := []float64{0, 0, 0, 3.0 / 7}
+
+	for name, tt := range map[string]struct {
+		ob   *Orderbook
+		want []float64
+	}{"front-heavy": {front, wantFront}, "back-heavy": {back, wantBack}} {
+		profile := tt.ob.ImbalanceProfile(bands)
+		if len(profile) != len(bands) {
+			t.Fatalf("%s: %d bands, want %d", name, len(profile), len(bands))
+		}
+		for i, band := range profile {
+			if band.Cents != bands[i] || !closeTo(band.Imbalance, tt.want[i]) {
+				t.Errorf("%s: band %d¢ imbalance = %.4f, want %.4f", name, band.Cents, band.Imbalance, tt.want[i])
+			}
+		}
+	}
+
+	// The deepest band can't tell them apart; the touch can
+	f, b := front.ImbalanceProfile(bands), back.ImbalanceProfile(bands)
+	if f[3].Imbalance != b[3].Imbalance || f[0].Imbalance == b[0].Imbalance {
+		t.Errorf("10¢ bands %.3f/%.3f should match and 1¢ bands %.3f/%.3f differ",
+			f[3].Imbalance, b[3].Imbalance, f[0].Imbalance, b[0].Imbalance)
+	}
+
+	if profile := NewOrderbook("EMPTY").ImbalanceProfile(bands); len(profile) != 4 || profile[0].Imbalance != 0 {
+		t.Errorf("empty book profile = %+v, want zeroed bands", profile)
+	}
+}
+
+func TestInferTakerSide(t *testing.T) {
+	ob := NewOrderbook("MKT")
+	ob.Bids = []PriceLevel{{Price: 45, Quantity: 100}}
+	ob.Asks = []PriceLevel{{Price: 49, Quantity: 100}}
+
+	tests := []struct {
+		price int
+		want  TradeSide
+	}{
+		{49, SideYes}, // at the ask
+		{51, SideYes}, // through the ask
+		{45, SideNo},  // at the bid
+		{43, SideNo},  // through the bid
+		{48, SideYes}, // inside, nearer the ask
+		{46, SideNo},  // inside, nearer the bid
+		{47, ""},      // at mid
+	}
+	for _, tt := range tests {
+		if got := ob.InferTakerSide(tt.price); got != tt.want {
+			t.Errorf("InferTakerSide(%d) = %q, want %q", tt.price, got, tt.want)
+		}
+	}
+
+	oneSided := NewOrderbook("ONE")
+	oneSided.Bids = []PriceLevel{{Price: 45, Quantity: 100}}
+	if got := oneSided.InferTakerSide(45); got != "" {
+		t.Errorf("one-sided book: InferTakerSide = %q, want unknown", got)
+	}
+}
+
+func TestUpdateFromKalshiDropsZeroQuantityLevels(t *testing.T) {
+	// Zero-quantity levels at the best YES bid (46¢) and best NO bid (53¢,
+	// i.e. a 47¢ YES ask) mark removed prices
+	resp := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"0.44", "200.00"}, {"0.46", "0.00"}, {"0.45", "100.00"}},
+		NoDollars:  [][]string{{"0.53", "0.00"}, {"0.52", "150.00"}},
+	}}
+	ob := NewOrderbook("MKT")
+	if err := ob.UpdateFromKalshi(resp, 0); err != nil {
+		t.Fatalf("zero-quantity levels rejected the book: %v", err)
+	}
+
+	for _, level := range append(append([]PriceLevel(nil), ob.Bids...), ob.Asks...) {
+		if level.Quantity == 0 {
+			t.Errorf("zero-quantity level %+v stored", level)
+		}
+	}
+	if len(ob.Bids) != 2 || ob.Bids[0].Price != 45 {
+		t.Errorf("bids = %+v, want best bid 45¢ with the 46¢ removal dropped", ob.Bids)
+	}
+	if len(ob.Asks) != 1 || ob.Asks[0].Price != 48 {
+		t.Errorf("asks = %+v, want best ask 48¢ with the 47¢ removal dropped", ob.Asks)
+	}
+	// Depth is notional (cents), summed over real levels only
+	if bid, ask := ob.BidDepth(), ob.AskDepth(); bid != 44*200+45*100 || ask != 48*150 {
+		t.Errorf("depth = %d / %d, want %d / %d", bid, ask, 44*200+45*100, 48*150)
+	}
+
+	// A book of nothing but removals is empty, not invalid
+	empty := &KalshiOrderbookResponse{OrderbookFp: KalshiOrderbookFp{
+		YesDollars: [][]string{{"0.45", "0.00"}},
+		NoDollars:  [][]string{{"0.53", "0.00"}},
+	}}
+	if err := ob.UpdateFromKalshi(empty, 0); err != nil {
+		t.Fatalf("all-zero book rejected: %v", err)
+	}
+	if ob.State() != BookEmpty {
+		t.Errorf("all-zero book state = %s, want empty", ob.State())
+	}
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Pinned markets are always polled and tracked regardless of status.
+// The set is persisted to a small JSON file so pins survive restarts.
+
+// LoadPinnedMarkets reads the pinned set from path and remembers path for later saves.
+// A missing file is not an error.
+func (e *Engine) LoadPinnedMarkets(path string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pinPath = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read pinned markets: %w", err)
+	}
+
+	var tickers []string
+	if err := json.Unmarshal(data, &tickers); err != nil {
+		return fmt.Errorf("failed to parse pinned markets: %w", err)
+	}
+
+	for _, ticker := range tickers {
+		e.pinned[ticker] = true
+	}
+	return nil
+}
+
+func (e *Engine) PinMarket(ticker string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pinned[ticker] = true
+	return e.savePinnedLocked()
+}
+
+func (e *Engine) UnpinMarket(ticker string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.pinned, ticker)
+	return e.savePinnedLocked()
+}
+
+func (e *Engine) IsPinned(ticker string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.pinned[ticker]
+}
+
+// PinnedMarkets returns pinned tickers in sorted order
+func (e *Engine) PinnedMarkets() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.pinnedLocked()
+}
+
+func (e *Engine) pinnedLocked() []string {
+	tickers := make([]string, 0, len(e.pinned))
+	for ticker := range e.pinned {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+func (e *Engine) savePinnedLocked() error {
+	if e.pinPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e.pinnedLocked())
+	if err != nil {
+		return fmt.Errorf("failed to marshal pinned markets: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash can't leave a truncated file
+	tmp := e.pinPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pinned markets: %w", err)
+	}
+	return os.Rename(tmp, e.pinPath)
+}
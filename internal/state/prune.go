@@ -0,0 +1,52 @@
+package state
+
+import "time"
+
+// PruneInactiveMarkets forgets markets that haven't been active since before
+// cutoff, together with their books, trades, history and caches, so a
+// long-running feed doesn't hold every market that ever closed. Pinned markets
+// are retained whatever their status, and recorded resolutions are kept for
+// calibration. It returns how many markets were removed.
+func (e *Engine) PruneInactiveMarkets(cutoff time.Time) int {
+	e.mu.Lock()
+	var pruned []string
+	for ticker, market := range e.markets {
+		if market.Status == StatusActive || e.pinned[ticker] || !e.lastActive[ticker].Before(cutoff) {
+			continue
+		}
+		delete(e.markets, ticker)
+		delete(e.orderbooks, ticker)
+		delete(e.booksFetched, ticker)
+		delete(e.tradeLogs, ticker)
+		delete(e.metadata, ticker)
+		delete(e.quotes, ticker)
+		delete(e.firstSeen, ticker)
+		delete(e.bookUpdates, ticker)
+		delete(e.lastActive, ticker)
+		delete(e.bookTops, ticker)
+		delete(e.topChanges, ticker)
+		pruned = append(pruned, ticker)
+	}
+	e.mu.Unlock()
+
+	e.timeSeries.removeMarkets(pruned)
+	return len(pruned)
+}
+
+// removeMarkets drops all recorded history of the given markets
+func (ts *TimeSeriesStore) removeMarkets(tickers []string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for _, ticker := range tickers {
+		delete(ts.snapshots, ticker)
+		delete(ts.midStats, ticker)
+		delete(ts.driftBaselines, ticker)
+		delete(ts.trades, ticker)
+		delete(ts.signals, ticker)
+		delete(ts.quant, ticker)
+		delete(ts.volume, ticker)
+		delete(ts.minuteBars, ticker)
+		delete(ts.hourBars, ticker)
+	}
+}
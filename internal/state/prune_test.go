@@ -0,0 +1,39 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneInactiveMarketsKeepsPinned(t *testing.T) {
+	e := NewEngine()
+	for _, ticker := range []string{"ACTIVE", "CLOSED", "PINNED"} {
+		e.RegisterMarket(&Market{Ticker: ticker, Status: StatusActive})
+		e.AddTrade(&Trade{MarketTicker: ticker, Price: 50, Quantity: 1, Timestamp: time.Now()})
+	}
+	e.RegisterMarket(&Market{Ticker: "CLOSED", Status: StatusClosed})
+	e.RegisterMarket(&Market{Ticker: "PINNED", Status: StatusClosed})
+	if err := e.PinMarket("PINNED"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing has been inactive for long yet
+	if pruned := e.PruneInactiveMarkets(time.Now().Add(-time.Hour)); pruned != 0 {
+		t.Fatalf("pruned %d recently active markets", pruned)
+	}
+
+	if pruned := e.PruneInactiveMarkets(time.Now().Add(time.Hour)); pruned != 1 {
+		t.Fatalf("pruned %d markets, want 1", pruned)
+	}
+	if _, exists := e.GetMarket("CLOSED"); exists {
+		t.Error("closed market was not pruned")
+	}
+	if trades := e.GetRecentTrades("CLOSED", time.Hour); len(trades) != 0 {
+		t.Error("pruned market's trades were kept")
+	}
+	for _, ticker := range []string{"ACTIVE", "PINNED"} {
+		if _, exists := e.GetMarket(ticker); !exists {
+			t.Errorf("%s was pruned", ticker)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package state
+
+import "time"
+
+// QuantPoint is the chartable subset of a quantitative signal at a point in time
+type QuantPoint struct {
+	Timestamp        time.Time `json:"timestamp"`
+	EfficiencyScore  float64   `json:"efficiency_score"`
+	LiquidityScore   float64   `json:"liquidity_score"`
+	PriceVolatility  float64   `json:"price_volatility"`
+	ExpectedValue    float64   `json:"expected_value"`
+	CalibrationError float64   `json:"calibration_error"`
+	SharpeRatio      float64   `json:"sharpe_ratio"`
+	ZScore           float64   `json:"z_score"`
+	TrendStrength    float64   `json:"trend_strength"`
+}
+
+// sameValues reports whether two points carry identical metrics, ignoring time
+func (q QuantPoint) sameValues(other QuantPoint) bool {
+	q.Timestamp = other.Timestamp
+	return q == other
+}
+
+// RecordQuant records a quant point, skipping it when nothing changed since the
+// last recorded point so an idle market doesn't flood the history
+func (ts *TimeSeriesStore) RecordQuant(ticker string, point QuantPoint) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	points := ts.quant[ticker]
+	if len(points) > 0 && points[len(points)-1].sameValues(point) {
+		return
+	}
+
+	points = append(points, point)
+	if len(points) > ts.maxSignalsPerMarket {
+		points = points[len(points)-ts.maxSignalsPerMarket:]
+	}
+
+	ts.quant[ticker] = points
+}
+
+// GetQuantHistory returns quant points for a market at or after since
+func (ts *TimeSeriesStore) GetQuantHistory(ticker string, since time.Time) []QuantPoint {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var filtered []QuantPoint
+	for _, q := range ts.quant[ticker] {
+		if !q.Timestamp.Before(since) {
+			filtered = append(filtered, q)
+		}
+	}
+
+	return filtered
+}
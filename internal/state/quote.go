@@ -0,0 +1,129 @@
+package state
+
+import "time"
+
+// Quote is the lightweight top-of-book price cache fed by the WebSocket ticker
+// channel. It is kept separate from the full orderbook, which is only as fresh
+// as the last orderbook poll or snapshot.
+type Quote struct {
+	LastPrice    int       `json:"last_price"` // cents
+	YesBid       int       `json:"yes_bid"`    // cents
+	YesAsk       int       `json:"yes_ask"`    // cents
+	Volume       int64     `json:"volume"`     // cumulative contracts traded
+	OpenInterest int64     `json:"open_interest"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// QuoteUpdate carries the fields present in one ticker message; nil fields are
+// left unchanged, since ticker_v2 messages only include what changed
+type QuoteUpdate struct {
+	LastPrice    *int
+	YesBid       *int
+	YesAsk       *int
+	Volume       *int64 // cumulative
+	VolumeDelta  *int64 // contracts traded since the previous message
+	OpenInterest *int64
+	Timestamp    time.Time
+}
+
+// UpdateQuote merges a ticker update into the market's quote and records any
+// traded volume in the time-series store. A moved bid or ask counts as a
+// top-of-book change for flicker detection.
+func (e *Engine) UpdateQuote(ticker string, update QuoteUpdate) {
+	e.mu.Lock()
+	quote, exists := e.quotes[ticker]
+	if !exists {
+		quote = &Quote{}
+		e.quotes[ticker] = quote
+	}
+
+	bidMoved := update.YesBid != nil && *update.YesBid != quote.YesBid
+	askMoved := update.YesAsk != nil && *update.YesAsk != quote.YesAsk
+	if exists && (bidMoved || askMoved) {
+		e.recordTopOfBookChange(ticker, time.Now())
+	}
+
+	if update.LastPrice != nil {
+		quote.LastPrice = *update.LastPrice
+	}
+	if update.YesBid != nil {
+		quote.YesBid = *update.YesBid
+	}
+	if update.YesAsk != nil {
+		quote.YesAsk = *update.YesAsk
+	}
+	if update.OpenInterest != nil {
+		quote.OpenInterest = *update.OpenInterest
+	}
+
+	var traded int64
+	switch {
+	case update.VolumeDelta != nil:
+		traded = *update.VolumeDelta
+		quote.Volume += traded
+	case update.Volume != nil:
+		// The first cumulative reading only establishes the baseline
+		if exists {
+			traded = *update.Volume - quote.Volume
+		}
+		quote.Volume = *update.Volume
+	}
+
+	quote.UpdatedAt = update.Timestamp
+	if quote.UpdatedAt.IsZero() {
+		quote.UpdatedAt = time.Now().UTC()
+	}
+	updatedAt := quote.UpdatedAt
+	e.mu.Unlock()
+
+	if traded > 0 {
+		e.timeSeries.RecordVolume(ticker, updatedAt, traded)
+	}
+}
+
+// GetQuote returns a copy of the market's cached quote
+func (e *Engine) GetQuote(ticker string) (*Quote, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	quote, exists := e.quotes[ticker]
+	if !exists {
+		return nil, false
+	}
+	c := *quote
+	return &c, true
+}
+
+// VolumePoint is contracts traded in one ticker-channel interval
+type VolumePoint struct {
+	Timestamp time.Time
+	Contracts int64
+}
+
+// RecordVolume records traded volume reported by the ticker channel
+func (ts *TimeSeriesStore) RecordVolume(ticker string, at time.Time, contracts int64) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	points := append(ts.volume[ticker], VolumePoint{Timestamp: at, Contracts: contracts})
+	if len(points) > ts.maxTradesPerMarket {
+		points = points[len(points)-ts.maxTradesPerMarket:]
+	}
+	ts.volume[ticker] = points
+}
+
+// GetTickerVolume returns contracts traded since the given time according to
+// the ticker channel, which covers markets without trade-channel data, and the
+// number of ticker messages that reported it
+func (ts *TimeSeriesStore) GetTickerVolume(ticker string, since time.Time) (contracts int64, samples int) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	for _, point := range ts.volume[ticker] {
+		if !point.Timestamp.Before(since) {
+			contracts += point.Contracts
+			samples++
+		}
+	}
+	return contracts, samples
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReferencePrice is the policy for reducing a market to a single fair price.
+// It sets the current value implied-probability drift measures, and each
+// sample of the snapshot drift baseline. The other prices in the system keep
+// fixed definitions:
+//
+//   - MarketSnapshot.MidPrice is always the plain mid. The OHLC candles,
+//     volatility, price change and backtest moves are built from it, so
+//     recorded history stays comparable when the policy changes.
+//   - The scanner's MidPrice and Microprice fields report exactly what they're
+//     named for, and MicropriceDiff is the gap between the two.
+//   - The scanner's FairValue is its own microprice/VWAP blend, tuned by the
+//     fair_value settings rather than this policy.
+//   - The trade drift baseline is made of trade prices by definition; only
+//     the current value it's compared with follows the policy.
+//   - Expiry alerts report the best bid, best ask, mid and last trade side by
+//     side as inputs, since a book near settlement is often one-sided.
+type ReferencePrice string
+
+const (
+	// ReferenceMid is the midpoint of best bid and best ask
+	ReferenceMid ReferencePrice = "mid"
+	// ReferenceMicroprice weights best bid and ask by the opposite side's depth
+	ReferenceMicroprice ReferencePrice = "microprice"
+	// ReferenceLastTrade is the most recent trade price
+	ReferenceLastTrade ReferencePrice = "last"
+)
+
+// referenceTradeWindow is how recent a trade must be to serve as the last-trade
+// reference, matching the trades attached to each snapshot
+const referenceTradeWindow = 5 * time.Minute
+
+// ParseReferencePrice validates a configured policy; "" is the mid
+func ParseReferencePrice(s string) (ReferencePrice, error) {
+	switch ReferencePrice(s) {
+	case "":
+		return ReferenceMid, nil
+	case ReferenceMid, ReferenceMicroprice, ReferenceLastTrade:
+		return ReferencePrice(s), nil
+	}
+	return "", fmt.Errorf("unknown reference price %q (want mid, microprice or last)", s)
+}
+
+// ReferenceProb returns a market's reference price as a probability (0-1)
+// under policy. Mid and microprice need a two-sided book; microprice weighs
+// micropriceLevels per side. The last-trade policy needs lastTrade, and false
+// is returned when the inputs are missing.
+func ReferenceProb(policy ReferencePrice, ob *Orderbook, lastTrade *Trade, micropriceLevels int) (float64, bool) {
+	switch policy {
+	case ReferenceMicroprice:
+		return ob.MicropriceDepth(micropriceLevels)
+	case ReferenceLastTrade:
+		if lastTrade == nil {
+			return 0, false
+		}
+		return float64(lastTrade.Price) / 100.0, true
+	default:
+		if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+			return 0, false
+		}
+		return float64(ob.Bids[0].Price+ob.Asks[0].Price) / 200.0, true
+	}
+}
+
+// SetReferencePrice sets the policy for the drift baseline's samples and for
+// ReferencePrice; micropriceLevels is used by the microprice policy
+func (e *Engine) SetReferencePrice(policy ReferencePrice, micropriceLevels int) {
+	ts := e.GetTimeSeries()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.referencePrice = policy
+	ts.micropriceLevels = micropriceLevels
+}
+
+// ReferencePrice returns the market's current reference price (0-1) from ob
+// under the configured policy, or false if it can't be determined
+func (e *Engine) ReferencePrice(ticker string, ob *Orderbook) (float64, bool) {
+	ts := e.GetTimeSeries()
+	ts.mu.RLock()
+	policy, levels := ts.referencePrice, ts.micropriceLevels
+	ts.mu.RUnlock()
+
+	var lastTrade *Trade
+	if policy == ReferenceLastTrade {
+		if trades := e.GetRecentTrades(ticker, referenceTradeWindow); len(trades) > 0 {
+			lastTrade = trades[len(trades)-1]
+		}
+	}
+	return ReferenceProb(policy, ob, lastTrade, levels)
+}
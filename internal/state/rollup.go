@@ -0,0 +1,261 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// Bar aggregates the mid-price snapshots and trades of one interval
+type Bar struct {
+	Start   time.Time `json:"start"`
+	Open    float64   `json:"open"` // mid, probability (0-1)
+	High    float64   `json:"high"`
+	Low     float64   `json:"low"`
+	Close   float64   `json:"close"`
+	Volume  int64     `json:"volume"`  // contracts traded
+	Samples int       `json:"samples"` // snapshots aggregated
+}
+
+// merge folds a later bar for the same interval into b
+func (b *Bar) merge(later Bar) {
+	if later.High > b.High {
+		b.High = later.High
+	}
+	if later.Low < b.Low {
+		b.Low = later.Low
+	}
+	b.Close = later.Close
+	b.Volume += later.Volume
+	b.Samples += later.Samples
+}
+
+// appendBar adds bar to a time-ordered series, merging it into the last bar
+// when both cover the same interval (an interval can be compacted in pieces)
+func appendBar(bars []Bar, bar Bar) []Bar {
+	if n := len(bars); n > 0 && bars[n-1].Start.Equal(bar.Start) {
+		bars[n-1].merge(bar)
+		return bars
+	}
+	return append(bars, bar)
+}
+
+// compact moves a market's aged-out history down the retention tiers: full
+// resolution snapshots older than fullResolutionWindow (or beyond the snapshot
+// cap) become minute bars, and minute bars older than minuteBarWindow become
+// hour bars. Caller holds ts.mu.
+func (ts *TimeSeriesStore) compact(ticker string, now time.Time) {
+	snapshots := ts.snapshots[ticker]
+	cutoff := now.Add(-ts.fullResolutionWindow)
+
+	evicted := 0
+	if len(snapshots) > ts.maxSnapshotsPerMarket {
+		evicted = len(snapshots) - ts.maxSnapshotsPerMarket
+	}
+	for evicted < len(snapshots) && snapshots[evicted].Timestamp.Before(cutoff) {
+		evicted++
+	}
+
+	if evicted > 0 {
+		stats := ts.midStats[ticker]
+		minuteBars := ts.minuteBars[ticker]
+		for _, old := range snapshots[:evicted] {
+			if stats != nil {
+				stats.remove(old.MidPrice)
+			}
+			minuteBars = appendBar(minuteBars, ts.snapshotBar(ticker, old, minuteBars))
+		}
+		ts.snapshots[ticker] = snapshots[evicted:]
+		ts.minuteBars[ticker] = minuteBars
+	}
+
+	minuteBars := ts.minuteBars[ticker]
+	minuteCutoff := now.Add(-ts.minuteBarWindow)
+	rolled := 0
+	hourBars := ts.hourBars[ticker]
+	for rolled < len(minuteBars) && minuteBars[rolled].Start.Before(minuteCutoff) {
+		bar := minuteBars[rolled]
+		bar.Start = bar.Start.Truncate(time.Hour)
+		hourBars = appendBar(hourBars, bar)
+		rolled++
+	}
+	if rolled == 0 {
+		return
+	}
+	ts.minuteBars[ticker] = minuteBars[rolled:]
+	if len(hourBars) > ts.maxHourBarsPerMarket {
+		hourBars = hourBars[len(hourBars)-ts.maxHourBarsPerMarket:]
+	}
+	ts.hourBars[ticker] = hourBars
+}
+
+// snapshotBar makes a single-sample minute bar for snap. The first snapshot of
+// a minute carries that minute's traded volume; later ones merge into the bar
+// already in bars with none, so volume is counted once.
+func (ts *TimeSeriesStore) snapshotBar(ticker string, snap MarketSnapshot, bars []Bar) Bar {
+	start := snap.Timestamp.Truncate(time.Minute)
+	bar := Bar{
+		Start:   start,
+		Open:    snap.MidPrice,
+		High:    snap.MidPrice,
+		Low:     snap.MidPrice,
+		Close:   snap.MidPrice,
+		Samples: 1,
+	}
+
+	if n := len(bars); n == 0 || !bars[n-1].Start.Equal(start) {
+		bar.Volume = ts.tradeVolume(ticker, start, start.Add(time.Minute))
+	}
+	return bar
+}
+
+// tradeVolume sums the quantity of retained trades in [from, to). Caller holds ts.mu.
+func (ts *TimeSeriesStore) tradeVolume(ticker string, from, to time.Time) int64 {
+	trades := ts.trades[ticker]
+	i := sort.Search(len(trades), func(i int) bool {
+		return !trades[i].Timestamp.Before(from)
+	})
+
+	var volume int64
+	for ; i < len(trades) && trades[i].Timestamp.Before(to); i++ {
+		volume += int64(trades[i].Quantity)
+	}
+	return volume
+}
+
+// MidPoint is a market's mid price at one point in its history
+type MidPoint struct {
+	Timestamp time.Time
+	Mid       float64 // probability (0-1)
+}
+
+// GetMidHistory returns a market's mid price history at or after since, oldest
+// first, at the finest resolution still retained: one close per hour or minute
+// bar for compacted history, stamped at the end of the bar, then every
+// full-resolution snapshot.
+func (ts *TimeSeriesStore) GetMidHistory(ticker string, since time.Time) []MidPoint {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var points []MidPoint
+	addBars := func(bars []Bar, size time.Duration) {
+		for _, b := range bars {
+			if end := b.Start.Add(size); !end.Before(since) {
+				points = append(points, MidPoint{Timestamp: end, Mid: b.Close})
+			}
+		}
+	}
+	addBars(ts.hourBars[ticker], time.Hour)
+	addBars(ts.minuteBars[ticker], time.Minute)
+	for _, snap := range ts.snapshots[ticker] {
+		if !snap.Timestamp.Before(since) {
+			points = append(points, MidPoint{Timestamp: snap.Timestamp, Mid: snap.MidPrice})
+		}
+	}
+
+	// A bar can end after the next tier begins when its interval was only
+	// partly compacted; pull its stamp back so the series stays ordered
+	for i := len(points) - 2; i >= 0; i-- {
+		if points[i].Timestamp.After(points[i+1].Timestamp) {
+			points[i].Timestamp = points[i+1].Timestamp
+		}
+	}
+	return points
+}
+
+// GetCandles builds OHLC mid candles of the given interval from since to now,
+// drawing on compacted bars where full-resolution snapshots have aged out.
+// Hour bars are used only when interval is a whole number of hours. Intervals
+// with no snapshots after the first candle are filled flat at the previous
+// close with Samples 0, so the series has no gaps.
+func (ts *TimeSeriesStore) GetCandles(ticker string, since time.Time, interval time.Duration) []Bar {
+	if interval < time.Minute {
+		return nil
+	}
+
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	first := since.Truncate(interval)
+	var candles []Bar
+	add := func(b Bar) {
+		if !b.Start.Before(first) {
+			candles = appendBar(candles, b)
+		}
+	}
+
+	if interval%time.Hour == 0 {
+		for _, b := range ts.hourBars[ticker] {
+			b.Start = b.Start.Truncate(interval)
+			add(b)
+		}
+	}
+
+	// Trades before the end of the last minute bar are already in bar volumes
+	var counted time.Time
+	for _, b := range ts.minuteBars[ticker] {
+		counted = b.Start.Add(time.Minute)
+		b.Start = b.Start.Truncate(interval)
+		add(b)
+	}
+
+	var lastStart time.Time
+	for _, snap := range ts.snapshots[ticker] {
+		start := snap.Timestamp.Truncate(interval)
+		bar := Bar{
+			Start:   start,
+			Open:    snap.MidPrice,
+			High:    snap.MidPrice,
+			Low:     snap.MidPrice,
+			Close:   snap.MidPrice,
+			Samples: 1,
+		}
+		if !start.Equal(lastStart) {
+			bar.Volume = ts.uncountedVolume(ticker, start, interval, counted)
+			lastStart = start
+		}
+		add(bar)
+	}
+
+	return ts.fillGaps(ticker, candles, interval, counted)
+}
+
+// uncountedVolume is the trade volume in the interval starting at start that
+// isn't already included in a compacted bar. Caller holds ts.mu.
+func (ts *TimeSeriesStore) uncountedVolume(ticker string, start time.Time, interval time.Duration, counted time.Time) int64 {
+	from := start
+	if counted.After(from) {
+		from = counted
+	}
+	end := start.Add(interval)
+	if !from.Before(end) {
+		return 0
+	}
+	return ts.tradeVolume(ticker, from, end)
+}
+
+// fillGaps inserts flat candles at the previous close for intervals without
+// snapshots, keeping any trade volume in them. Caller holds ts.mu.
+func (ts *TimeSeriesStore) fillGaps(ticker string, candles []Bar, interval time.Duration, counted time.Time) []Bar {
+	if len(candles) < 2 {
+		return candles
+	}
+
+	filled := make([]Bar, 0, len(candles))
+	for i, c := range candles {
+		if i > 0 {
+			prev := filled[len(filled)-1]
+			for start := prev.Start.Add(interval); start.Before(c.Start); start = start.Add(interval) {
+				filled = append(filled, Bar{
+					Start:  start,
+					Open:   prev.Close,
+					High:   prev.Close,
+					Low:    prev.Close,
+					Close:  prev.Close,
+					Volume: ts.uncountedVolume(ticker, start, interval, counted),
+				})
+			}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
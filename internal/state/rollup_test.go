@@ -0,0 +1,138 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompactRollsSnapshotsIntoCoarserBars(t *testing.T) {
+	ts := NewTimeSeriesStore()
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	dayOld := now.Add(-26 * time.Hour)
+	hourOld := now.Add(-2 * time.Hour)
+
+	snap := func(at time.Time, mid float64) MarketSnapshot {
+		return MarketSnapshot{Timestamp: at, MarketTicker: "MKT", MidPrice: mid}
+	}
+	ts.snapshots["MKT"] = []MarketSnapshot{
+		// One minute, more than a day old: ends up in an hour bar
+		snap(dayOld, 0.40),
+		snap(dayOld.Add(20*time.Second), 0.45),
+		snap(dayOld.Add(40*time.Second), 0.42),
+		// One minute, two hours old: ends up in a minute bar
+		snap(hourOld, 0.50),
+		snap(hourOld.Add(15*time.Second), 0.55),
+		snap(hourOld.Add(30*time.Second), 0.48),
+		snap(hourOld.Add(45*time.Second), 0.52),
+		// Recent: stays at full resolution
+		snap(now.Add(-10*time.Minute), 0.60),
+	}
+	trade := func(at time.Time, qty int) *Trade {
+		return &Trade{MarketTicker: "MKT", Price: 50, Quantity: qty, Timestamp: at}
+	}
+	ts.trades["MKT"] = []*Trade{
+		trade(dayOld.Add(5*time.Second), 3),
+		trade(hourOld.Add(10*time.Second), 5),
+		trade(hourOld.Add(50*time.Second), 7),
+		trade(hourOld.Add(70*time.Second), 100), // the next minute, which has no snapshots
+	}
+
+	ts.compact("MKT", now)
+
+	wantHour := Bar{Start: dayOld, Open: 0.40, High: 0.45, Low: 0.40, Close: 0.42, Volume: 3, Samples: 3}
+	if got := ts.hourBars["MKT"]; len(got) != 1 || got[0] != wantHour {
+		t.Errorf("hour bars = %+v, want [%+v]", got, wantHour)
+	}
+	wantMinute := Bar{Start: hourOld, Open: 0.50, High: 0.55, Low: 0.48, Close: 0.52, Volume: 12, Samples: 4}
+	if got := ts.minuteBars["MKT"]; len(got) != 1 || got[0] != wantMinute {
+		t.Errorf("minute bars = %+v, want [%+v]", got, wantMinute)
+	}
+	if got := ts.snapshots["MKT"]; len(got) != 1 || got[0].MidPrice != 0.60 {
+		t.Errorf("snapshots left = %+v, want only the recent one", got)
+	}
+
+	// Mid history reaches back through every tier, one close per bar
+	history := ts.GetMidHistory("MKT", time.Time{})
+	want := []MidPoint{
+		{Timestamp: dayOld.Add(time.Hour), Mid: 0.42},
+		{Timestamp: hourOld.Add(time.Minute), Mid: 0.52},
+		{Timestamp: now.Add(-10 * time.Minute), Mid: 0.60},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("mid history = %+v, want %+v", history, want)
+	}
+	for i := range want {
+		if !history[i].Timestamp.Equal(want[i].Timestamp) || history[i].Mid != want[i].Mid {
+			t.Errorf("mid history[%d] = %+v, want %+v", i, history[i], want[i])
+		}
+	}
+}
+
+func TestCompactMergesAnIntervalCompactedInPieces(t *testing.T) {
+	ts := NewTimeSeriesStore()
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	minute := now.Add(-2 * time.Hour)
+
+	ts.snapshots["MKT"] = []MarketSnapshot{{Timestamp: minute, MidPrice: 0.30}}
+	ts.trades["MKT"] = []*Trade{{MarketTicker: "MKT", Quantity: 4, Timestamp: minute.Add(time.Second)}}
+	ts.compact("MKT", now)
+
+	ts.snapshots["MKT"] = []MarketSnapshot{{Timestamp: minute.Add(30 * time.Second), MidPrice: 0.20}}
+	ts.compact("MKT", now)
+
+	want := Bar{Start: minute, Open: 0.30, High: 0.30, Low: 0.20, Close: 0.20, Volume: 4, Samples: 2}
+	if got := ts.minuteBars["MKT"]; len(got) != 1 || got[0] != want {
+		t.Errorf("minute bars = %+v, want [%+v] with volume counted once", got, want)
+	}
+}
+
+func TestCandlesFromKnownSeries(t *testing.T) {
+	ts := NewTimeSeriesStore()
+	at := func(hhmm string) time.Time {
+		clock, _ := time.Parse("15:04:05", hhmm)
+		return time.Date(2025, 3, 14, clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC)
+	}
+
+	// The first minute has already been compacted into a bar, trades included
+	ts.minuteBars["MKT"] = []Bar{{Start: at("14:00:00"), Open: 0.41, High: 0.41, Low: 0.41, Close: 0.41, Volume: 2, Samples: 1}}
+	for _, s := range []struct {
+		at  string
+		mid float64
+	}{
+		{"14:01:00", 0.40}, {"14:03:00", 0.46}, {"14:04:00", 0.38},
+		{"14:06:00", 0.50}, {"14:09:00", 0.52},
+		// nothing from 14:10 to 14:15
+		{"14:17:00", 0.55},
+	} {
+		ts.snapshots["MKT"] = append(ts.snapshots["MKT"], MarketSnapshot{Timestamp: at(s.at), MidPrice: s.mid})
+	}
+	for _, tr := range []struct {
+		at  string
+		qty int
+	}{
+		{"14:00:30", 2}, // already in the minute bar's volume
+		{"14:02:00", 10}, {"14:08:00", 4}, {"14:12:00", 6}, {"14:16:00", 1},
+	} {
+		ts.trades["MKT"] = append(ts.trades["MKT"], &Trade{MarketTicker: "MKT", Quantity: tr.qty, Timestamp: at(tr.at)})
+	}
+
+	got := ts.GetCandles("MKT", at("13:57:00"), 5*time.Minute)
+	want := []Bar{
+		{Start: at("14:00:00"), Open: 0.41, High: 0.46, Low: 0.38, Close: 0.38, Volume: 12, Samples: 4},
+		{Start: at("14:05:00"), Open: 0.50, High: 0.52, Low: 0.50, Close: 0.52, Volume: 4, Samples: 2},
+		{Start: at("14:10:00"), Open: 0.52, High: 0.52, Low: 0.52, Close: 0.52, Volume: 6, Samples: 0}, // gap
+		{Start: at("14:15:00"), Open: 0.55, High: 0.55, Low: 0.55, Close: 0.55, Volume: 1, Samples: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("candles = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("candle %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if candles := ts.GetCandles("MKT", at("13:57:00"), 30*time.Second); candles != nil {
+		t.Errorf("sub-minute interval gave %d candles, want none", len(candles))
+	}
+}
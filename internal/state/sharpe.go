@@ -0,0 +1,76 @@
+package state
+
+import "time"
+
+// SharpePoint is one step of a rolling return/Sharpe series
+type SharpePoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Return    float64   `json:"return"` // change in mid over the period, probability points
+	Sharpe    float64   `json:"sharpe"` // mean / stddev of the trailing window's returns (not annualized)
+}
+
+// GetRollingSharpe resamples a market's mid price into closes every period,
+// takes period-over-period returns, and computes a Sharpe ratio over each
+// trailing window of returns. History older than the full-resolution window
+// comes from compacted bar closes. Periods without data are skipped, so a
+// return can span a gap. It returns nil until there are window+1 closes.
+func (ts *TimeSeriesStore) GetRollingSharpe(ticker string, since time.Time, period time.Duration, window int) []SharpePoint {
+	if period <= 0 || window < 2 {
+		return nil
+	}
+
+	history := ts.GetMidHistory(ticker, since)
+	if len(history) == 0 {
+		return nil
+	}
+
+	// Last mid in each period
+	var closes []MidPoint
+	lastBucket := int64(-1)
+	for _, point := range history {
+		bucket := int64(point.Timestamp.Sub(since) / period)
+		if bucket == lastBucket {
+			closes[len(closes)-1] = point
+			continue
+		}
+		closes = append(closes, point)
+		lastBucket = bucket
+	}
+
+	if len(closes) < window+1 {
+		return nil
+	}
+
+	times := make([]time.Time, 0, len(closes)-1)
+	returns := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		times = append(times, closes[i].Timestamp)
+		returns = append(returns, closes[i].Mid-closes[i-1].Mid)
+	}
+
+	return rollingSharpe(times, returns, window)
+}
+
+// rollingSharpe emits one point per return once window returns are available
+func rollingSharpe(times []time.Time, returns []float64, window int) []SharpePoint {
+	var stats runningStats
+	var series []SharpePoint
+
+	for i, r := range returns {
+		stats.add(r)
+		if i >= window {
+			stats.remove(returns[i-window])
+		}
+		if i < window-1 {
+			continue
+		}
+
+		point := SharpePoint{Timestamp: times[i], Return: r}
+		if sd := stats.stdDev(); sd > MinStdDev {
+			point.Sharpe = stats.mean / sd
+		}
+		series = append(series, point)
+	}
+
+	return series
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRollingSharpeKnownSeries(t *testing.T) {
+	// Returns alternate 1 and 3 points: every even window has mean 2 and
+	// population stddev 1, so a Sharpe of exactly 2
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	var times []time.Time
+	var returns []float64
+	for i := 0; i < 12; i++ {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+		returns = append(returns, 0.01+0.02*float64(i%2))
+	}
+
+	series := rollingSharpe(times, returns, 4)
+	if len(series) != len(returns)-3 {
+		t.Fatalf("got %d points, want one per return from the 4th: %d", len(series), len(returns)-3)
+	}
+	for _, point := range series {
+		if math.Abs(point.Sharpe-2) > 1e-9 {
+			t.Errorf("%s: sharpe = %.6f, want 2", point.Timestamp.Format(time.TimeOnly), point.Sharpe)
+		}
+	}
+	if !series[0].Timestamp.Equal(times[3]) || series[0].Return != returns[3] {
+		t.Errorf("first point %+v, want the 4th return", series[0])
+	}
+
+	// Flat returns have no volatility and report 0 rather than dividing by it
+	for _, point := range rollingSharpe(times, make([]float64, len(times)), 4) {
+		if point.Sharpe != 0 {
+			t.Errorf("flat series sharpe = %f, want 0", point.Sharpe)
+		}
+	}
+}
+
+func TestRollingSharpeInsufficientData(t *testing.T) {
+	ts := NewTimeSeriesStore()
+	since := time.Now().Add(-time.Hour)
+	if got := ts.GetRollingSharpe("MKT", since, time.Minute, 4); got != nil {
+		t.Errorf("no history: got %v, want nil", got)
+	}
+	if got := ts.GetRollingSharpe("MKT", since, time.Minute, 1); got != nil {
+		t.Errorf("window 1: got %v, want nil", got)
+	}
+
+	// Four closes make three returns, one short of a window of four
+	for i := 0; i < 4; i++ {
+		ts.minuteBars["MKT"] = append(ts.minuteBars["MKT"], Bar{Start: since.Add(time.Duration(i) * time.Minute).Truncate(time.Minute), Close: 0.5})
+	}
+	if got := ts.GetRollingSharpe("MKT", since.Add(-time.Minute), time.Minute, 4); got != nil {
+		t.Errorf("three returns: got %v, want nil", got)
+	}
+}
+
+func TestRollingSharpeFromCompactedHistory(t *testing.T) {
+	// Two hours of minute bars, all past the full-resolution window, with
+	// closes stepping up 1 then 3 points alternately
+	ts := NewTimeSeriesStore()
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	mid := 0.2
+	for i := 0; i < 120; i++ {
+		mid += 0.01 + 0.02*float64(i%2)
+		ts.minuteBars["MKT"] = append(ts.minuteBars["MKT"], Bar{Start: start.Add(time.Duration(i) * time.Minute), Close: mid, Samples: 1})
+	}
+
+	series := ts.GetRollingSharpe("MKT", start, time.Minute, 4)
+	if len(series) == 0 {
+		t.Fatal("no rolling Sharpe from compacted history")
+	}
+	for _, point := range series {
+		if math.Abs(point.Sharpe-2) > 1e-6 {
+			t.Fatalf("%s: sharpe = %.6f, want 2", point.Timestamp.Format(time.TimeOnly), point.Sharpe)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"math"
 	"sync"
 	"time"
 )
@@ -35,16 +36,39 @@ type TimeSeriesStore struct {
 	// Market snapshots (top-of-book + depth + imbalance)
 	snapshots map[string][]MarketSnapshot // market_ticker -> []snapshot
 
+	// Running mid-price stats over all retained snapshots
+	midStats map[string]*runningStats // market_ticker -> stats
+
+	// Reference prices of the snapshots within the drift window, the baseline
+	// drift is measured against
+	driftBaselines map[string]*midWindow // market_ticker -> window
+
 	// Trade history
 	trades map[string][]*Trade // market_ticker -> []trade
 
 	// Signal history
 	signals map[string][]SignalPoint // market_ticker -> []signal
 
+	// Quant metric history
+	quant map[string][]QuantPoint // market_ticker -> []quant
+
+	// Volume reported by the ticker channel
+	volume map[string][]VolumePoint // market_ticker -> []volume
+
+	// Compacted snapshot history: minute bars for the last day, hour bars beyond
+	minuteBars map[string][]Bar // market_ticker -> []bar
+	hourBars   map[string][]Bar // market_ticker -> []bar
+
 	// Configuration
 	maxSnapshotsPerMarket int
 	maxTradesPerMarket    int
 	maxSignalsPerMarket   int
+	maxHourBarsPerMarket  int
+	fullResolutionWindow  time.Duration // snapshots older than this become minute bars
+	minuteBarWindow       time.Duration // minute bars older than this become hour bars
+	driftWindow           time.Duration
+	referencePrice        ReferencePrice // drift baseline samples; see SetReferencePrice
+	micropriceLevels      int
 }
 
 type SignalPoint struct {
@@ -57,11 +81,23 @@ type SignalPoint struct {
 func NewTimeSeriesStore() *TimeSeriesStore {
 	return &TimeSeriesStore{
 		snapshots:             make(map[string][]MarketSnapshot),
+		midStats:              make(map[string]*runningStats),
+		driftBaselines:        make(map[string]*midWindow),
 		trades:                make(map[string][]*Trade),
 		signals:               make(map[string][]SignalPoint),
+		quant:                 make(map[string][]QuantPoint),
+		volume:                make(map[string][]VolumePoint),
+		minuteBars:            make(map[string][]Bar),
+		hourBars:              make(map[string][]Bar),
 		maxSnapshotsPerMarket: 10000, // ~2.7 hours at 1s intervals
 		maxTradesPerMarket:    10000,
 		maxSignalsPerMarket:   10000,
+		maxHourBarsPerMarket:  24 * 90,
+		fullResolutionWindow:  time.Hour,
+		minuteBarWindow:       24 * time.Hour,
+		driftWindow:           time.Minute,
+		referencePrice:        ReferenceMid,
+		micropriceLevels:      1,
 	}
 }
 
@@ -83,7 +119,7 @@ func (ts *TimeSeriesStore) RecordSnapshot(ticker string, orderbook *Orderbook, t
 	micropriceProb := microprice * 100.0 // Convert to percentage
 
 	snapshot := MarketSnapshot{
-		Timestamp:    time.Now(),
+		Timestamp:    time.Now().UTC(),
 		MarketTicker: ticker,
 		BestBid:      bestBid,
 		BestAsk:      bestAsk,
@@ -100,15 +136,20 @@ func (ts *TimeSeriesStore) RecordSnapshot(ticker string, orderbook *Orderbook, t
 		snapshot.LastTrade = trades[len(trades)-1]
 	}
 
-	snapshots := ts.snapshots[ticker]
-	snapshots = append(snapshots, snapshot)
+	stats, exists := ts.midStats[ticker]
+	if !exists {
+		stats = &runningStats{}
+		ts.midStats[ticker] = stats
+	}
 
-	// Keep only recent snapshots
-	if len(snapshots) > ts.maxSnapshotsPerMarket {
-		snapshots = snapshots[len(snapshots)-ts.maxSnapshotsPerMarket:]
+	ts.snapshots[ticker] = append(ts.snapshots[ticker], snapshot)
+	stats.add(snapshot.MidPrice)
+	if ref, ok := ReferenceProb(ts.referencePrice, orderbook, snapshot.LastTrade, ts.micropriceLevels); ok {
+		ts.recordDriftMid(ticker, snapshot.Timestamp, ref)
 	}
 
-	ts.snapshots[ticker] = snapshots
+	// Roll older snapshots into coarser bars
+	ts.compact(ticker, snapshot.Timestamp)
 }
 
 // RecordTrade records a trade
@@ -133,7 +174,7 @@ func (ts *TimeSeriesStore) RecordSignal(ticker string, signalType string, value
 
 	signals := ts.signals[ticker]
 	signals = append(signals, SignalPoint{
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 		Type:      signalType,
 		Value:     value,
 		Metadata:  metadata,
@@ -146,6 +187,21 @@ func (ts *TimeSeriesStore) RecordSignal(ticker string, signalType string, value
 	ts.signals[ticker] = signals
 }
 
+// GetSignals returns recorded signals for a market since the given time
+func (ts *TimeSeriesStore) GetSignals(ticker string, since time.Time) []SignalPoint {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var filtered []SignalPoint
+	for _, sig := range ts.signals[ticker] {
+		if !sig.Timestamp.Before(since) {
+			filtered = append(filtered, sig)
+		}
+	}
+
+	return filtered
+}
+
 // GetSnapshots returns snapshots for a market within a time window
 func (ts *TimeSeriesStore) GetSnapshots(ticker string, since time.Time) []MarketSnapshot {
 	ts.mu.RLock()
@@ -163,6 +219,14 @@ func (ts *TimeSeriesStore) GetSnapshots(ticker string, since time.Time) []Market
 	return filtered
 }
 
+// SnapshotCount returns how many snapshots are retained for a market
+func (ts *TimeSeriesStore) SnapshotCount(ticker string) int {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	return len(ts.snapshots[ticker])
+}
+
 // GetRecentSnapshots returns the N most recent snapshots
 func (ts *TimeSeriesStore) GetRecentSnapshots(ticker string, n int) []MarketSnapshot {
 	ts.mu.RLock()
@@ -193,9 +257,41 @@ func (ts *TimeSeriesStore) GetTrades(ticker string, since time.Time) []*Trade {
 	return filtered
 }
 
-// GetVolatility computes price volatility over a time window
+// GetVolatility computes price volatility over a time window.
+// When the window covers every retained snapshot it is answered in O(1) from
+// the running stats; shorter windows fall back to a batch computation.
 func (ts *TimeSeriesStore) GetVolatility(ticker string, window time.Duration) float64 {
 	since := time.Now().Add(-window)
+
+	ts.mu.RLock()
+	snapshots := ts.snapshots[ticker]
+	if len(snapshots) > 0 && !snapshots[0].Timestamp.Before(since) {
+		stats := ts.midStats[ticker]
+		ts.mu.RUnlock()
+		if stats == nil || len(snapshots) < 2 {
+			return 0
+		}
+		return stats.stdDev()
+	}
+	ts.mu.RUnlock()
+
+	return ts.windowedVolatility(ticker, since)
+}
+
+// GetFullVolatility returns the standard deviation of mid price across all retained snapshots
+func (ts *TimeSeriesStore) GetFullVolatility(ticker string) float64 {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	stats, exists := ts.midStats[ticker]
+	if !exists {
+		return 0
+	}
+	return stats.stdDev()
+}
+
+// windowedVolatility computes the standard deviation of mid price since a cutoff
+func (ts *TimeSeriesStore) windowedVolatility(ticker string, since time.Time) float64 {
 	snapshots := ts.GetSnapshots(ticker, since)
 
 	if len(snapshots) < 2 {
@@ -219,25 +315,19 @@ func (ts *TimeSeriesStore) GetVolatility(ticker string, window time.Duration) fl
 		variance += (p - mean) * (p - mean)
 	}
 	variance /= float64(len(prices))
-	stdDev := 0.0
-	if variance > 0 {
-		// Simple sqrt approximation
-		for i := 0; i < 10; i++ {
-			if stdDev == 0 {
-				stdDev = variance
-			} else {
-				stdDev = (stdDev + variance/stdDev) / 2
-			}
-		}
-	}
 
 	// Return standard deviation as percentage points
-	return stdDev
+	return math.Sqrt(variance)
 }
 
 // GetPriceChange computes price change over a time window
 func (ts *TimeSeriesStore) GetPriceChange(ticker string, window time.Duration) (float64, bool) {
-	since := time.Now().Add(-window)
+	return ts.GetPriceChangeAt(ticker, window, time.Now())
+}
+
+// GetPriceChangeAt computes price change over the window ending at now
+func (ts *TimeSeriesStore) GetPriceChangeAt(ticker string, window time.Duration, now time.Time) (float64, bool) {
+	since := now.Add(-window)
 	snapshots := ts.GetSnapshots(ticker, since)
 
 	if len(snapshots) < 2 {
@@ -5,6 +5,8 @@ import (
 	"time"
 )
 
+// TradeSide is the taker's side: SideYes lifted the YES offer, SideNo the
+// NO offer (selling YES). Empty when the feed didn't say.
 type TradeSide string
 
 const (
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"math"
+	"sort"
+)
+
+// TradeSizeStats summarises the sizes (contracts) of a set of trades, to tell
+// many small retail prints apart from a few large ones
+type TradeSizeStats struct {
+	Count          int     `json:"count"`
+	Mean           float64 `json:"mean"`
+	Median         float64 `json:"median"`
+	P95            float64 `json:"p95"`
+	LargeThreshold int     `json:"large_threshold"`
+	LargeTrades    int     `json:"large_trades"`
+}
+
+// TradeSizeDistribution computes size stats over trades. Trades of at least
+// largeThreshold contracts are counted as large (0 counts none). The median of
+// an even count averages the middle two; p95 is the nearest-rank percentile.
+func TradeSizeDistribution(trades []*Trade, largeThreshold int) TradeSizeStats {
+	stats := TradeSizeStats{LargeThreshold: largeThreshold}
+	if len(trades) == 0 {
+		return stats
+	}
+
+	sizes := make([]int, len(trades))
+	total := 0
+	for i, trade := range trades {
+		sizes[i] = trade.Quantity
+		total += trade.Quantity
+		if largeThreshold > 0 && trade.Quantity >= largeThreshold {
+			stats.LargeTrades++
+		}
+	}
+	sort.Ints(sizes)
+
+	n := len(sizes)
+	stats.Count = n
+	stats.Mean = float64(total) / float64(n)
+	if n%2 == 1 {
+		stats.Median = float64(sizes[n/2])
+	} else {
+		stats.Median = float64(sizes[n/2-1]+sizes[n/2]) / 2
+	}
+	rank := int(math.Ceil(0.95 * float64(n)))
+	stats.P95 = float64(sizes[rank-1])
+
+	return stats
+}
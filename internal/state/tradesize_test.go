@@ -0,0 +1,52 @@
+package state
+
+import "testing"
+
+func TestTradeSizeDistribution(t *testing.T) {
+	trades := func(sizes ...int) []*Trade {
+		out := make([]*Trade, len(sizes))
+		for i, size := range sizes {
+			out[i] = &Trade{MarketTicker: "MKT", Price: 50, Quantity: size}
+		}
+		return out
+	}
+
+	// Eighteen 5-lots of retail churn and two 500-lot blocks, out of order
+	var sizes []int
+	for i := 0; i < 18; i++ {
+		sizes = append(sizes, 5)
+	}
+	sizes = append([]int{500}, append(sizes, 500)...)
+
+	tests := []struct {
+		name      string
+		trades    []*Trade
+		threshold int
+		want      TradeSizeStats
+	}{
+		{"retail and blocks", trades(sizes...), 100,
+			TradeSizeStats{Count: 20, Mean: 54.5, Median: 5, P95: 500, LargeThreshold: 100, LargeTrades: 2}},
+		{"odd count", trades(100, 1, 2), 100,
+			TradeSizeStats{Count: 3, Mean: 103.0 / 3, Median: 2, P95: 100, LargeThreshold: 100, LargeTrades: 1}},
+		{"even count averages the middle two", trades(1, 2, 3, 10), 0,
+			TradeSizeStats{Count: 4, Mean: 4, Median: 2.5, P95: 10}},
+		// Nearest rank: ceil(0.95 * 40) = 38th smallest of 1..40
+		{"nearest-rank p95", trades(seq(1, 40)...), 39,
+			TradeSizeStats{Count: 40, Mean: 20.5, Median: 20.5, P95: 38, LargeThreshold: 39, LargeTrades: 2}},
+		{"no trades", nil, 100, TradeSizeStats{LargeThreshold: 100}},
+	}
+	for _, tt := range tests {
+		if got := TradeSizeDistribution(tt.trades, tt.threshold); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// seq returns from, from+1, ..., to
+func seq(from, to int) []int {
+	var out []int
+	for i := from; i <= to; i++ {
+		out = append(out, i)
+	}
+	return out
+}
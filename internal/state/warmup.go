@@ -0,0 +1,51 @@
+package state
+
+import "time"
+
+// WarmupStatus reports how far a market is through its warm-up period
+type WarmupStatus struct {
+	WarmedUp        bool      `json:"warmed_up"`
+	FirstSeen       time.Time `json:"first_seen"`
+	TrackedSecs     float64   `json:"tracked_secs"`
+	Snapshots       int       `json:"snapshots"` // books received, unchanged ones included
+	MinSnapshots    int       `json:"min_snapshots"`
+	MinDurationSecs float64   `json:"min_duration_secs"`
+}
+
+// SetWarmup configures the warm-up a newly registered market must complete:
+// at least minSnapshots books received and minDuration of tracking. Every
+// received book counts, including ones identical to the last and so not added
+// to the snapshot history, so a quiet market still warms up. A zero value
+// disables that requirement.
+func (e *Engine) SetWarmup(minSnapshots int, minDuration time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.warmupMinSnapshots = minSnapshots
+	e.warmupDuration = minDuration
+}
+
+// GetWarmupStatus returns the market's warm-up progress as of now
+func (e *Engine) GetWarmupStatus(ticker string) WarmupStatus {
+	e.mu.RLock()
+	firstSeen, seen := e.firstSeen[ticker]
+	received := e.bookUpdates[ticker]
+	minSnapshots := e.warmupMinSnapshots
+	minDuration := e.warmupDuration
+	e.mu.RUnlock()
+
+	status := WarmupStatus{
+		FirstSeen:       firstSeen,
+		Snapshots:       received,
+		MinSnapshots:    minSnapshots,
+		MinDurationSecs: minDuration.Seconds(),
+	}
+	if !seen {
+		return status
+	}
+
+	tracked := time.Since(firstSeen)
+	status.TrackedSecs = tracked.Seconds()
+	status.WarmedUp = tracked >= minDuration && status.Snapshots >= minSnapshots
+	return status
+}
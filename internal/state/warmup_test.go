@@ -0,0 +1,32 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietMarketWarmsUpFromUnchangedBooks(t *testing.T) {
+	engine := NewEngine()
+	engine.SetWarmup(5, 0)
+	engine.RegisterMarket(&Market{Ticker: "MKT", Status: StatusActive})
+
+	// The same book polled repeatedly: one snapshot, five books received
+	for i := 0; i < 5; i++ {
+		if engine.GetWarmupStatus("MKT").WarmedUp {
+			t.Fatalf("warmed up after %d books, want 5", i)
+		}
+		ob := NewOrderbook("MKT")
+		ob.Bids = []PriceLevel{{Price: 45, Quantity: 100}}
+		ob.Asks = []PriceLevel{{Price: 47, Quantity: 100}}
+		ob.LastUpdate = time.Now()
+		engine.UpdateOrderbook("MKT", ob)
+	}
+
+	status := engine.GetWarmupStatus("MKT")
+	if !status.WarmedUp || status.Snapshots != 5 {
+		t.Errorf("status = %+v, want warmed up after 5 books", status)
+	}
+	if n := engine.GetTimeSeries().SnapshotCount("MKT"); n != 1 {
+		t.Errorf("%d snapshots recorded, want 1 (unchanged books are deduplicated)", n)
+	}
+}
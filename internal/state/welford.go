@@ -0,0 +1,45 @@
+package state
+
+import "math"
+
+// runningStats maintains mean and variance incrementally using Welford's
+// algorithm. Values can also be removed (in any order) so the stats can track
+// a sliding window as old snapshots are evicted.
+type runningStats struct {
+	n    int
+	mean float64
+	m2   float64 // sum of squared deviations from the mean
+}
+
+func (r *runningStats) add(x float64) {
+	r.n++
+	delta := x - r.mean
+	r.mean += delta / float64(r.n)
+	r.m2 += delta * (x - r.mean)
+}
+
+func (r *runningStats) remove(x float64) {
+	if r.n <= 1 {
+		*r = runningStats{}
+		return
+	}
+	oldMean := r.mean
+	r.n--
+	r.mean = (oldMean*float64(r.n+1) - x) / float64(r.n)
+	r.m2 -= (x - oldMean) * (x - r.mean)
+	if r.m2 < 0 {
+		r.m2 = 0 // guard against float drift
+	}
+}
+
+// variance returns the population variance
+func (r *runningStats) variance() float64 {
+	if r.n < 2 {
+		return 0
+	}
+	return r.m2 / float64(r.n)
+}
+
+func (r *runningStats) stdDev() float64 {
+	return math.Sqrt(r.variance())
+}
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// batchVariance is the two-pass population variance of xs
+func batchVariance(xs []float64) float64 {
+	var mean float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	var sum float64
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return sum / float64(len(xs))
+}
+
+func TestRunningStatsMatchesBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var stats runningStats
+	var window []float64
+
+	for i := 0; i < 5000; i++ {
+		x := 0.3 + 0.4*rng.Float64()
+		stats.add(x)
+		window = append(window, x)
+
+		// Slide a 200-sample window, evicting the oldest
+		if len(window) > 200 {
+			stats.remove(window[0])
+			window = window[1:]
+		}
+
+		if len(window) >= 2 {
+			want := batchVariance(window)
+			if math.Abs(stats.variance()-want) > 1e-9 {
+				t.Fatalf("step %d: online variance %.12f, batch %.12f", i, stats.variance(), want)
+			}
+		}
+	}
+}
+
+func TestFullVolatilityMatchesWindowed(t *testing.T) {
+	ts := NewTimeSeriesStore()
+	for i, bid := range []int{40, 42, 45, 41, 39, 44, 47, 43} {
+		ob := NewOrderbook("MKT")
+		ob.Bids = []PriceLevel{{Price: bid, Quantity: 10}}
+		ob.Asks = []PriceLevel{{Price: bid + 2 + i%2, Quantity: 10}}
+		ts.RecordSnapshot("MKT", ob, nil)
+	}
+
+	online := ts.GetFullVolatility("MKT")
+	batch := ts.windowedVolatility("MKT", time.Time{})
+	if math.Abs(online-batch) > 1e-9 {
+		t.Fatalf("online %.12f, batch %.12f", online, batch)
+	}
+}
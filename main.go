@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/kalshi-signal-feed/internal/alerting"
 	"github.com/kalshi-signal-feed/internal/api"
+	"github.com/kalshi-signal-feed/internal/audit"
 	"github.com/kalshi-signal-feed/internal/config"
 	"github.com/kalshi-signal-feed/internal/ingestion"
 	"github.com/kalshi-signal-feed/internal/signals"
@@ -17,18 +20,35 @@ import (
 )
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	configPath := flag.String("config", "", "path to a TOML config file (default config/default.toml, or $KALSHI__CONFIG_FILE)")
+	flag.Parse()
+
+	log.SetFlags(log.LstdFlags | log.Lshortfile | log.LUTC)
 	log.Println("Starting Kalshi Signal Feed System")
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 	log.Println("Configuration loaded")
+	for _, warning := range cfg.Kalshi.EnvironmentWarnings() {
+		log.Printf("Warning: %s", warning)
+	}
 
 	// Initialize state engine
 	stateEngine := state.NewEngine()
+	stateEngine.SetWarmup(cfg.Signals.WarmupMinSnapshots, time.Duration(cfg.Signals.WarmupSecs)*time.Second)
+	stateEngine.SetDriftWindow(time.Duration(cfg.Signals.DriftWindowSecs) * time.Second)
+	referencePrice, err := state.ParseReferencePrice(cfg.Signals.ReferencePrice)
+	if err != nil {
+		log.Fatalf("Invalid signals.reference_price: %v", err)
+	}
+	stateEngine.SetReferencePrice(referencePrice, cfg.Scanner.MicropriceLevels)
+	stateEngine.SetActivityHalfLife(time.Duration(cfg.Scanner.ActivityHalfLifeSecs) * time.Second)
+	if err := stateEngine.LoadPinnedMarkets(cfg.Ingestion.PinnedMarketsPath); err != nil {
+		log.Printf("Failed to load pinned markets: %v", err)
+	}
 	log.Println("State engine initialized")
 
 	// Create signal channel
@@ -36,6 +56,7 @@ func main() {
 
 	// Initialize signal processor
 	signalProcessor := signals.NewProcessor(stateEngine, signalChan, cfg.Signals)
+	signalProcessor.SetMicropriceLevels(cfg.Scanner.MicropriceLevels)
 	log.Println("Signal processor initialized")
 
 	// Initialize alert manager
@@ -50,9 +71,27 @@ func main() {
 	log.Println("Ingestion layer initialized")
 
 	// Initialize API server
-	apiServer := api.NewServer(cfg.API, stateEngine, signalChan)
+	apiServer := api.NewServer(cfg.API, cfg.Scanner, stateEngine, signalChan)
+	apiServer.SetDashboardBaseURL(cfg.Alerting.DashboardBaseURL)
+	alertManager.SetCategoryFunc(apiServer.MarketCategory)
+	apiServer.SetBreakerStatusFunc(ingestionLayer.BreakerStatus)
+	if err := apiServer.Listen(); err != nil {
+		log.Fatalf("Failed to start API server: %v", err)
+	}
 	log.Println("API server initialized")
 
+	// Initialize audit log (optional)
+	var auditLog *audit.Log
+	if cfg.Audit.Path != "" {
+		auditLog, err = audit.NewLog(cfg.Audit)
+		if err != nil {
+			log.Fatalf("Failed to initialize audit log: %v", err)
+		}
+		signalProcessor.SetAuditLog(auditLog)
+		apiServer.SetAuditLog(auditLog)
+		log.Printf("Audit log initialized at %s", cfg.Audit.Path)
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -91,6 +130,17 @@ func main() {
 		}
 	}()
 
+	// Start audit log writer
+	if auditLog != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := auditLog.Run(ctx); err != nil {
+				log.Printf("Audit log error: %v", err)
+			}
+		}()
+	}
+
 	// Start API server
 	wg.Add(1)
 	go func() {